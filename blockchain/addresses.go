@@ -0,0 +1,217 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db"
+)
+
+// ErrAddressIndexDisabled is returned when the address activity index is used but not maintained,
+// see [Blockchain.WithAddressIndex].
+var ErrAddressIndexDisabled = errors.New("address index is disabled")
+
+// TransactionLocation is the position of a transaction in the chain.
+type TransactionLocation struct {
+	BlockNumber uint64
+	Index       uint64
+}
+
+// WithAddressIndex makes the blockchain index the transactions of every stored block by the
+// addresses of their senders and of the contracts they target.
+//
+// Only the blocks stored while the index is enabled are indexed this way, the others are indexed
+// by [Blockchain.IndexAddressActivity]. The index always covers a prefix of the chain: blocks are
+// not indexed out of order. The entries of the blocks removed by [Blockchain.RevertHead] are
+// removed from the index.
+func (b *Blockchain) WithAddressIndex() *Blockchain {
+	b.addressIndex = true
+	return b
+}
+
+// transactionAddresses returns the addresses a transaction is indexed by: the address of its sender
+// and the address of the contract it targets, if they differ.
+func transactionAddresses(t core.Transaction) []*felt.Felt {
+	var candidates []*felt.Felt
+	switch t := t.(type) {
+	case *core.InvokeTransaction:
+		candidates = []*felt.Felt{t.SenderAddress, t.ContractAddress}
+	case *core.DeclareTransaction:
+		candidates = []*felt.Felt{t.SenderAddress}
+	case *core.DeployTransaction:
+		candidates = []*felt.Felt{t.ContractAddress}
+	case *core.DeployAccountTransaction:
+		candidates = []*felt.Felt{t.ContractAddress}
+	case *core.L1HandlerTransaction:
+		candidates = []*felt.Felt{t.ContractAddress}
+	}
+
+	addresses := make([]*felt.Felt, 0, len(candidates))
+	for _, address := range candidates {
+		if address != nil && (len(addresses) == 0 || !addresses[0].Equal(address)) {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
+
+// storeAddressActivity indexes a transaction by its addresses as follows:
+//
+// [db.AddressActivity](Address, BlockNumber, Index) -> TransactionHash
+func storeAddressActivity(txn db.Transaction, number, i uint64, t core.Transaction) error {
+	bnIndexBytes := (&txAndReceiptDBKey{number, i}).MarshalBinary()
+	for _, address := range transactionAddresses(t) {
+		if err := txn.Set(db.AddressActivity.Key(address.Marshal(), bnIndexBytes), t.Hash().Marshal()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unindexAddressActivity removes the transactions of the head block from the address activity
+// index, if they are indexed. It does so whether the index is enabled or not, so that blocks
+// stored in place of the head block can be indexed later.
+func unindexAddressActivity(txn db.Transaction, head *core.Block) error {
+	height, err := addressActivityHeight(txn)
+	if err != nil || height <= head.Number {
+		return err
+	}
+
+	for i, tx := range head.Transactions {
+		bnIndexBytes := (&txAndReceiptDBKey{head.Number, uint64(i)}).MarshalBinary()
+		for _, address := range transactionAddresses(tx) {
+			if err = txn.Delete(db.AddressActivity.Key(address.Marshal(), bnIndexBytes)); err != nil {
+				return err
+			}
+		}
+	}
+	return setAddressActivityHeight(txn, head.Number)
+}
+
+// addressActivityHeight returns the number of blocks indexed in the address activity index as
+// maintained in:
+//
+// [db.AddressActivityHeight]() -> (NumberOfBlocks)
+func addressActivityHeight(txn db.Transaction) (uint64, error) {
+	var height uint64
+	err := txn.Get(db.AddressActivityHeight.Key(), func(val []byte) error {
+		height = binary.BigEndian.Uint64(val)
+		return nil
+	})
+	if errors.Is(err, db.ErrKeyNotFound) {
+		return 0, nil
+	}
+	return height, err
+}
+
+func setAddressActivityHeight(txn db.Transaction, height uint64) error {
+	return txn.Set(db.AddressActivityHeight.Key(), binary.BigEndian.AppendUint64(nil, height))
+}
+
+// indexesAddressActivity reports whether the transactions of the block with the given number are
+// indexed when the block is stored.
+func (b *Blockchain) indexesAddressActivity(txn db.Transaction, number uint64) (bool, error) {
+	if !b.addressIndex {
+		return false, nil
+	}
+	height, err := addressActivityHeight(txn)
+	return height == number, err
+}
+
+// IndexAddressActivity indexes at most `limit` stored blocks that are missing from the address
+// activity index, oldest first, and returns the number of indexed blocks. Those are the blocks
+// stored while the index was disabled.
+//
+// Indexing is done in a single write transaction, so `limit` bounds how long other writers are blocked.
+func (b *Blockchain) IndexAddressActivity(limit uint64) (uint64, error) {
+	if !b.addressIndex {
+		return 0, ErrAddressIndexDisabled
+	}
+
+	var indexed uint64
+	return indexed, b.database.Update(func(txn db.Transaction) error {
+		height, err := b.height(txn)
+		if errors.Is(err, db.ErrKeyNotFound) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		next, err := addressActivityHeight(txn)
+		if err != nil {
+			return err
+		}
+		for ; next <= height && indexed < limit; next++ {
+			block, err := blockByNumber(txn, next)
+			if err != nil {
+				return err
+			}
+			for i, tx := range block.Transactions {
+				if err = storeAddressActivity(txn, next, uint64(i), tx); err != nil {
+					return err
+				}
+			}
+			indexed++
+		}
+
+		if indexed == 0 {
+			return nil
+		}
+		return setAddressActivityHeight(txn, next)
+	})
+}
+
+// TransactionsByAddress gets the hashes of at most `limit` transactions sent by or to the contract
+// at the given address, oldest first, from location `start`, or from the first transaction if `start`
+// is nil. The location of the next transaction is returned if there are more transactions, nil otherwise.
+func (b *Blockchain) TransactionsByAddress(address *felt.Felt, start *TransactionLocation, limit uint64) (
+	[]*felt.Felt, *TransactionLocation, error,
+) {
+	if !b.addressIndex {
+		return nil, nil, ErrAddressIndexDisabled
+	}
+	if limit == 0 {
+		return nil, nil, errors.New("limit must be positive")
+	}
+	if start == nil {
+		start = new(TransactionLocation)
+	}
+
+	var (
+		hashes []*felt.Felt
+		next   *TransactionLocation
+	)
+	return hashes, next, b.database.View(func(txn db.Transaction) error {
+		iterator, err := txn.NewIterator()
+		if err != nil {
+			return err
+		}
+
+		prefix := db.AddressActivity.Key(address.Marshal())
+		startKey := (&txAndReceiptDBKey{start.BlockNumber, start.Index}).MarshalBinary()
+		for iterator.Seek(append(append([]byte{}, prefix...), startKey...)); iterator.Valid(); iterator.Next() {
+			key := iterator.Key()
+			if !bytes.HasPrefix(key, prefix) {
+				break
+			}
+			if uint64(len(hashes)) == limit {
+				var bnIndex txAndReceiptDBKey
+				if err = bnIndex.UnmarshalBinary(key[len(prefix):]); err != nil {
+					return db.CloseAndWrapOnError(iterator.Close, err)
+				}
+				next = &TransactionLocation{BlockNumber: bnIndex.Number, Index: bnIndex.Index}
+				break
+			}
+
+			val, err := iterator.Value()
+			if err != nil {
+				return db.CloseAndWrapOnError(iterator.Close, err)
+			}
+			hashes = append(hashes, new(felt.Felt).SetBytes(val))
+		}
+		return iterator.Close()
+	})
+}
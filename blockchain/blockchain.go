@@ -2,15 +2,17 @@ package blockchain
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"errors"
+	"fmt"
 
-	"github.com/Masterminds/semver/v3"
 	"github.com/NethermindEth/juno/core"
 	"github.com/NethermindEth/juno/core/felt"
 	"github.com/NethermindEth/juno/db"
 	"github.com/NethermindEth/juno/encoder"
 	"github.com/NethermindEth/juno/utils"
+	"github.com/ethereum/go-ethereum/common"
 )
 
 const lenOfByteSlice = 8
@@ -32,25 +34,15 @@ type Reader interface {
 	Receipt(hash *felt.Felt) (receipt *core.TransactionReceipt, blockHash *felt.Felt, blockNumber uint64, err error)
 	StateUpdateByNumber(number uint64) (update *core.StateUpdate, err error)
 	StateUpdateByHash(hash *felt.Felt) (update *core.StateUpdate, err error)
-}
-
-var supportedStarknetVersion = semver.MustParse("0.11.0")
-
-func checkBlockVersion(protocolVersion string) error {
-	if protocolVersion == "" {
-		return nil
-	}
-
-	blockVer, err := semver.NewVersion(protocolVersion)
-	if err != nil {
-		return err
-	}
-
-	if blockVer.GreaterThan(supportedStarknetVersion) {
-		return errors.New("unsupported block version")
-	}
-
-	return nil
+	AggregatedStateUpdate(from, to uint64) (update *core.StateUpdate, err error)
+	L1HandlerTxnHash(msgHash *common.Hash) (l1HandlerTxnHash *felt.Felt, err error)
+	L2ToL1MessagesByHash(msgHash *common.Hash) (records []*L2ToL1MessageRecord, err error)
+	L2ToL1MessagesByRecipient(recipient common.Address, start *L2ToL1MessageLocation, toBlock, limit uint64) (
+		records []*L2ToL1MessageRecord, next *L2ToL1MessageLocation, err error)
+	TransactionsByAddress(address *felt.Felt, start *TransactionLocation, limit uint64) (
+		hashes []*felt.Felt, next *TransactionLocation, err error)
+	ClassDeclaration(classHash *felt.Felt) (declaration *ClassDeclaration, err error)
+	ClassInstances(classHash, start *felt.Felt, limit uint64) (instances []*ClassInstance, next *felt.Felt, err error)
 }
 
 var _ Reader = (*Blockchain)(nil)
@@ -59,6 +51,8 @@ var _ Reader = (*Blockchain)(nil)
 type Blockchain struct {
 	network  utils.Network
 	database db.DB
+	// addressIndex is set if transactions are indexed by address, see [Blockchain.WithAddressIndex].
+	addressIndex bool
 }
 
 func New(database db.DB, network utils.Network) *Blockchain {
@@ -190,6 +184,47 @@ func (b *Blockchain) StateUpdateByHash(hash *felt.Felt) (*core.StateUpdate, erro
 	})
 }
 
+// MaxAggregatedBlocks is the largest number of blocks whose state updates can be aggregated at once.
+const MaxAggregatedBlocks = 1000
+
+var ErrBlockRangeTooLarge = fmt.Errorf("block range spans more than %d blocks", MaxAggregatedBlocks)
+
+// AggregatedStateUpdate folds the stored state updates of blocks `from` through `to` (inclusive)
+// into a single state update. Its OldRoot is the state root before block `from`, its NewRoot and
+// BlockHash are those of block `to`, and its StateDiff takes the former state to the latter.
+//
+// At most [MaxAggregatedBlocks] blocks can be aggregated, which bounds both the memory used and how
+// long the read transaction is held.
+func (b *Blockchain) AggregatedStateUpdate(from, to uint64) (*core.StateUpdate, error) {
+	if from > to {
+		return nil, fmt.Errorf("invalid block range: %d is greater than %d", from, to)
+	}
+	if to-from >= MaxAggregatedBlocks {
+		return nil, ErrBlockRangeTooLarge
+	}
+
+	var update *core.StateUpdate
+	return update, b.database.View(func(txn db.Transaction) error {
+		aggregator := core.NewStateDiffAggregator()
+		update = new(core.StateUpdate)
+		for number := from; number <= to; number++ {
+			blockUpdate, err := stateUpdateByNumber(txn, number)
+			if err != nil {
+				return err
+			}
+
+			if number == from {
+				update.OldRoot = blockUpdate.OldRoot
+			}
+			update.NewRoot = blockUpdate.NewRoot
+			update.BlockHash = blockUpdate.BlockHash
+			aggregator.Add(blockUpdate.StateDiff)
+		}
+		update.StateDiff = aggregator.StateDiff()
+		return nil
+	})
+}
+
 // TransactionByBlockNumberAndIndex gets the transaction for a given block number and index.
 func (b *Blockchain) TransactionByBlockNumberAndIndex(blockNumber, index uint64) (core.Transaction, error) {
 	var transaction core.Transaction
@@ -224,26 +259,67 @@ func (b *Blockchain) Receipt(hash *felt.Felt) (*core.TransactionReceipt, *felt.F
 	})
 }
 
+// L1HandlerTxnHash gets the hash of the L1 handler transaction that consumed the L1 to L2 message
+// with the given hash.
+func (b *Blockchain) L1HandlerTxnHash(msgHash *common.Hash) (*felt.Felt, error) {
+	var l1HandlerTxnHash *felt.Felt
+	return l1HandlerTxnHash, b.database.View(func(txn db.Transaction) error {
+		return txn.Get(db.L1HandlerTxnHashByMsgHash.Key(msgHash.Bytes()), func(val []byte) error {
+			l1HandlerTxnHash = new(felt.Felt).SetBytes(val)
+			return nil
+		})
+	})
+}
+
 // Store takes a block and state update and performs sanity checks before putting in the database.
+// The state diff commitment of the block is computed from the state update if the block doesn't
+// come with one.
 func (b *Blockchain) Store(block *core.Block, stateUpdate *core.StateUpdate, declaredClasses map[felt.Felt]core.Class) error {
+	if block.StateDiffCommitment == nil {
+		setStateDiffCommitment(block, stateUpdate)
+	}
 	return b.database.Update(func(txn db.Transaction) error {
 		if err := b.verifyBlock(txn, block); err != nil {
 			return err
 		}
-		if err := core.NewState(txn).Update(stateUpdate, declaredClasses); err != nil {
+		state := core.NewState(txn)
+		reverseDiff, err := state.ReverseDiff(stateUpdate, declaredClasses)
+		if err != nil {
+			return err
+		}
+		if err = state.Update(stateUpdate, declaredClasses); err != nil {
 			return err
 		}
-		if err := storeBlockHeader(txn, block.Header); err != nil {
+		if err = storeBlockHeader(txn, block.Header); err != nil {
+			return err
+		}
+		indexAddresses, err := b.indexesAddressActivity(txn, block.Number)
+		if err != nil {
 			return err
 		}
 		for i, tx := range block.Transactions {
-			if err := storeTransactionAndReceipt(txn, block.Number, uint64(i), tx,
-				block.Receipts[i]); err != nil {
+			if err = storeTransactionAndReceipt(txn, block.Number, uint64(i), tx,
+				block.Receipts[i], indexAddresses); err != nil {
+				return err
+			}
+			if err = storeL2ToL1Messages(txn, block.Number, uint64(i), block.Receipts[i]); err != nil {
+				return err
+			}
+		}
+		if indexAddresses {
+			if err = setAddressActivityHeight(txn, block.Number+1); err != nil {
 				return err
 			}
 		}
 
-		if err := storeStateUpdate(txn, block.Number, stateUpdate); err != nil {
+		if err = storeClassIndexes(txn, block.Number, block.Transactions, stateUpdate.StateDiff); err != nil {
+			return err
+		}
+
+		if err = storeStateUpdate(txn, block.Number, stateUpdate); err != nil {
+			return err
+		}
+		if err = storeStateReverseDiff(txn, block.Number, reverseDiff); err != nil {
 			return err
 		}
 
@@ -255,15 +331,132 @@ func (b *Blockchain) Store(block *core.Block, stateUpdate *core.StateUpdate, dec
 	})
 }
 
+// RevertHead removes the head block and reverts the state to the one of its parent, so that the
+// block of another branch can be stored in its place when the chain is reorganised. The entries of
+// the head block are removed from the indexes too.
+//
+// Blocks whose state updates were pruned, see [Blockchain.PruneStateUpdates], cannot be reverted,
+// and neither can blocks stored by versions that did not record what their state updates overwrote.
+func (b *Blockchain) RevertHead() error {
+	return b.database.Update(func(txn db.Transaction) error {
+		head, err := b.head(txn)
+		if err != nil {
+			return err
+		}
+
+		stateUpdate, err := stateUpdateByNumber(txn, head.Number)
+		if err != nil {
+			return err
+		}
+		reverseDiff, err := stateReverseDiffByNumber(txn, head.Number)
+		if err != nil {
+			return fmt.Errorf("state reverse diff of block %d: %w", head.Number, err)
+		}
+		if err = core.NewState(txn).Revert(stateUpdate, reverseDiff); err != nil {
+			return err
+		}
+
+		if err = unindexAddressActivity(txn, head); err != nil {
+			return err
+		}
+		if err = deleteClassIndexes(txn, head.Number, stateUpdate.StateDiff); err != nil {
+			return err
+		}
+		for i, tx := range head.Transactions {
+			if err = deleteTransactionAndReceipt(txn, head.Number, uint64(i), tx, head.Receipts[i]); err != nil {
+				return err
+			}
+			if err = deleteL2ToL1Messages(txn, head.Number, uint64(i), head.Receipts[i]); err != nil {
+				return err
+			}
+		}
+
+		numBytes := binary.BigEndian.AppendUint64(nil, head.Number)
+		for _, key := range [][]byte{
+			db.BlockHeaderNumbersByHash.Key(head.Hash.Marshal()),
+			db.BlockHeadersByNumber.Key(numBytes),
+			db.StateUpdatesByBlockNumber.Key(numBytes),
+			db.StateReverseDiffs.Key(numBytes),
+		} {
+			if err = txn.Delete(key); err != nil {
+				return err
+			}
+		}
+
+		if head.Number == 0 {
+			return txn.Delete(db.ChainHeight.Key())
+		}
+		return txn.Set(db.ChainHeight.Key(), binary.BigEndian.AppendUint64(nil, head.Number-1))
+	})
+}
+
+func setStateDiffCommitment(block *core.Block, stateUpdate *core.StateUpdate) {
+	block.StateDiffCommitment = stateUpdate.StateDiff.Commitment()
+	block.StateDiffLength = stateUpdate.StateDiff.Length()
+}
+
+// VerifyState rebuilds the state tries from their leaves and checks them against the stored
+// roots and the head block's GlobalStateRoot. The first inconsistency found is returned.
+func (b *Blockchain) VerifyState(ctx context.Context) error {
+	return b.database.View(func(txn db.Transaction) error {
+		root, err := core.NewState(txn).Verify(ctx)
+		if err != nil {
+			return err
+		}
+
+		height, err := b.height(txn)
+		if errors.Is(err, db.ErrKeyNotFound) {
+			if !root.IsZero() {
+				return fmt.Errorf("blockchain is empty but state root is %s", root)
+			}
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		header, err := blockHeaderByNumber(txn, height)
+		if err != nil {
+			return err
+		}
+
+		if !root.Equal(header.GlobalStateRoot) {
+			return fmt.Errorf("state root: %s does not match head block's (number: %d) global state root: %s",
+				root, header.Number, header.GlobalStateRoot)
+		}
+		return nil
+	})
+}
+
 // VerifyBlock assumes the block has already been sanity-checked.
+// VerifyAccountSignatures checks the signatures of the transactions of block that were sent from
+// accounts keeping their public key in a well-known storage variable, see
+// [core.VerifyAccountSignature]. The public keys are read from the state of the head, so block
+// must be the block that follows it. The first signature that does not match is returned.
+func (b *Blockchain) VerifyAccountSignatures(block *core.Block) error {
+	return b.database.View(func(txn db.Transaction) error {
+		state := core.NewState(txn)
+		for _, tx := range block.Transactions {
+			err := core.VerifyAccountSignature(state, tx)
+			if err != nil && !errors.Is(err, core.ErrSignatureNotVerifiable) {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (b *Blockchain) VerifyBlock(block *core.Block) error {
 	return b.database.View(func(txn db.Transaction) error {
 		return b.verifyBlock(txn, block)
 	})
 }
 
+// ErrParentDoesNotMatchHead is returned when a block that does not extend the head block is stored,
+// which happens when the chain was reorganised, see [Blockchain.RevertHead].
+var ErrParentDoesNotMatchHead = errors.New("block's parent hash does not match head block hash")
+
 func (b *Blockchain) verifyBlock(txn db.Transaction, block *core.Block) error {
-	if err := checkBlockVersion(block.ProtocolVersion); err != nil {
+	if err := core.CheckProtocolVersion(block.ProtocolVersion); err != nil {
 		return err
 	}
 
@@ -284,7 +477,7 @@ func (b *Blockchain) verifyBlock(txn db.Transaction, block *core.Block) error {
 			return errors.New("block number difference between head and incoming block is not 1")
 		}
 		if !block.ParentHash.Equal(head.Hash) {
-			return errors.New("block's parent hash does not match head block hash")
+			return ErrParentDoesNotMatchHead
 		}
 	}
 
@@ -430,6 +623,112 @@ func storeStateUpdate(txn db.Transaction, blockNumber uint64, update *core.State
 	return nil
 }
 
+// storeStateReverseDiff stores what the state update of a block overwrote, for the block to be
+// reverted by [Blockchain.RevertHead]:
+//
+// [db.StateReverseDiffs](BlockNumber) -> (StateReverseDiff)
+func storeStateReverseDiff(txn db.Transaction, blockNumber uint64, reverseDiff *core.StateReverseDiff) error {
+	reverseDiffBytes, err := encoder.Marshal(reverseDiff)
+	if err != nil {
+		return err
+	}
+	return txn.Set(db.StateReverseDiffs.Key(binary.BigEndian.AppendUint64(nil, blockNumber)),
+		reverseDiffBytes)
+}
+
+func stateReverseDiffByNumber(txn db.Transaction, blockNumber uint64) (*core.StateReverseDiff, error) {
+	var reverseDiff *core.StateReverseDiff
+	return reverseDiff, txn.Get(db.StateReverseDiffs.Key(binary.BigEndian.AppendUint64(nil, blockNumber)),
+		func(val []byte) error {
+			reverseDiff = new(core.StateReverseDiff)
+			return encoder.Unmarshal(val, reverseDiff)
+		})
+}
+
+// ErrStateUpdatePruned is returned for the state updates of blocks that are stored but whose state
+// updates were pruned, see [Blockchain.PruneStateUpdates].
+var ErrStateUpdatePruned = errors.New("state update was pruned")
+
+// PruneStateUpdates deletes at most `limit` stored state updates, oldest first, that belong to blocks
+// older than the `keep` most recent blocks. It returns the number of deleted state updates.
+//
+// Pruning is done in a single write transaction, so `limit` bounds how long other writers are blocked.
+// The state tries are keyed by path and only hold the current state, so they never need pruning.
+// What the pruned state updates overwrote is deleted with them, so their blocks can no longer be
+// reverted, see [Blockchain.RevertHead].
+//
+// The number of blocks whose state updates were pruned is maintained as follows:
+//
+// [db.StateUpdatesPrunedHeight]() -> (NumberOfBlocks)
+func (b *Blockchain) PruneStateUpdates(keep, limit uint64) (uint64, error) {
+	if keep == 0 {
+		return 0, errors.New("at least one block must be kept")
+	}
+
+	var pruned uint64
+	return pruned, b.database.Update(func(txn db.Transaction) error {
+		height, err := b.height(txn)
+		if errors.Is(err, db.ErrKeyNotFound) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if height < keep {
+			return nil
+		}
+		firstKept := height - keep + 1
+
+		iterator, err := txn.NewIterator()
+		if err != nil {
+			return err
+		}
+
+		var keys [][]byte
+		prefix := db.StateUpdatesByBlockNumber.Key()
+		for iterator.Seek(prefix); iterator.Valid() && uint64(len(keys)) < limit; iterator.Next() {
+			key := iterator.Key()
+			if !bytes.HasPrefix(key, prefix) || binary.BigEndian.Uint64(key[len(prefix):]) >= firstKept {
+				break
+			}
+			keys = append(keys, append([]byte{}, key...))
+		}
+
+		if err = iterator.Close(); err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			if err = txn.Delete(key); err != nil {
+				return err
+			}
+			if err = txn.Delete(db.StateReverseDiffs.Key(key[len(prefix):])); err != nil {
+				return err
+			}
+			pruned++
+		}
+		if len(keys) == 0 {
+			return nil
+		}
+
+		lastPruned := binary.BigEndian.Uint64(keys[len(keys)-1][len(prefix):])
+		return txn.Set(db.StateUpdatesPrunedHeight.Key(), binary.BigEndian.AppendUint64(nil, lastPruned+1))
+	})
+}
+
+// stateUpdatesPrunedHeight returns the number of oldest blocks whose state updates were pruned.
+func stateUpdatesPrunedHeight(txn db.Transaction) (uint64, error) {
+	var height uint64
+	err := txn.Get(db.StateUpdatesPrunedHeight.Key(), func(val []byte) error {
+		height = binary.BigEndian.Uint64(val)
+		return nil
+	})
+	if errors.Is(err, db.ErrKeyNotFound) {
+		return 0, nil
+	}
+	return height, err
+}
+
 func stateUpdateByNumber(txn db.Transaction, blockNumber uint64) (*core.StateUpdate, error) {
 	numBytes := make([]byte, lenOfByteSlice)
 	binary.BigEndian.PutUint64(numBytes, blockNumber)
@@ -438,7 +737,16 @@ func stateUpdateByNumber(txn db.Transaction, blockNumber uint64) (*core.StateUpd
 	if err := txn.Get(db.StateUpdatesByBlockNumber.Key(numBytes), func(val []byte) error {
 		update = new(core.StateUpdate)
 		return encoder.Unmarshal(val, update)
-	}); err != nil {
+	}); errors.Is(err, db.ErrKeyNotFound) {
+		prunedHeight, prunedErr := stateUpdatesPrunedHeight(txn)
+		if prunedErr != nil {
+			return nil, prunedErr
+		}
+		if blockNumber < prunedHeight {
+			return nil, ErrStateUpdatePruned
+		}
+		return nil, err
+	} else if err != nil {
 		return nil, err
 	}
 	return update, nil
@@ -453,17 +761,87 @@ func stateUpdateByHash(txn db.Transaction, hash *felt.Felt) (*core.StateUpdate,
 	})
 }
 
-// SanityCheckNewHeight checks integrity of a block and resulting state update
-func (b *Blockchain) SanityCheckNewHeight(block *core.Block, stateUpdate *core.StateUpdate) error {
+// SanityCheckNewHeight checks integrity of a block, resulting state update and the classes
+// that are referenced by it
+func (b *Blockchain) SanityCheckNewHeight(block *core.Block, stateUpdate *core.StateUpdate,
+	newClasses map[felt.Felt]core.Class,
+) error {
 	if !block.Hash.Equal(stateUpdate.BlockHash) {
 		return errors.New("block hashes do not match")
 	}
 	if !block.GlobalStateRoot.Equal(stateUpdate.NewRoot) {
 		return errors.New("block's GlobalStateRoot does not match state update's NewRoot")
 	}
+	if err := b.verifyNewClasses(stateUpdate.StateDiff, newClasses); err != nil {
+		return err
+	}
+	if block.StateDiffCommitment != nil {
+		if !block.StateDiffCommitment.Equal(stateUpdate.StateDiff.Commitment()) {
+			return errors.New("block's StateDiffCommitment does not match state update's StateDiff")
+		}
+		if block.StateDiffLength != stateUpdate.StateDiff.Length() {
+			return errors.New("block's StateDiffLength does not match state update's StateDiff")
+		}
+	} else {
+		// newer block hashes commit to the state diff
+		setStateDiffCommitment(block, stateUpdate)
+	}
 	return core.VerifyBlockHash(block, b.network)
 }
 
+// verifyNewClasses checks that the definitions of all the classes declared in the state diff
+// are given and verifies the hashes of the classes which are not stored yet. Stored classes
+// were verified before they were stored. The compiled class hashes of declared Cairo 1 classes
+// are verified if their compiled classes are given.
+func (b *Blockchain) verifyNewClasses(stateDiff *core.StateDiff, newClasses map[felt.Felt]core.Class) error {
+	declaredClassHashes := append([]*felt.Felt{}, stateDiff.DeclaredV0Classes...)
+	for _, declaredClass := range stateDiff.DeclaredV1Classes {
+		declaredClassHashes = append(declaredClassHashes, declaredClass.ClassHash)
+	}
+	for _, classHash := range declaredClassHashes {
+		if _, found := newClasses[*classHash]; !found {
+			return fmt.Errorf("class %s: definition of declared class is missing", classHash)
+		}
+	}
+
+	unverifiedClasses := make(map[felt.Felt]core.Class, len(newClasses))
+	if err := b.database.View(func(txn db.Transaction) error {
+		for classHash, class := range newClasses {
+			err := txn.Get(db.Class.Key(classHash.Marshal()), func(val []byte) error {
+				return nil
+			})
+			if errors.Is(err, db.ErrKeyNotFound) {
+				unverifiedClasses[classHash] = class
+			} else if err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if err := core.VerifyClassHashes(unverifiedClasses); err != nil {
+		return err
+	}
+
+	for _, declaredClass := range stateDiff.DeclaredV1Classes {
+		class, ok := newClasses[*declaredClass.ClassHash].(*core.Cairo1Class)
+		if !ok || class.Compiled == nil {
+			continue
+		}
+		hash, err := class.Compiled.Hash()
+		if err != nil {
+			return fmt.Errorf("class %s: %v", declaredClass.ClassHash, err)
+		}
+		if !hash.Equal(declaredClass.CompiledClassHash) {
+			return fmt.Errorf("class %s: calculated compiled class hash %s does not match declared compiled class hash %s",
+				declaredClass.ClassHash, hash, declaredClass.CompiledClassHash)
+		}
+	}
+	return nil
+}
+
 type txAndReceiptDBKey struct {
 	Number uint64
 	Index  uint64
@@ -488,12 +866,20 @@ func (t *txAndReceiptDBKey) UnmarshalBinary(data []byte) error {
 // [db.TransactionsByBlockNumberAndIndex](BlockNumber, Index) -> Transaction
 // [db.ReceiptsByBlockNumberAndIndex](BlockNumber, Index) -> Receipt
 //
+// L1 handler transactions are also indexed by the hash of the message they consume:
+//
+// [db.L1HandlerTxnHashByMsgHash](MessageHash) -> TransactionHash
+//
+// If indexAddresses is set, the transaction is indexed by its addresses too, see storeAddressActivity.
+//
 // Note: we are using the same transaction hash bucket which keeps track of block number and
 // index for both transactions and receipts since transaction and its receipt share the same hash.
 // "[]" is the db prefix to represent a bucket
 // "()" are additional keys appended to the prefix or multiple values marshalled together
 // "->" represents a key value pair.
-func storeTransactionAndReceipt(txn db.Transaction, number, i uint64, t core.Transaction, r *core.TransactionReceipt) error {
+func storeTransactionAndReceipt(txn db.Transaction, number, i uint64, t core.Transaction, r *core.TransactionReceipt,
+	indexAddresses bool,
+) error {
 	bnIndexBytes := (&txAndReceiptDBKey{number, i}).MarshalBinary()
 
 	if err := txn.Set(db.TransactionBlockNumbersAndIndicesByHash.Key((r.TransactionHash).Marshal()),
@@ -516,6 +902,53 @@ func storeTransactionAndReceipt(txn db.Transaction, number, i uint64, t core.Tra
 	if err = txn.Set(db.ReceiptsByBlockNumberAndIndex.Key(bnIndexBytes), rBytes); err != nil {
 		return err
 	}
+
+	if l1Handler, ok := t.(*core.L1HandlerTransaction); ok {
+		if msg := l1Handler.Message(); msg != nil {
+			msgHash := msg.Hash()
+			if err = txn.Set(db.L1HandlerTxnHashByMsgHash.Key(msgHash.Bytes()), l1Handler.Hash().Marshal()); err != nil {
+				return err
+			}
+		}
+	}
+
+	if indexAddresses {
+		return storeAddressActivity(txn, number, i, t)
+	}
+	return nil
+}
+
+// deleteTransactionAndReceipt deletes what storeTransactionAndReceipt stores, except for the
+// address activity index, see unindexAddressActivity.
+func deleteTransactionAndReceipt(txn db.Transaction, number, i uint64, t core.Transaction, r *core.TransactionReceipt) error {
+	bnIndexBytes := (&txAndReceiptDBKey{number, i}).MarshalBinary()
+	for _, key := range [][]byte{
+		db.TransactionBlockNumbersAndIndicesByHash.Key(r.TransactionHash.Marshal()),
+		db.TransactionsByBlockNumberAndIndex.Key(bnIndexBytes),
+		db.ReceiptsByBlockNumberAndIndex.Key(bnIndexBytes),
+	} {
+		if err := txn.Delete(key); err != nil {
+			return err
+		}
+	}
+
+	if l1Handler, ok := t.(*core.L1HandlerTransaction); ok {
+		if msg := l1Handler.Message(); msg != nil {
+			msgHash := msg.Hash()
+			key := db.L1HandlerTxnHashByMsgHash.Key(msgHash.Bytes())
+			// the message may have been consumed again by a later transaction
+			var consumer felt.Felt
+			err := txn.Get(key, func(val []byte) error {
+				consumer.SetBytes(val)
+				return nil
+			})
+			if err != nil && !errors.Is(err, db.ErrKeyNotFound) {
+				return err
+			} else if err == nil && consumer.Equal(l1Handler.Hash()) {
+				return txn.Delete(key)
+			}
+		}
+	}
 	return nil
 }
 
@@ -2,18 +2,24 @@ package blockchain_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"math/big"
+	"sort"
 	"testing"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/NethermindEth/juno/blockchain"
 	"github.com/NethermindEth/juno/clients/feeder"
 	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/crypto"
 	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/core/trie"
 	"github.com/NethermindEth/juno/db"
 	"github.com/NethermindEth/juno/db/pebble"
 	adaptfeeder "github.com/NethermindEth/juno/starknetdata/feeder"
 	"github.com/NethermindEth/juno/utils"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -162,6 +168,53 @@ func TestVerifyBlock(t *testing.T) {
 	})
 }
 
+func TestVerifyAccountSignatures(t *testing.T) {
+	testDB := pebble.NewMemTest()
+	chain := blockchain.New(testDB, utils.MAINNET)
+
+	privateKey := utils.HexToFelt(t, "0x3c1e9550e66958296d11b60f8e8e7a7ad990d07fa65d5f7652c4a6c87d4e3cc")
+	publicKey, err := crypto.PublicKey(privateKey)
+	require.NoError(t, err)
+
+	account := utils.HexToFelt(t, "0xACC")
+	require.NoError(t, testDB.Update(func(txn db.Transaction) error {
+		contract, err := core.DeployContract(account, utils.HexToFelt(t, "0xC1A55"), txn)
+		if err != nil {
+			return err
+		}
+		key, err := crypto.StarknetKeccak([]byte("Account_public_key"))
+		if err != nil {
+			return err
+		}
+		return contract.UpdateStorage([]core.StorageDiff{{Key: key, Value: publicKey}})
+	}))
+
+	hash := utils.HexToFelt(t, "0x397e76d1667c4454bfb83514e120583af836f8e32a516765497823eb85a5c84")
+	r, s, err := crypto.Sign(hash, privateKey)
+	require.NoError(t, err)
+	signed := &core.InvokeTransaction{
+		TransactionHash:      hash,
+		SenderAddress:        account,
+		TransactionSignature: []*felt.Felt{r, s},
+		Version:              new(felt.Felt).SetUint64(1),
+	}
+	// not sent from an account, so it is skipped
+	l1Handler := &core.L1HandlerTransaction{TransactionHash: utils.HexToFelt(t, "0x1")}
+
+	t.Run("signatures match", func(t *testing.T) {
+		block := &core.Block{Transactions: []core.Transaction{l1Handler, signed}}
+		assert.NoError(t, chain.VerifyAccountSignatures(block))
+	})
+
+	t.Run("signature does not match", func(t *testing.T) {
+		forged := *signed
+		forged.TransactionHash = utils.HexToFelt(t, "0x1234")
+		block := &core.Block{Transactions: []core.Transaction{l1Handler, &forged}}
+		assert.EqualError(t, chain.VerifyAccountSignatures(block), "signature of transaction 0x1234 does not match "+
+			"public key "+publicKey.String()+" of account "+account.String())
+	})
+}
+
 func TestSanityCheckNewHeight(t *testing.T) {
 	h1, err := new(felt.Felt).SetRandom()
 	require.NoError(t, err)
@@ -185,7 +238,7 @@ func TestSanityCheckNewHeight(t *testing.T) {
 		require.NoError(t, err)
 
 		stateUpdate := &core.StateUpdate{BlockHash: h1}
-		assert.EqualError(t, chain.SanityCheckNewHeight(mainnetBlock1, stateUpdate), "block hashes do not match")
+		assert.EqualError(t, chain.SanityCheckNewHeight(mainnetBlock1, stateUpdate, nil), "block hashes do not match")
 	})
 
 	t.Run("error when block global state root does not match state update's new root",
@@ -194,9 +247,115 @@ func TestSanityCheckNewHeight(t *testing.T) {
 			require.NoError(t, err)
 			stateUpdate := &core.StateUpdate{BlockHash: mainnetBlock1.Hash, NewRoot: h1}
 
-			assert.EqualError(t, chain.SanityCheckNewHeight(mainnetBlock1, stateUpdate),
+			assert.EqualError(t, chain.SanityCheckNewHeight(mainnetBlock1, stateUpdate, nil),
 				"block's GlobalStateRoot does not match state update's NewRoot")
 		})
+
+	t.Run("error when a class does not match its class hash", func(t *testing.T) {
+		mainnetBlock1, err := gw.BlockByNumber(context.Background(), 1)
+		require.NoError(t, err)
+		mainnetStateUpdate1, err := gw.StateUpdate(context.Background(), 1)
+		require.NoError(t, err)
+
+		classHash := mainnetStateUpdate1.StateDiff.DeployedContracts[0].ClassHash
+		class, err := gw.Class(context.Background(), classHash)
+		require.NoError(t, err)
+		require.NoError(t, chain.SanityCheckNewHeight(mainnetBlock1, mainnetStateUpdate1,
+			map[felt.Felt]core.Class{*classHash: class}))
+
+		class.(*core.Cairo0Class).Bytecode = nil
+		assert.EqualError(t, chain.SanityCheckNewHeight(mainnetBlock1, mainnetStateUpdate1,
+			map[felt.Felt]core.Class{*classHash: class}),
+			fmt.Sprintf("class %s: calculated class hash %s does not match", classHash, class.Hash()))
+	})
+
+	t.Run("error when a declared class is missing", func(t *testing.T) {
+		mainnetBlock1, err := gw.BlockByNumber(context.Background(), 1)
+		require.NoError(t, err)
+		mainnetStateUpdate1, err := gw.StateUpdate(context.Background(), 1)
+		require.NoError(t, err)
+
+		stateUpdate := *mainnetStateUpdate1
+		stateUpdate.StateDiff = &core.StateDiff{DeclaredV0Classes: []*felt.Felt{h1}}
+		assert.EqualError(t, chain.SanityCheckNewHeight(mainnetBlock1, &stateUpdate, nil),
+			fmt.Sprintf("class %s: definition of declared class is missing", h1))
+	})
+
+	t.Run("error when a compiled class does not match the declared compiled class hash", func(t *testing.T) {
+		integClient, integCloseFn := feeder.NewTestClient(utils.INTEGRATION)
+		t.Cleanup(integCloseFn)
+		integGw := adaptfeeder.New(integClient)
+
+		classHash := utils.HexToFelt(t, "0x4e70b19333ae94bd958625f7b61ce9eec631653597e68645e13780061b2136c")
+		class, err := integGw.Class(context.Background(), classHash)
+		require.NoError(t, err)
+		compiled := madeUpCompiledClass(t)
+		class.(*core.Cairo1Class).Compiled = compiled
+		compiledClassHash, err := compiled.Hash()
+		require.NoError(t, err)
+
+		mainnetBlock1, err := gw.BlockByNumber(context.Background(), 1)
+		require.NoError(t, err)
+		mainnetStateUpdate1, err := gw.StateUpdate(context.Background(), 1)
+		require.NoError(t, err)
+
+		stateUpdate := *mainnetStateUpdate1
+		stateUpdate.StateDiff = &core.StateDiff{
+			DeclaredV1Classes: []core.DeclaredV1Class{{ClassHash: classHash, CompiledClassHash: h1}},
+		}
+		classes := map[felt.Felt]core.Class{*classHash: class}
+		assert.EqualError(t, chain.SanityCheckNewHeight(mainnetBlock1, &stateUpdate, classes),
+			fmt.Sprintf("class %s: calculated compiled class hash %s does not match declared compiled class hash %s",
+				classHash, compiledClassHash, h1))
+
+		stateUpdate.StateDiff.DeclaredV1Classes[0].CompiledClassHash = compiledClassHash
+		assert.NoError(t, chain.SanityCheckNewHeight(mainnetBlock1, &stateUpdate, classes))
+	})
+
+	t.Run("error when the state diff commitment does not match the state update", func(t *testing.T) {
+		mainnetBlock1, err := gw.BlockByNumber(context.Background(), 1)
+		require.NoError(t, err)
+		mainnetStateUpdate1, err := gw.StateUpdate(context.Background(), 1)
+		require.NoError(t, err)
+
+		classHash := mainnetStateUpdate1.StateDiff.DeployedContracts[0].ClassHash
+		class, err := gw.Class(context.Background(), classHash)
+		require.NoError(t, err)
+		classes := map[felt.Felt]core.Class{*classHash: class}
+
+		mainnetBlock1.StateDiffCommitment = h1
+		assert.EqualError(t, chain.SanityCheckNewHeight(mainnetBlock1, mainnetStateUpdate1, classes),
+			"block's StateDiffCommitment does not match state update's StateDiff")
+
+		mainnetBlock1.StateDiffCommitment = mainnetStateUpdate1.StateDiff.Commitment()
+		mainnetBlock1.StateDiffLength = mainnetStateUpdate1.StateDiff.Length() + 1
+		assert.EqualError(t, chain.SanityCheckNewHeight(mainnetBlock1, mainnetStateUpdate1, classes),
+			"block's StateDiffLength does not match state update's StateDiff")
+
+		mainnetBlock1.StateDiffLength--
+		assert.NoError(t, chain.SanityCheckNewHeight(mainnetBlock1, mainnetStateUpdate1, classes))
+	})
+
+	t.Run("stored classes are not verified again", func(t *testing.T) {
+		mainnetBlock1, err := gw.BlockByNumber(context.Background(), 1)
+		require.NoError(t, err)
+		mainnetStateUpdate1, err := gw.StateUpdate(context.Background(), 1)
+		require.NoError(t, err)
+
+		classHash := mainnetStateUpdate1.StateDiff.DeployedContracts[0].ClassHash
+		class, err := gw.Class(context.Background(), classHash)
+		require.NoError(t, err)
+		require.NoError(t, chain.Store(mainnetBlock1, mainnetStateUpdate1, map[felt.Felt]core.Class{*classHash: class}))
+
+		mainnetBlock2, err := gw.BlockByNumber(context.Background(), 2)
+		require.NoError(t, err)
+		mainnetStateUpdate2, err := gw.StateUpdate(context.Background(), 2)
+		require.NoError(t, err)
+
+		class.(*core.Cairo0Class).Bytecode = nil
+		assert.NoError(t, chain.SanityCheckNewHeight(mainnetBlock2, mainnetStateUpdate2,
+			map[felt.Felt]core.Class{*classHash: class}))
+	})
 }
 
 func TestStore(t *testing.T) {
@@ -330,3 +489,765 @@ func TestTransactionAndReceipt(t *testing.T) {
 		}
 	})
 }
+
+func TestVerifyState(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+
+	testDB := pebble.NewMemTest()
+	chain := blockchain.New(testDB, utils.MAINNET)
+
+	t.Run("empty blockchain", func(t *testing.T) {
+		require.NoError(t, chain.VerifyState(context.Background()))
+	})
+
+	for i := uint64(0); i < 3; i++ {
+		b, err := gw.BlockByNumber(context.Background(), i)
+		require.NoError(t, err)
+
+		su, err := gw.StateUpdate(context.Background(), i)
+		require.NoError(t, err)
+
+		require.NoError(t, chain.Store(b, su, nil))
+	}
+
+	t.Run("consistent blockchain", func(t *testing.T) {
+		require.NoError(t, chain.VerifyState(context.Background()))
+	})
+
+	t.Run("corrupted contract storage root", func(t *testing.T) {
+		su0, err := gw.StateUpdate(context.Background(), 0)
+		require.NoError(t, err)
+
+		var addr felt.Felt
+		for addr = range su0.StateDiff.StorageDiffs {
+			break
+		}
+
+		require.NoError(t, testDB.Update(func(txn db.Transaction) error {
+			return txn.Delete(db.ContractRootKey.Key(addr.Marshal()))
+		}))
+
+		err = chain.VerifyState(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "contract "+addr.String())
+	})
+}
+
+func TestAggregatedStateUpdate(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+
+	chain := blockchain.New(pebble.NewMemTest(), utils.MAINNET)
+
+	updates := make([]*core.StateUpdate, 3)
+	for i := uint64(0); i < 3; i++ {
+		b, err := gw.BlockByNumber(context.Background(), i)
+		require.NoError(t, err)
+
+		updates[i], err = gw.StateUpdate(context.Background(), i)
+		require.NoError(t, err)
+
+		require.NoError(t, chain.Store(b, updates[i], nil))
+	}
+
+	t.Run("error on invalid range", func(t *testing.T) {
+		_, err := chain.AggregatedStateUpdate(2, 1)
+		assert.EqualError(t, err, "invalid block range: 2 is greater than 1")
+	})
+
+	t.Run("error on too large range", func(t *testing.T) {
+		_, err := chain.AggregatedStateUpdate(0, blockchain.MaxAggregatedBlocks)
+		assert.ErrorIs(t, err, blockchain.ErrBlockRangeTooLarge)
+	})
+
+	t.Run("error on missing block", func(t *testing.T) {
+		_, err := chain.AggregatedStateUpdate(1, 3)
+		assert.EqualError(t, err, db.ErrKeyNotFound.Error())
+	})
+
+	t.Run("single block", func(t *testing.T) {
+		update, err := chain.AggregatedStateUpdate(1, 1)
+		require.NoError(t, err)
+		assert.Equal(t, updates[1].OldRoot, update.OldRoot)
+		assert.Equal(t, updates[1].NewRoot, update.NewRoot)
+		assert.Equal(t, updates[1].BlockHash, update.BlockHash)
+		assert.Equal(t, len(updates[1].StateDiff.DeployedContracts), len(update.StateDiff.DeployedContracts))
+		assert.Equal(t, len(updates[1].StateDiff.StorageDiffs), len(update.StateDiff.StorageDiffs))
+	})
+
+	t.Run("applying the aggregated update gives the same state", func(t *testing.T) {
+		update, err := chain.AggregatedStateUpdate(0, 2)
+		require.NoError(t, err)
+		assert.Equal(t, &felt.Zero, update.OldRoot)
+		assert.Equal(t, updates[2].NewRoot, update.NewRoot)
+		assert.Equal(t, updates[2].BlockHash, update.BlockHash)
+
+		txn := pebble.NewMemTest().NewTransaction(true)
+		t.Cleanup(func() {
+			require.NoError(t, txn.Discard())
+		})
+		require.NoError(t, core.NewState(txn).Update(update, nil))
+	})
+}
+
+func TestPruneStateUpdates(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+
+	chain := blockchain.New(pebble.NewMemTest(), utils.MAINNET)
+
+	t.Run("empty blockchain", func(t *testing.T) {
+		pruned, err := chain.PruneStateUpdates(1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(0), pruned)
+	})
+
+	for i := uint64(0); i < 3; i++ {
+		b, err := gw.BlockByNumber(context.Background(), i)
+		require.NoError(t, err)
+
+		su, err := gw.StateUpdate(context.Background(), i)
+		require.NoError(t, err)
+
+		require.NoError(t, chain.Store(b, su, nil))
+	}
+
+	t.Run("at least one block must be kept", func(t *testing.T) {
+		_, err := chain.PruneStateUpdates(0, 10)
+		assert.Error(t, err)
+	})
+
+	t.Run("nothing to prune", func(t *testing.T) {
+		pruned, err := chain.PruneStateUpdates(3, 10)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(0), pruned)
+	})
+
+	t.Run("prune oldest blocks up to the limit", func(t *testing.T) {
+		pruned, err := chain.PruneStateUpdates(1, 1)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), pruned)
+
+		_, err = chain.StateUpdateByNumber(0)
+		assert.ErrorIs(t, err, blockchain.ErrStateUpdatePruned)
+		_, err = chain.StateUpdateByNumber(1)
+		require.NoError(t, err)
+		_, err = chain.AggregatedStateUpdate(0, 2)
+		assert.ErrorIs(t, err, blockchain.ErrStateUpdatePruned)
+
+		pruned, err = chain.PruneStateUpdates(1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), pruned)
+
+		_, err = chain.StateUpdateByNumber(1)
+		assert.ErrorIs(t, err, blockchain.ErrStateUpdatePruned)
+		_, err = chain.StateUpdateByNumber(2)
+		require.NoError(t, err)
+		_, err = chain.StateUpdateByNumber(3)
+		assert.ErrorIs(t, err, db.ErrKeyNotFound)
+	})
+
+	t.Run("pruning does not affect blocks and state", func(t *testing.T) {
+		_, err := chain.BlockByNumber(0)
+		require.NoError(t, err)
+		require.NoError(t, chain.VerifyState(context.Background()))
+	})
+}
+
+func TestRevertHead(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+
+	chain := blockchain.New(pebble.NewMemTest(), utils.MAINNET).WithAddressIndex()
+
+	t.Run("empty blockchain", func(t *testing.T) {
+		assert.ErrorIs(t, chain.RevertHead(), db.ErrKeyNotFound)
+	})
+
+	var (
+		blocks       []*core.Block
+		stateUpdates []*core.StateUpdate
+	)
+	for i := uint64(0); i < 3; i++ {
+		b, err := gw.BlockByNumber(context.Background(), i)
+		require.NoError(t, err)
+
+		su, err := gw.StateUpdate(context.Background(), i)
+		require.NoError(t, err)
+
+		require.NoError(t, chain.Store(b, su, nil))
+		blocks = append(blocks, b)
+		stateUpdates = append(stateUpdates, su)
+	}
+
+	// transactionsByAddress gets the hashes of the transactions of the contract targeted by the
+	// first transaction of the head block
+	var address *felt.Felt
+	switch tx := blocks[2].Transactions[0].(type) {
+	case *core.DeployTransaction:
+		address = tx.ContractAddress
+	case *core.InvokeTransaction:
+		address = tx.ContractAddress
+	}
+	require.NotNil(t, address)
+	transactionsByAddress := func(t *testing.T) []*felt.Felt {
+		t.Helper()
+		hashes, _, err := chain.TransactionsByAddress(address, nil, 100)
+		require.NoError(t, err)
+		return hashes
+	}
+	indexedTransactions := transactionsByAddress(t)
+
+	t.Run("revert head", func(t *testing.T) {
+		require.NoError(t, chain.RevertHead())
+
+		height, err := chain.Height()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), height)
+
+		_, err = chain.BlockByNumber(2)
+		assert.ErrorIs(t, err, db.ErrKeyNotFound)
+		_, err = chain.BlockByHash(blocks[2].Hash)
+		assert.ErrorIs(t, err, db.ErrKeyNotFound)
+		_, err = chain.StateUpdateByNumber(2)
+		assert.ErrorIs(t, err, db.ErrKeyNotFound)
+		for _, tx := range blocks[2].Transactions {
+			_, err = chain.TransactionByHash(tx.Hash())
+			assert.ErrorIs(t, err, db.ErrKeyNotFound)
+			_, _, _, err = chain.Receipt(tx.Hash())
+			assert.ErrorIs(t, err, db.ErrKeyNotFound)
+		}
+
+		root, err := chain.StateCommitment()
+		require.NoError(t, err)
+		assert.Equal(t, blocks[1].GlobalStateRoot, root)
+		require.NoError(t, chain.VerifyState(context.Background()))
+
+		hashes := transactionsByAddress(t)
+		assert.Less(t, len(hashes), len(indexedTransactions))
+		for _, hash := range hashes {
+			_, err = chain.TransactionByHash(hash)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("store the reverted block again", func(t *testing.T) {
+		require.NoError(t, chain.Store(blocks[2], stateUpdates[2], nil))
+		assert.Equal(t, indexedTransactions, transactionsByAddress(t))
+	})
+
+	t.Run("revert every block", func(t *testing.T) {
+		for i := 0; i < len(blocks); i++ {
+			require.NoError(t, chain.RevertHead())
+		}
+
+		_, err := chain.Height()
+		assert.ErrorIs(t, err, db.ErrKeyNotFound)
+		root, err := chain.StateCommitment()
+		require.NoError(t, err)
+		assert.Equal(t, &felt.Zero, root)
+		assert.Empty(t, transactionsByAddress(t))
+
+		for i, b := range blocks {
+			require.NoError(t, chain.Store(b, stateUpdates[i], nil))
+		}
+		assert.Equal(t, indexedTransactions, transactionsByAddress(t))
+	})
+
+	t.Run("blocks whose state updates were pruned cannot be reverted", func(t *testing.T) {
+		_, err := chain.PruneStateUpdates(1, 10)
+		require.NoError(t, err)
+
+		require.NoError(t, chain.RevertHead())
+		assert.ErrorIs(t, chain.RevertHead(), blockchain.ErrStateUpdatePruned)
+	})
+}
+
+func TestStoreCairo1Class(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.INTEGRATION)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+
+	testDB := pebble.NewMemTest()
+	blockchain.New(testDB, utils.INTEGRATION)
+
+	classHash := utils.HexToFelt(t, "0x4e70b19333ae94bd958625f7b61ce9eec631653597e68645e13780061b2136c")
+	compiled := madeUpCompiledClass(t)
+	compiledClassHash, err := compiled.Hash()
+	require.NoError(t, err)
+
+	class, err := gw.Class(context.Background(), classHash)
+	require.NoError(t, err)
+
+	cairo1Class, ok := class.(*core.Cairo1Class)
+	require.True(t, ok)
+	cairo1Class.Compiled = compiled
+
+	// a state with a single declared class
+	leaf := crypto.Poseidon(new(felt.Felt).SetBytes([]byte("CONTRACT_CLASS_LEAF_V0")), compiledClassHash)
+	var classesRoot *felt.Felt
+	require.NoError(t, trie.Config{Hash: crypto.Poseidon, Height: 251}.RunOnTemp(func(classesTrie *trie.Trie) error {
+		_, err = classesTrie.Put(classHash, leaf)
+		require.NoError(t, err)
+		classesRoot, err = classesTrie.Root()
+		return err
+	}))
+
+	su := &core.StateUpdate{
+		OldRoot: new(felt.Felt),
+		NewRoot: crypto.PoseidonArray(new(felt.Felt).SetBytes([]byte("STARKNET_STATE_V0")), new(felt.Felt), classesRoot),
+		StateDiff: &core.StateDiff{
+			DeclaredV1Classes: []core.DeclaredV1Class{{ClassHash: classHash, CompiledClassHash: compiledClassHash}},
+		},
+	}
+
+	declaredClasses := map[felt.Felt]core.Class{*classHash: cairo1Class}
+	var reverseDiff *core.StateReverseDiff
+	require.NoError(t, testDB.Update(func(txn db.Transaction) error {
+		state := core.NewState(txn)
+		if reverseDiff, err = state.ReverseDiff(su, declaredClasses); err != nil {
+			return err
+		}
+		return state.Update(su, declaredClasses)
+	}))
+
+	require.NoError(t, testDB.View(func(txn db.Transaction) error {
+		state := core.NewState(txn)
+
+		gotClass, err := state.Class(classHash)
+		require.NoError(t, err)
+		gotCairo1Class, ok := gotClass.(*core.Cairo1Class)
+		require.True(t, ok)
+		assert.Nil(t, gotCairo1Class.Compiled)
+		assert.Equal(t, classHash, gotCairo1Class.Hash())
+
+		gotCompiled, err := state.CompiledClass(compiledClassHash)
+		require.NoError(t, err)
+		assert.Equal(t, compiled, gotCompiled)
+
+		_, err = state.CompiledClass(classHash)
+		assert.EqualError(t, err, db.ErrKeyNotFound.Error())
+		return nil
+	}))
+
+	t.Run("revert the declaration", func(t *testing.T) {
+		require.NoError(t, testDB.Update(func(txn db.Transaction) error {
+			return core.NewState(txn).Revert(su, reverseDiff)
+		}))
+
+		require.NoError(t, testDB.View(func(txn db.Transaction) error {
+			state := core.NewState(txn)
+
+			root, err := state.Root()
+			require.NoError(t, err)
+			assert.Equal(t, &felt.Zero, root)
+
+			_, err = state.Class(classHash)
+			assert.ErrorIs(t, err, db.ErrKeyNotFound)
+			_, err = state.CompiledClass(compiledClassHash)
+			assert.ErrorIs(t, err, db.ErrKeyNotFound)
+			return nil
+		}))
+	})
+}
+
+// madeUpCompiledClass returns a CASM that no class on any network compiles to.
+func madeUpCompiledClass(t *testing.T) *core.CompiledClass {
+	t.Helper()
+
+	prime, ok := new(big.Int).SetString("0x800000000000011000000000000000000000000000000000000000000000001", 0)
+	require.True(t, ok)
+	return &core.CompiledClass{
+		Bytecode: []*felt.Felt{
+			utils.HexToFelt(t, "0xa0680017fff8000"),
+			utils.HexToFelt(t, "0x7"),
+			utils.HexToFelt(t, "0x208b7fff7fff7ffe"),
+		},
+		BytecodeSegmentLengths: &core.SegmentLengths{Children: []core.SegmentLengths{{Length: 2}, {Length: 1}}},
+		PythonicHints:          json.RawMessage("[]"),
+		CompilerVersion:        "2.6.0",
+		Hints:                  json.RawMessage("[]"),
+		Prime:                  prime,
+		External: []core.CompiledEntryPoint{{
+			Offset:   0,
+			Builtins: []string{"range_check"},
+			Selector: utils.HexToFelt(t, "0x22ff5f21f0b81b113e63f7db6da94fedef11b2119b4088b89664fb9a3cb658"),
+		}},
+		L1Handler:   []core.CompiledEntryPoint{},
+		Constructor: []core.CompiledEntryPoint{},
+	}
+}
+
+func TestL1HandlerTxnHash(t *testing.T) {
+	chain := blockchain.New(pebble.NewMemTest(), utils.MAINNET)
+
+	msgHash := common.HexToHash("0x6563d03b2a3a40c8deabb304dc06dc5ee953f5c7adb757e3a2960abc07f449d4")
+	_, err := chain.L1HandlerTxnHash(&msgHash)
+	assert.ErrorIs(t, err, db.ErrKeyNotFound)
+}
+
+func TestL2ToL1Messages(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+
+	chain := blockchain.New(pebble.NewMemTest(), utils.MAINNET)
+
+	var sent []*core.L2ToL1Message
+	for i := uint64(0); i < 3; i++ {
+		b, err := gw.BlockByNumber(context.Background(), i)
+		require.NoError(t, err)
+
+		su, err := gw.StateUpdate(context.Background(), i)
+		require.NoError(t, err)
+
+		require.NoError(t, chain.Store(b, su, nil))
+		for _, r := range b.Receipts {
+			sent = append(sent, r.L2ToL1Message...)
+		}
+	}
+	require.NotEmpty(t, sent)
+
+	t.Run("by hash", func(t *testing.T) {
+		msgHash := sent[0].Hash()
+		records, err := chain.L2ToL1MessagesByHash(&msgHash)
+		require.NoError(t, err)
+		require.NotEmpty(t, records)
+		for _, record := range records {
+			assert.Equal(t, msgHash, record.Hash)
+			assert.Equal(t, sent[0], record.Message)
+		}
+
+		unknown := common.Hash{}
+		records, err = chain.L2ToL1MessagesByHash(&unknown)
+		require.NoError(t, err)
+		assert.Empty(t, records)
+	})
+
+	recipient := sent[0].To
+	var toRecipient []*core.L2ToL1Message
+	for _, msg := range sent {
+		if msg.To == recipient {
+			toRecipient = append(toRecipient, msg)
+		}
+	}
+
+	t.Run("by recipient", func(t *testing.T) {
+		records, next, err := chain.L2ToL1MessagesByRecipient(recipient, &blockchain.L2ToL1MessageLocation{}, 2, 100)
+		require.NoError(t, err)
+		assert.Nil(t, next)
+		require.Len(t, records, len(toRecipient))
+		for i, record := range records {
+			assert.Equal(t, toRecipient[i], record.Message)
+		}
+	})
+
+	t.Run("by recipient with pagination", func(t *testing.T) {
+		start := &blockchain.L2ToL1MessageLocation{}
+		var got []*core.L2ToL1Message
+		for start != nil {
+			records, next, err := chain.L2ToL1MessagesByRecipient(recipient, start, 2, 1)
+			require.NoError(t, err)
+			require.Len(t, records, 1)
+			got = append(got, records[0].Message)
+			start = next
+		}
+		assert.Equal(t, toRecipient, got)
+	})
+
+	t.Run("by recipient from a nil start", func(t *testing.T) {
+		records, _, err := chain.L2ToL1MessagesByRecipient(recipient, nil, 2, 1)
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, toRecipient[0], records[0].Message)
+	})
+
+	t.Run("by recipient up to block", func(t *testing.T) {
+		records, _, err := chain.L2ToL1MessagesByRecipient(recipient, &blockchain.L2ToL1MessageLocation{}, 0, 100)
+		require.NoError(t, err)
+		for _, record := range records {
+			assert.Equal(t, uint64(0), record.BlockNumber)
+		}
+	})
+
+	t.Run("limit must be positive", func(t *testing.T) {
+		_, _, err := chain.L2ToL1MessagesByRecipient(recipient, &blockchain.L2ToL1MessageLocation{}, 2, 0)
+		assert.Error(t, err)
+	})
+
+	t.Run("messages of reverted blocks are removed", func(t *testing.T) {
+		records, _, err := chain.L2ToL1MessagesByRecipient(recipient, nil, 2, 100)
+		require.NoError(t, err)
+		var inGenesis []*blockchain.L2ToL1MessageRecord
+		for _, record := range records {
+			if record.BlockNumber == 0 {
+				inGenesis = append(inGenesis, record)
+			}
+		}
+
+		require.NoError(t, chain.RevertHead())
+		require.NoError(t, chain.RevertHead())
+		records, _, err = chain.L2ToL1MessagesByRecipient(recipient, nil, 2, 100)
+		require.NoError(t, err)
+		assert.Equal(t, inGenesis, records)
+
+		require.NoError(t, chain.RevertHead())
+		records, _, err = chain.L2ToL1MessagesByRecipient(recipient, nil, 2, 100)
+		require.NoError(t, err)
+		assert.Empty(t, records)
+		msgHash := sent[0].Hash()
+		records, err = chain.L2ToL1MessagesByHash(&msgHash)
+		require.NoError(t, err)
+		assert.Empty(t, records)
+	})
+}
+
+func TestTransactionsByAddress(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+
+	chain := blockchain.New(pebble.NewMemTest(), utils.MAINNET).WithAddressIndex()
+	unindexedChain := blockchain.New(pebble.NewMemTest(), utils.MAINNET)
+
+	var blocks []*core.Block
+	for i := uint64(0); i < 3; i++ {
+		b, err := gw.BlockByNumber(context.Background(), i)
+		require.NoError(t, err)
+
+		su, err := gw.StateUpdate(context.Background(), i)
+		require.NoError(t, err)
+
+		require.NoError(t, chain.Store(b, su, nil))
+		require.NoError(t, unindexedChain.Store(b, su, nil))
+		blocks = append(blocks, b)
+	}
+
+	// the contract deployed by the first transaction of the genesis block is invoked later on
+	deploy, ok := blocks[0].Transactions[0].(*core.DeployTransaction)
+	require.True(t, ok)
+	address := deploy.ContractAddress
+	var want []*felt.Felt
+	for _, b := range blocks {
+		for _, tx := range b.Transactions {
+			switch tx := tx.(type) {
+			case *core.DeployTransaction:
+				if tx.ContractAddress.Equal(address) {
+					want = append(want, tx.Hash())
+				}
+			case *core.InvokeTransaction:
+				if tx.ContractAddress.Equal(address) {
+					want = append(want, tx.Hash())
+				}
+			}
+		}
+	}
+	require.Greater(t, len(want), 1)
+
+	t.Run("all transactions", func(t *testing.T) {
+		hashes, next, err := chain.TransactionsByAddress(address, &blockchain.TransactionLocation{}, 100)
+		require.NoError(t, err)
+		assert.Nil(t, next)
+		assert.Equal(t, want, hashes)
+	})
+
+	t.Run("pagination", func(t *testing.T) {
+		start := &blockchain.TransactionLocation{}
+		var got []*felt.Felt
+		for start != nil {
+			hashes, next, err := chain.TransactionsByAddress(address, start, 1)
+			require.NoError(t, err)
+			require.Len(t, hashes, 1)
+			got = append(got, hashes...)
+			start = next
+		}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("nil start is the first transaction", func(t *testing.T) {
+		expected, expectedNext, err := chain.TransactionsByAddress(address, &blockchain.TransactionLocation{}, 1)
+		require.NoError(t, err)
+
+		hashes, next, err := chain.TransactionsByAddress(address, nil, 1)
+		require.NoError(t, err)
+		assert.Equal(t, expected, hashes)
+		assert.Equal(t, expectedNext, next)
+	})
+
+	t.Run("unknown address", func(t *testing.T) {
+		hashes, next, err := chain.TransactionsByAddress(new(felt.Felt).SetUint64(1), &blockchain.TransactionLocation{}, 100)
+		require.NoError(t, err)
+		assert.Nil(t, next)
+		assert.Empty(t, hashes)
+	})
+
+	t.Run("limit must be positive", func(t *testing.T) {
+		_, _, err := chain.TransactionsByAddress(address, &blockchain.TransactionLocation{}, 0)
+		assert.Error(t, err)
+	})
+
+	t.Run("index disabled", func(t *testing.T) {
+		_, _, err := unindexedChain.TransactionsByAddress(address, &blockchain.TransactionLocation{}, 100)
+		assert.ErrorIs(t, err, blockchain.ErrAddressIndexDisabled)
+		_, err = unindexedChain.IndexAddressActivity(10)
+		assert.ErrorIs(t, err, blockchain.ErrAddressIndexDisabled)
+	})
+
+	t.Run("index blocks stored while the index was disabled", func(t *testing.T) {
+		unindexedChain.WithAddressIndex()
+		hashes, _, err := unindexedChain.TransactionsByAddress(address, &blockchain.TransactionLocation{}, 100)
+		require.NoError(t, err)
+		assert.Empty(t, hashes)
+
+		indexed, err := unindexedChain.IndexAddressActivity(2)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2), indexed)
+		indexed, err = unindexedChain.IndexAddressActivity(2)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), indexed)
+		indexed, err = unindexedChain.IndexAddressActivity(2)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(0), indexed)
+
+		hashes, _, err = unindexedChain.TransactionsByAddress(address, &blockchain.TransactionLocation{}, 100)
+		require.NoError(t, err)
+		assert.Equal(t, want, hashes)
+	})
+
+	t.Run("blocks are not indexed out of order", func(t *testing.T) {
+		lateChain := blockchain.New(pebble.NewMemTest(), utils.MAINNET)
+		su, err := gw.StateUpdate(context.Background(), 0)
+		require.NoError(t, err)
+		require.NoError(t, lateChain.Store(blocks[0], su, nil))
+
+		lateChain.WithAddressIndex()
+		su, err = gw.StateUpdate(context.Background(), 1)
+		require.NoError(t, err)
+		require.NoError(t, lateChain.Store(blocks[1], su, nil))
+
+		hashes, _, err := lateChain.TransactionsByAddress(address, &blockchain.TransactionLocation{}, 100)
+		require.NoError(t, err)
+		assert.Empty(t, hashes)
+
+		indexed, err := lateChain.IndexAddressActivity(10)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2), indexed)
+	})
+}
+
+func TestClassIndexes(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+
+	chain := blockchain.New(pebble.NewMemTest(), utils.MAINNET)
+
+	var updates []*core.StateUpdate
+	for i := uint64(0); i < 3; i++ {
+		b, err := gw.BlockByNumber(context.Background(), i)
+		require.NoError(t, err)
+
+		su, err := gw.StateUpdate(context.Background(), i)
+		require.NoError(t, err)
+
+		require.NoError(t, chain.Store(b, su, nil))
+		updates = append(updates, su)
+	}
+
+	// contracts were deployed without declaring their class first in the genesis block
+	genesis, err := chain.BlockByNumber(0)
+	require.NoError(t, err)
+	deploy, ok := genesis.Transactions[0].(*core.DeployTransaction)
+	require.True(t, ok)
+	classHash := deploy.ClassHash
+
+	t.Run("class declaration", func(t *testing.T) {
+		declaration, err := chain.ClassDeclaration(classHash)
+		require.NoError(t, err)
+		assert.Equal(t, &blockchain.ClassDeclaration{BlockNumber: 0, TransactionHash: deploy.Hash()}, declaration)
+
+		_, err = chain.ClassDeclaration(new(felt.Felt).SetUint64(1))
+		assert.ErrorIs(t, err, db.ErrKeyNotFound)
+	})
+
+	var want []*blockchain.ClassInstance
+	for number, su := range updates {
+		for _, contract := range su.StateDiff.DeployedContracts {
+			if contract.ClassHash.Equal(classHash) {
+				want = append(want, &blockchain.ClassInstance{Address: contract.Address, BlockNumber: uint64(number)})
+			}
+		}
+	}
+	sort.Slice(want, func(i, j int) bool {
+		return want[i].Address.Cmp(want[j].Address) < 0
+	})
+	require.Greater(t, len(want), 1)
+
+	t.Run("class instances", func(t *testing.T) {
+		instances, next, err := chain.ClassInstances(classHash, new(felt.Felt), 100)
+		require.NoError(t, err)
+		assert.Nil(t, next)
+		assert.Equal(t, want, instances)
+	})
+
+	t.Run("class instances with pagination", func(t *testing.T) {
+		start := new(felt.Felt)
+		var got []*blockchain.ClassInstance
+		for start != nil {
+			instances, next, err := chain.ClassInstances(classHash, start, 1)
+			require.NoError(t, err)
+			require.Len(t, instances, 1)
+			got = append(got, instances...)
+			start = next
+		}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("class instances from a nil start", func(t *testing.T) {
+		instances, _, err := chain.ClassInstances(classHash, nil, 1)
+		require.NoError(t, err)
+		assert.Equal(t, want[:1], instances)
+	})
+
+	t.Run("unknown class has no instances", func(t *testing.T) {
+		instances, next, err := chain.ClassInstances(new(felt.Felt).SetUint64(1), new(felt.Felt), 100)
+		require.NoError(t, err)
+		assert.Nil(t, next)
+		assert.Empty(t, instances)
+	})
+
+	t.Run("limit must be positive", func(t *testing.T) {
+		_, _, err := chain.ClassInstances(classHash, new(felt.Felt), 0)
+		assert.Error(t, err)
+	})
+
+	t.Run("classes and instances of reverted blocks are removed", func(t *testing.T) {
+		var inGenesis []*blockchain.ClassInstance
+		for _, instance := range want {
+			if instance.BlockNumber == 0 {
+				inGenesis = append(inGenesis, instance)
+			}
+		}
+
+		require.NoError(t, chain.RevertHead())
+		require.NoError(t, chain.RevertHead())
+		instances, _, err := chain.ClassInstances(classHash, nil, 100)
+		require.NoError(t, err)
+		assert.Equal(t, inGenesis, instances)
+		declaration, err := chain.ClassDeclaration(classHash)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(0), declaration.BlockNumber)
+
+		require.NoError(t, chain.RevertHead())
+		instances, _, err = chain.ClassInstances(classHash, nil, 100)
+		require.NoError(t, err)
+		assert.Empty(t, instances)
+		_, err = chain.ClassDeclaration(classHash)
+		assert.ErrorIs(t, err, db.ErrKeyNotFound)
+	})
+}
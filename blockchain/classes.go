@@ -0,0 +1,218 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db"
+)
+
+// ClassDeclaration is where a class was declared.
+type ClassDeclaration struct {
+	BlockNumber uint64
+	// TransactionHash is the hash of the declare transaction of the class. Classes that were
+	// declared implicitly, by deploying a contract before declare transactions existed, have the
+	// hash of the deploy transaction instead. It is nil if no such transaction is in the block.
+	TransactionHash *felt.Felt
+}
+
+// ClassInstance is a contract that uses, or used, a class.
+type ClassInstance struct {
+	Address *felt.Felt
+	// BlockNumber is the number of the block in which the contract started using the class, by
+	// being deployed or by replacing its class.
+	BlockNumber uint64
+	// Replaced is set if the contract replaced the class by another one since.
+	Replaced bool
+}
+
+// storeClassIndexes indexes the classes declared and instantiated in a block as follows:
+//
+// [db.ClassDeclarations](ClassHash) -> (BlockNumber, TransactionHash)
+// [db.ClassInstances](ClassHash, ContractAddress) -> (BlockNumber)
+//
+// The TransactionHash is omitted if the declaring transaction is not known. Classes and instances
+// are indexed the first time they appear only. The entries of the blocks removed by
+// [Blockchain.RevertHead] are removed by deleteClassIndexes.
+func storeClassIndexes(txn db.Transaction, number uint64, transactions []core.Transaction, diff *core.StateDiff) error {
+	declarers := make(map[felt.Felt]*felt.Felt)
+	for _, tx := range transactions {
+		var classHash *felt.Felt
+		switch tx := tx.(type) {
+		case *core.DeclareTransaction:
+			classHash = tx.ClassHash
+		case *core.DeployTransaction:
+			classHash = tx.ClassHash
+		default:
+			continue
+		}
+		if _, found := declarers[*classHash]; !found {
+			declarers[*classHash] = tx.Hash()
+		}
+	}
+
+	numBytes := binary.BigEndian.AppendUint64(nil, number)
+	for _, classHash := range declaredClassHashes(diff) {
+		declaration := numBytes
+		if txHash := declarers[*classHash]; txHash != nil {
+			declaration = append(append([]byte{}, numBytes...), txHash.Marshal()...)
+		}
+		if err := setIfAbsent(txn, db.ClassDeclarations.Key(classHash.Marshal()), declaration); err != nil {
+			return err
+		}
+	}
+
+	for _, instance := range classInstances(diff) {
+		if err := setIfAbsent(txn, db.ClassInstances.Key(instance.ClassHash.Marshal(), instance.Address.Marshal()),
+			numBytes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteClassIndexes removes the classes declared and instantiated in a block from the indexes,
+// unless they first appeared in an earlier block.
+func deleteClassIndexes(txn db.Transaction, number uint64, diff *core.StateDiff) error {
+	var keys [][]byte
+	for _, classHash := range declaredClassHashes(diff) {
+		keys = append(keys, db.ClassDeclarations.Key(classHash.Marshal()))
+	}
+	for _, instance := range classInstances(diff) {
+		keys = append(keys, db.ClassInstances.Key(instance.ClassHash.Marshal(), instance.Address.Marshal()))
+	}
+
+	for _, key := range keys {
+		var indexedNumber uint64
+		err := txn.Get(key, func(val []byte) error {
+			if len(val) < lenOfByteSlice {
+				return errors.New("invalid class index entry")
+			}
+			indexedNumber = binary.BigEndian.Uint64(val)
+			return nil
+		})
+		if errors.Is(err, db.ErrKeyNotFound) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if indexedNumber == number {
+			if err = txn.Delete(key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// declaredClassHashes returns the hashes of the classes a state diff declares.
+func declaredClassHashes(diff *core.StateDiff) []*felt.Felt {
+	declared := append([]*felt.Felt{}, diff.DeclaredV0Classes...)
+	for _, class := range diff.DeclaredV1Classes {
+		declared = append(declared, class.ClassHash)
+	}
+	// contracts could be deployed without declaring their class first in the early blocks
+	for _, contract := range diff.DeployedContracts {
+		declared = append(declared, contract.ClassHash)
+	}
+	return declared
+}
+
+// classInstances returns the contracts that start using a class in a state diff, by being deployed
+// or by replacing their class.
+func classInstances(diff *core.StateDiff) []core.DeployedContract {
+	instances := make([]core.DeployedContract, 0, len(diff.DeployedContracts)+len(diff.ReplacedClasses))
+	instances = append(instances, diff.DeployedContracts...)
+	for _, replaced := range diff.ReplacedClasses {
+		instances = append(instances, core.DeployedContract(replaced))
+	}
+	return instances
+}
+
+func setIfAbsent(txn db.Transaction, key, value []byte) error {
+	err := txn.Get(key, func([]byte) error { return nil })
+	if errors.Is(err, db.ErrKeyNotFound) {
+		return txn.Set(key, value)
+	}
+	return err
+}
+
+// ClassDeclaration gets where the class with the given hash was declared.
+func (b *Blockchain) ClassDeclaration(classHash *felt.Felt) (*ClassDeclaration, error) {
+	var declaration *ClassDeclaration
+	return declaration, b.database.View(func(txn db.Transaction) error {
+		return txn.Get(db.ClassDeclarations.Key(classHash.Marshal()), func(val []byte) error {
+			if len(val) != lenOfByteSlice && len(val) != lenOfByteSlice+felt.Bytes {
+				return errors.New("invalid class declaration")
+			}
+			declaration = &ClassDeclaration{BlockNumber: binary.BigEndian.Uint64(val)}
+			if len(val) > lenOfByteSlice {
+				declaration.TransactionHash = new(felt.Felt).SetBytes(val[lenOfByteSlice:])
+			}
+			return nil
+		})
+	})
+}
+
+// ClassInstances gets at most `limit` contracts that use, or used, the class with the given hash,
+// in address order, from address `start`, or from the lowest address if `start` is nil. The address
+// of the next contract is returned if there are more contracts, nil otherwise.
+func (b *Blockchain) ClassInstances(classHash, start *felt.Felt, limit uint64) ([]*ClassInstance, *felt.Felt, error) {
+	if limit == 0 {
+		return nil, nil, errors.New("limit must be positive")
+	}
+	if start == nil {
+		start = new(felt.Felt)
+	}
+
+	var (
+		instances []*ClassInstance
+		next      *felt.Felt
+	)
+	return instances, next, b.database.View(func(txn db.Transaction) error {
+		iterator, err := txn.NewIterator()
+		if err != nil {
+			return err
+		}
+
+		prefix := db.ClassInstances.Key(classHash.Marshal())
+		for iterator.Seek(db.ClassInstances.Key(classHash.Marshal(), start.Marshal())); iterator.Valid(); iterator.Next() {
+			key := iterator.Key()
+			if !bytes.HasPrefix(key, prefix) {
+				break
+			}
+
+			address := new(felt.Felt).SetBytes(key[len(prefix):])
+			if uint64(len(instances)) == limit {
+				next = address
+				break
+			}
+
+			val, err := iterator.Value()
+			if err != nil {
+				return db.CloseAndWrapOnError(iterator.Close, err)
+			}
+			instances = append(instances, &ClassInstance{
+				Address:     address,
+				BlockNumber: binary.BigEndian.Uint64(val),
+			})
+		}
+		if err = iterator.Close(); err != nil {
+			return err
+		}
+
+		state := core.NewState(txn)
+		for _, instance := range instances {
+			currentClassHash, err := state.ContractClassHash(instance.Address)
+			if err != nil {
+				return err
+			}
+			instance.Replaced = !currentClassHash.Equal(classHash)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,177 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// L2ToL1MessageLocation is the position of an L2 to L1 message in the chain.
+type L2ToL1MessageLocation struct {
+	BlockNumber      uint64
+	TransactionIndex uint64
+	MessageIndex     uint64
+}
+
+func (l *L2ToL1MessageLocation) MarshalBinary() []byte {
+	key := binary.BigEndian.AppendUint64(nil, l.BlockNumber)
+	key = binary.BigEndian.AppendUint64(key, l.TransactionIndex)
+	return binary.BigEndian.AppendUint64(key, l.MessageIndex)
+}
+
+func (l *L2ToL1MessageLocation) UnmarshalBinary(data []byte) error {
+	if len(data) != 3*lenOfByteSlice {
+		return errors.New("invalid L2 to L1 message location")
+	}
+	l.BlockNumber = binary.BigEndian.Uint64(data)
+	l.TransactionIndex = binary.BigEndian.Uint64(data[lenOfByteSlice:])
+	l.MessageIndex = binary.BigEndian.Uint64(data[2*lenOfByteSlice:])
+	return nil
+}
+
+// L2ToL1MessageRecord is an L2 to L1 message together with the transaction that sent it.
+type L2ToL1MessageRecord struct {
+	L2ToL1MessageLocation
+	Hash            common.Hash
+	TransactionHash *felt.Felt
+	Message         *core.L2ToL1Message
+}
+
+// storeL2ToL1Messages indexes the L2 to L1 messages sent by a transaction as follows:
+//
+// [db.L2ToL1MessagesByHash](MessageHash, BlockNumber, TransactionIndex, MessageIndex) -> ()
+// [db.L2ToL1MessagesByRecipient](Recipient, BlockNumber, TransactionIndex, MessageIndex) -> ()
+//
+// The same message can be sent more than once, so each one is indexed by its location. The
+// messages themselves are read from the receipts. The entries of the blocks removed by
+// [Blockchain.RevertHead] are removed by deleteL2ToL1Messages.
+func storeL2ToL1Messages(txn db.Transaction, blockNumber, txIndex uint64, r *core.TransactionReceipt) error {
+	for _, key := range l2ToL1MessageKeys(blockNumber, txIndex, r) {
+		if err := txn.Set(key, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteL2ToL1Messages removes the L2 to L1 messages sent by a transaction from the indexes.
+func deleteL2ToL1Messages(txn db.Transaction, blockNumber, txIndex uint64, r *core.TransactionReceipt) error {
+	for _, key := range l2ToL1MessageKeys(blockNumber, txIndex, r) {
+		if err := txn.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// l2ToL1MessageKeys returns the keys the L2 to L1 messages sent by a transaction are indexed under.
+func l2ToL1MessageKeys(blockNumber, txIndex uint64, r *core.TransactionReceipt) [][]byte {
+	keys := make([][]byte, 0, 2*len(r.L2ToL1Message))
+	for i, msg := range r.L2ToL1Message {
+		location := (&L2ToL1MessageLocation{blockNumber, txIndex, uint64(i)}).MarshalBinary()
+		msgHash := msg.Hash()
+		keys = append(keys, db.L2ToL1MessagesByHash.Key(msgHash.Bytes(), location),
+			db.L2ToL1MessagesByRecipient.Key(msg.To.Bytes(), location))
+	}
+	return keys
+}
+
+// L2ToL1MessagesByHash gets the L2 to L1 messages with the given hash, oldest first.
+func (b *Blockchain) L2ToL1MessagesByHash(msgHash *common.Hash) ([]*L2ToL1MessageRecord, error) {
+	var records []*L2ToL1MessageRecord
+	return records, b.database.View(func(txn db.Transaction) error {
+		var err error
+		records, _, err = l2ToL1Messages(txn, db.L2ToL1MessagesByHash.Key(msgHash.Bytes()),
+			&L2ToL1MessageLocation{}, nil, 0)
+		return err
+	})
+}
+
+// L2ToL1MessagesByRecipient gets at most `limit` L2 to L1 messages sent to the given L1 address,
+// oldest first, from location `start`, or from the first message if `start` is nil, up to block
+// `toBlock` (inclusive). The location of the next message is returned if there are more messages
+// in the range, nil otherwise.
+func (b *Blockchain) L2ToL1MessagesByRecipient(recipient common.Address, start *L2ToL1MessageLocation,
+	toBlock, limit uint64,
+) ([]*L2ToL1MessageRecord, *L2ToL1MessageLocation, error) {
+	if limit == 0 {
+		return nil, nil, errors.New("limit must be positive")
+	}
+	if start == nil {
+		start = new(L2ToL1MessageLocation)
+	}
+
+	var (
+		records []*L2ToL1MessageRecord
+		next    *L2ToL1MessageLocation
+	)
+	return records, next, b.database.View(func(txn db.Transaction) error {
+		var err error
+		records, next, err = l2ToL1Messages(txn, db.L2ToL1MessagesByRecipient.Key(recipient.Bytes()),
+			start, &toBlock, limit)
+		return err
+	})
+}
+
+// l2ToL1Messages reads the messages indexed under prefix from location start up to block toBlock,
+// if not nil. At most limit messages are read, unless it is 0.
+func l2ToL1Messages(txn db.Transaction, prefix []byte, start *L2ToL1MessageLocation, toBlock *uint64,
+	limit uint64,
+) ([]*L2ToL1MessageRecord, *L2ToL1MessageLocation, error) {
+	iterator, err := txn.NewIterator()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var locations []L2ToL1MessageLocation
+	var next *L2ToL1MessageLocation
+	seekKey := append(append([]byte{}, prefix...), start.MarshalBinary()...)
+	for iterator.Seek(seekKey); iterator.Valid(); iterator.Next() {
+		key := iterator.Key()
+		if !bytes.HasPrefix(key, prefix) {
+			break
+		}
+
+		var location L2ToL1MessageLocation
+		if err = location.UnmarshalBinary(key[len(prefix):]); err != nil {
+			return nil, nil, db.CloseAndWrapOnError(iterator.Close, err)
+		}
+		if toBlock != nil && location.BlockNumber > *toBlock {
+			break
+		}
+		if limit != 0 && uint64(len(locations)) == limit {
+			next = &location
+			break
+		}
+		locations = append(locations, location)
+	}
+	if err = iterator.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	records := make([]*L2ToL1MessageRecord, 0, len(locations))
+	for _, location := range locations {
+		bnIndex := &txAndReceiptDBKey{location.BlockNumber, location.TransactionIndex}
+		receipt, err := receiptByBlockNumberAndIndex(txn, bnIndex)
+		if err != nil {
+			return nil, nil, err
+		}
+		if location.MessageIndex >= uint64(len(receipt.L2ToL1Message)) {
+			return nil, nil, errors.New("L2 to L1 message index out of range")
+		}
+
+		msg := receipt.L2ToL1Message[location.MessageIndex]
+		records = append(records, &L2ToL1MessageRecord{
+			L2ToL1MessageLocation: location,
+			Hash:                  msg.Hash(),
+			TransactionHash:       receipt.TransactionHash,
+			Message:               msg,
+		})
+	}
+	return records, next, nil
+}
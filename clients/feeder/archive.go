@@ -0,0 +1,319 @@
+package feeder
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/NethermindEth/juno/core/felt"
+)
+
+// archiveURL is the base URL of clients that read from an archive. It is never dialled.
+const archiveURL = "http://archive/feeder_gateway/"
+
+// archive gives access to the files of a directory or tarball. Names use forward slashes
+// and are relative to the root of the archive, e.g. "block/0.json".
+type archive struct {
+	readFile func(name string) ([]byte, error)
+	// listFiles returns the names of the files in dir, e.g. "0.json" for "block".
+	listFiles func(dir string) ([]string, error)
+}
+
+// NewArchiveClient returns a client that reads feeder gateway responses from a local archive
+// instead of the network. The archive is either a directory or a tarball (optionally gzipped)
+// with the same layout as the feeder testdata of a network, i.e. block/<number>.json,
+// state_update/<number>.json, transaction/<hash>.json, class/<hash>.json,
+// compiled_class/<hash>.json and so on, see archiveEndpoints for the full list. Requests for
+// the latest block or for a block by its hash are served from the numbered files, unless the
+// archive holds a response recorded for them.
+//
+// Tarballs are loaded into memory, a directory should be used for large archives.
+func NewArchiveClient(archivePath string) (*Client, error) {
+	a, err := openArchive(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	client := NewClient(archiveURL).WithBackoff(NopBackoff).WithMaxRetries(0)
+	client.client = &http.Client{Transport: archiveTransport{archive: a}}
+	return client, nil
+}
+
+// openArchive returns the archive of the directory or tarball at archivePath.
+func openArchive(archivePath string) (*archive, error) {
+	info, err := os.Stat(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		return &archive{
+			readFile: func(name string) ([]byte, error) {
+				return os.ReadFile(filepath.Join(archivePath, filepath.FromSlash(name)))
+			},
+			listFiles: func(dir string) ([]string, error) {
+				entries, err := os.ReadDir(filepath.Join(archivePath, filepath.FromSlash(dir)))
+				if err != nil {
+					return nil, err
+				}
+				names := make([]string, 0, len(entries))
+				for _, entry := range entries {
+					if entry.Type().IsRegular() {
+						names = append(names, entry.Name())
+					}
+				}
+				return names, nil
+			},
+		}, nil
+	}
+
+	a, err := loadTarball(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("load archive %s: %w", archivePath, err)
+	}
+	return a, nil
+}
+
+// loadTarball reads all JSON files of a tarball into memory. Files are also indexed relative
+// to their top-level directory, so that tarballs of a directory such as mainnet/ can be used
+// as they are.
+func loadTarball(tarballPath string) (*archive, error) {
+	f, err := os.Open(tarballPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(tarballPath, ".gz") || strings.HasSuffix(tarballPath, ".tgz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg || path.Ext(header.Name) != ".json" {
+			continue
+		}
+
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		name := path.Clean(header.Name)
+		files[name] = content
+		// also index the file relative to the top-level directory of the tarball
+		if _, rest, found := strings.Cut(name, "/"); found {
+			files[rest] = content
+		}
+	}
+
+	return &archive{
+		readFile: func(name string) ([]byte, error) {
+			content, found := files[name]
+			if !found {
+				return nil, fs.ErrNotExist
+			}
+			return content, nil
+		},
+		listFiles: func(dir string) ([]string, error) {
+			var names []string
+			for name := range files {
+				if path.Dir(name) == dir {
+					names = append(names, path.Base(name))
+				}
+			}
+			return names, nil
+		},
+	}, nil
+}
+
+// archiveTransport serves the requests of a [Client] from an archive.
+type archiveTransport struct {
+	archive *archive
+}
+
+func (t archiveTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	status, body := t.archive.response(req.URL)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Header:        make(http.Header),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// archiveEndpoint describes where the responses of a feeder gateway endpoint are kept in an archive.
+type archiveEndpoint struct {
+	dir string
+	// args are the query arguments that identify a response, in the order they appear in
+	// the file name. The block the query refers to, if any, is always appended.
+	args []string
+	// blockAddressed is set for endpoints that report missing responses as missing blocks.
+	blockAddressed bool
+}
+
+var archiveEndpoints = map[string]archiveEndpoint{
+	"get_block":                        {dir: "block", blockAddressed: true},
+	"get_state_update":                 {dir: "state_update", blockAddressed: true},
+	"get_signature":                    {dir: "signature", blockAddressed: true},
+	"get_transaction":                  {dir: "transaction", args: []string{"transactionHash"}},
+	"get_transaction_receipt":          {dir: "transaction_receipt", args: []string{"transactionHash"}},
+	"get_transaction_status":           {dir: "transaction_status", args: []string{"transactionHash"}},
+	"get_class_by_hash":                {dir: "class", args: []string{"classHash"}},
+	"get_compiled_class_by_class_hash": {dir: "compiled_class", args: []string{"classHash"}},
+	"get_class_hash_at":                {dir: "class_hash_at", args: []string{"contractAddress"}},
+	"get_storage_at":                   {dir: "storage_at", args: []string{"contractAddress", "key"}},
+	"get_nonce":                        {dir: "nonce", args: []string{"contractAddress"}},
+	"get_public_key":                   {dir: "public_key"},
+	"get_contract_addresses":           {dir: "contract_addresses"},
+}
+
+// archiveFileName returns the name of the archive file that holds the response to a request
+// for reqURL, e.g. block/0.json for get_block?blockNumber=0. Responses that don't depend on
+// any argument, such as get_public_key, are kept in a file named after their directory.
+func archiveFileName(reqURL *url.URL) (name string, endpoint archiveEndpoint, ok bool) {
+	queryMap, err := url.ParseQuery(reqURL.RawQuery)
+	if err != nil {
+		return "", archiveEndpoint{}, false
+	}
+
+	endpoint, found := archiveEndpoints[path.Base(reqURL.Path)]
+	if !found {
+		return "", archiveEndpoint{}, false
+	}
+
+	var values []string
+	for _, arg := range endpoint.args {
+		value := queryMap.Get(arg)
+		if value == "" {
+			return "", archiveEndpoint{}, false
+		}
+		values = append(values, value)
+	}
+	for _, arg := range []string{"blockNumber", "blockHash"} {
+		if value := queryMap.Get(arg); value != "" {
+			values = append(values, value)
+			break
+		}
+	}
+	if endpoint.blockAddressed && len(values) == 0 {
+		return "", archiveEndpoint{}, false
+	}
+
+	fileName := strings.Join(values, "_")
+	if fileName == "" {
+		fileName = endpoint.dir
+	} else if strings.ContainsAny(fileName, "/\\") {
+		return "", archiveEndpoint{}, false
+	}
+	return path.Join(endpoint.dir, fileName+".json"), endpoint, true
+}
+
+// response returns the status code and body the feeder gateway would respond with to a
+// request for reqURL, using the files of the archive.
+func (a *archive) response(reqURL *url.URL) (int, []byte) {
+	name, endpoint, ok := archiveFileName(reqURL)
+	if !ok {
+		return http.StatusBadRequest, nil
+	}
+
+	content, err := a.readFile(name)
+	if err != nil && endpoint.blockAddressed {
+		// the latest block and blocks addressed by hash are kept under their number
+		if number, found := a.blockNumber(reqURL.Query()); found {
+			content, err = a.readFile(path.Join(endpoint.dir, strconv.FormatUint(number, 10)+".json"))
+		}
+	}
+	if err != nil {
+		if endpoint.blockAddressed {
+			body, err := json.Marshal(Error{
+				Code:    BlockNotFound,
+				Message: "Block " + strings.TrimSuffix(path.Base(name), ".json") + " was not found.",
+			})
+			if err != nil {
+				return http.StatusInternalServerError, nil
+			}
+			return http.StatusBadRequest, body
+		}
+		return http.StatusNotFound, nil
+	}
+	return http.StatusOK, content
+}
+
+// blockNumber returns the number of the block that query refers to by an alias, i.e. the latest
+// block of the archive or the block with the given hash. Hashes are looked up by scanning the
+// blocks of the archive.
+func (a *archive) blockNumber(query url.Values) (uint64, bool) {
+	var blockHash *felt.Felt
+	if value := query.Get("blockHash"); value != "" {
+		hash, err := new(felt.Felt).SetString(value)
+		if err != nil {
+			return 0, false
+		}
+		blockHash = hash
+	} else if query.Get("blockNumber") != "latest" {
+		return 0, false
+	}
+
+	names, err := a.listFiles(archiveEndpoints["get_block"].dir)
+	if err != nil {
+		return 0, false
+	}
+
+	var latest uint64
+	var found bool
+	for _, name := range names {
+		if path.Ext(name) != ".json" {
+			continue
+		}
+		number, err := strconv.ParseUint(strings.TrimSuffix(name, ".json"), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		if blockHash == nil {
+			if !found || number > latest {
+				latest, found = number, true
+			}
+			continue
+		}
+
+		content, err := a.readFile(path.Join(archiveEndpoints["get_block"].dir, name))
+		if err != nil {
+			continue
+		}
+		var block struct {
+			Hash *felt.Felt `json:"block_hash"`
+		}
+		if err = json.Unmarshal(content, &block); err == nil && block.Hash != nil && block.Hash.Equal(blockHash) {
+			return number, true
+		}
+	}
+	return latest, found
+}
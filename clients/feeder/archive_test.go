@@ -0,0 +1,107 @@
+package feeder_test
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NethermindEth/juno/clients/feeder"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTarball archives the given files of the mainnet testdata under a top-level mainnet/ directory.
+func writeTarball(t *testing.T, tarballPath string, files ...string) {
+	t.Helper()
+
+	f, err := os.Create(tarballPath)
+	require.NoError(t, err)
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	for _, name := range files {
+		content, err := os.ReadFile(filepath.Join("testdata", "mainnet", name))
+		require.NoError(t, err)
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:     "mainnet/" + filepath.ToSlash(name),
+			Mode:     0o644,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		}))
+		_, err = tw.Write(content)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	require.NoError(t, f.Close())
+}
+
+func TestArchiveClient(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	ctx := context.Background()
+
+	expectedBlock, err := client.Block(ctx, 0)
+	require.NoError(t, err)
+	expectedUpdate, err := client.StateUpdate(ctx, 0)
+	require.NoError(t, err)
+
+	expectedLatest, err := client.Block(ctx, 1)
+	require.NoError(t, err)
+
+	tarball := filepath.Join(t.TempDir(), "mainnet.tar.gz")
+	writeTarball(t, tarball, filepath.Join("block", "0.json"), filepath.Join("block", "1.json"),
+		filepath.Join("state_update", "0.json"))
+
+	archives := map[string]string{
+		"directory": filepath.Join("testdata", "mainnet"),
+		"tarball":   tarball,
+	}
+	for name, archivePath := range archives {
+		archivePath := archivePath
+		t.Run(name, func(t *testing.T) {
+			archive, err := feeder.NewArchiveClient(archivePath)
+			require.NoError(t, err)
+
+			block, err := archive.Block(ctx, 0)
+			require.NoError(t, err)
+			assert.Equal(t, expectedBlock, block)
+
+			update, err := archive.StateUpdate(ctx, 0)
+			require.NoError(t, err)
+			assert.Equal(t, expectedUpdate, update)
+
+			_, err = archive.Block(ctx, 1000000)
+			var feederErr *feeder.Error
+			require.ErrorAs(t, err, &feederErr)
+			assert.Equal(t, feeder.BlockNotFound, feederErr.Code)
+
+			block, err = archive.BlockByHash(ctx, expectedBlock.Hash)
+			require.NoError(t, err)
+			assert.Equal(t, expectedBlock, block)
+
+			_, err = archive.BlockByHash(ctx, utils.HexToFelt(t, "0xffff"))
+			require.ErrorAs(t, err, &feederErr)
+			assert.Equal(t, feeder.BlockNotFound, feederErr.Code)
+		})
+	}
+
+	t.Run("latest block of a tarball", func(t *testing.T) {
+		archive, err := feeder.NewArchiveClient(tarball)
+		require.NoError(t, err)
+
+		block, err := archive.LatestBlock(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, expectedLatest, block)
+	})
+
+	t.Run("archive does not exist", func(t *testing.T) {
+		_, err := feeder.NewArchiveClient(filepath.Join(t.TempDir(), "missing"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
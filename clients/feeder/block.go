@@ -15,4 +15,38 @@ type Block struct {
 	Version          string                `json:"starknet_version"`
 	Receipts         []*TransactionReceipt `json:"transaction_receipts"`
 	SequencerAddress *felt.Felt            `json:"sequencer_address"`
+	// The state diff commitment and length are only reported by newer versions of the feeder
+	StateDiffCommitment *felt.Felt `json:"state_diff_commitment"`
+	StateDiffLength     uint64     `json:"state_diff_length"`
+	// The gas prices are reported as eth_l1_gas_price and strk_l1_gas_price by 0.13.0 blocks,
+	// and as l1_gas_price since 0.13.1
+	GasPriceETH    *felt.Felt `json:"eth_l1_gas_price"`
+	GasPriceSTRK   *felt.Felt `json:"strk_l1_gas_price"`
+	L1GasPrice     *GasPrice  `json:"l1_gas_price"`
+	L1DataGasPrice *GasPrice  `json:"l1_data_gas_price"`
+	L1DAMode       string     `json:"l1_da_mode"`
+}
+
+type GasPrice struct {
+	PriceInWei *felt.Felt `json:"price_in_wei"`
+	PriceInFri *felt.Felt `json:"price_in_fri"`
+}
+
+// Signature object returned by the feeder in JSON format for "get_signature" endpoint
+type Signature struct {
+	BlockNumber    uint64         `json:"block_number"`
+	Signature      []*felt.Felt   `json:"signature"`
+	SignatureInput SignatureInput `json:"signature_input"`
+}
+
+// SignatureInput holds the values that the sequencer signs a block over.
+type SignatureInput struct {
+	BlockHash           *felt.Felt `json:"block_hash"`
+	StateDiffCommitment *felt.Felt `json:"state_diff_commitment"`
+}
+
+// ContractAddresses object returned by the feeder in JSON format for "get_contract_addresses" endpoint
+type ContractAddresses struct {
+	Starknet             string `json:"Starknet"`
+	GpsStatementVerifier string `json:"GpsStatementVerifier"`
 }
@@ -83,3 +83,45 @@ func (c *ClassDefinition) UnmarshalJSON(data []byte) error {
 	c.V0 = new(Cairo0Definition)
 	return json.Unmarshal(data, c.V0)
 }
+
+type CompiledEntryPoint struct {
+	Selector *felt.Felt `json:"selector"`
+	Builtins []string   `json:"builtins"`
+	Offset   uint64     `json:"offset"`
+}
+
+type CompiledClass struct {
+	EntryPoints struct {
+		Constructor []CompiledEntryPoint `json:"CONSTRUCTOR"`
+		External    []CompiledEntryPoint `json:"EXTERNAL"`
+		L1Handler   []CompiledEntryPoint `json:"L1_HANDLER"`
+	} `json:"entry_points_by_type"`
+	Prime           string          `json:"prime"`
+	CompilerVersion string          `json:"compiler_version"`
+	Bytecode        []*felt.Felt    `json:"bytecode"`
+	Hints           json.RawMessage `json:"hints"`
+	PythonicHints   json.RawMessage `json:"pythonic_hints"`
+	// BytecodeSegmentLengths is nil for CASM compiled before bytecode segments were introduced.
+	BytecodeSegmentLengths *SegmentLengths `json:"bytecode_segment_lengths,omitempty"`
+}
+
+// SegmentLengths is either a number, the length of a bytecode segment, or a list of the segments
+// the segment is made of.
+type SegmentLengths struct {
+	Length   uint64
+	Children []SegmentLengths
+}
+
+func (s *SegmentLengths) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &s.Length); err == nil {
+		return nil
+	}
+	return json.Unmarshal(data, &s.Children)
+}
+
+func (s SegmentLengths) MarshalJSON() ([]byte, error) {
+	if len(s.Children) == 0 {
+		return json.Marshal(s.Length)
+	}
+	return json.Marshal(s.Children)
+}
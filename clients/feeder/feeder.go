@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -20,6 +21,44 @@ import (
 
 type Backoff func(wait time.Duration) time.Duration
 
+type ErrorCode string
+
+const (
+	BlockNotFound   ErrorCode = "StarknetErrorCode.BLOCK_NOT_FOUND"
+	UndeclaredClass ErrorCode = "StarknetErrorCode.UNDECLARED_CLASS"
+)
+
+// Error is an error response of the feeder gateway. Such responses describe the request
+// itself, e.g. a block that doesn't exist yet, so they are not retried.
+type Error struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// DecodeError is returned when a response of the feeder gateway cannot be decoded.
+type DecodeError struct {
+	Err error
+}
+
+func (e DecodeError) Error() string {
+	return "cannot decode feeder response: " + e.Err.Error()
+}
+
+func (e DecodeError) Unwrap() error {
+	return e.Err
+}
+
+func decode(body []byte, v any) error {
+	if err := json.Unmarshal(body, v); err != nil {
+		return DecodeError{Err: err}
+	}
+	return nil
+}
+
 type Client struct {
 	url        string
 	client     *http.Client
@@ -72,55 +111,17 @@ func NewTestClient(network utils.Network) (*Client, closeTestClient) {
 }
 
 func newTestServer(network utils.Network) *httptest.Server {
-	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		queryMap, err := url.ParseQuery(r.URL.RawQuery)
-		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-
-		wd, err := os.Getwd()
-		if err != nil {
-			panic(err)
-		}
-
-		base := wd[:strings.LastIndex(wd, "juno")+4]
-		queryArg := ""
-		dir := ""
-
-		switch {
-		case strings.HasSuffix(r.URL.Path, "get_block"):
-			dir = "block"
-			queryArg = "blockNumber"
-		case strings.HasSuffix(r.URL.Path, "get_state_update"):
-			dir = "state_update"
-			queryArg = "blockNumber"
-		case strings.HasSuffix(r.URL.Path, "get_transaction"):
-			dir = "transaction"
-			queryArg = "transactionHash"
-		case strings.HasSuffix(r.URL.Path, "get_class_by_hash"):
-			dir = "class"
-			queryArg = "classHash"
-		}
-
-		fileName, found := queryMap[queryArg]
-		if !found {
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-
-		path := filepath.Join(base, "clients", "feeder", "testdata", network.String(), dir, fileName[0]+".json")
-		read, err := os.ReadFile(path)
-		if err != nil {
-			w.WriteHeader(http.StatusNotFound)
-			return
-		}
+	wd, err := os.Getwd()
+	if err != nil {
+		panic(err)
+	}
 
-		_, err = w.Write(read)
-		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-		}
-	}))
+	base := wd[:strings.LastIndex(wd, "juno")+4]
+	replay, err := NewReplayServer(filepath.Join(base, "clients", "feeder", "testdata", network.String()))
+	if err != nil {
+		panic(err)
+	}
+	return httptest.NewServer(replay)
 }
 
 func NewClient(clientURL string) *Client {
@@ -183,8 +184,12 @@ func (c *Client) get(ctx context.Context, queryURL string) ([]byte, error) {
 					return resBytes, nil
 				}
 
-				if res.StatusCode != http.StatusOK {
-					err = errors.New(res.Status)
+				err = errors.New(res.Status)
+				if res.StatusCode >= 400 && res.StatusCode < 500 && res.StatusCode != http.StatusTooManyRequests {
+					if feederErr := readError(res.Body); feederErr != nil {
+						res.Body.Close()
+						return nil, feederErr
+					}
 				}
 				res.Body.Close()
 			}
@@ -202,70 +207,152 @@ func (c *Client) get(ctx context.Context, queryURL string) ([]byte, error) {
 	return nil, err
 }
 
-func (c *Client) StateUpdate(ctx context.Context, blockNumber uint64) (*StateUpdate, error) {
-	queryURL := c.buildQueryString("get_state_update", map[string]string{
-		"blockNumber": strconv.FormatUint(blockNumber, 10),
-	})
+// readError decodes the error response of the feeder gateway from body. It returns nil if
+// body isn't such a response.
+func readError(body io.Reader) *Error {
+	feederErr := new(Error)
+	if err := json.NewDecoder(body).Decode(feederErr); err != nil || feederErr.Code == "" {
+		return nil
+	}
+	return feederErr
+}
+
+// BlockID identifies the block that a query refers to, see [BlockNumber], [BlockHash],
+// [Latest] and [Pending].
+type BlockID struct {
+	arg   string
+	value string
+}
+
+var (
+	// Latest is the most recent accepted block.
+	Latest = BlockID{arg: "blockNumber", value: "latest"}
+	// Pending is the block that is currently being built by the sequencer.
+	Pending = BlockID{arg: "blockNumber", value: "pending"}
+)
+
+// BlockNumber identifies a block by its number.
+func BlockNumber(number uint64) BlockID {
+	return BlockID{arg: "blockNumber", value: strconv.FormatUint(number, 10)}
+}
+
+// BlockHash identifies a block by its hash.
+func BlockHash(hash *felt.Felt) BlockID {
+	return BlockID{arg: "blockHash", value: hash.String()}
+}
 
-	body, err := c.get(ctx, queryURL)
+// query requests endpoint with the given arguments and decodes the response into a T.
+func query[T any](ctx context.Context, c *Client, endpoint string, args map[string]string) (*T, error) {
+	body, err := c.get(ctx, c.buildQueryString(endpoint, args))
 	if err != nil {
 		return nil, err
 	}
 
-	update := new(StateUpdate)
-	if err = json.Unmarshal(body, update); err != nil {
+	response := new(T)
+	if err = decode(body, response); err != nil {
 		return nil, err
 	}
-	return update, nil
+	return response, nil
+}
+
+func (c *Client) StateUpdate(ctx context.Context, blockNumber uint64) (*StateUpdate, error) {
+	return query[StateUpdate](ctx, c, "get_state_update", map[string]string{
+		"blockNumber": strconv.FormatUint(blockNumber, 10),
+	})
 }
 
 func (c *Client) Transaction(ctx context.Context, transactionHash *felt.Felt) (*TransactionStatus, error) {
-	queryURL := c.buildQueryString("get_transaction", map[string]string{
+	return query[TransactionStatus](ctx, c, "get_transaction", map[string]string{
 		"transactionHash": transactionHash.String(),
 	})
+}
 
-	body, err := c.get(ctx, queryURL)
-	if err != nil {
-		return nil, err
-	}
+// TransactionReceipt returns the receipt of the transaction with the given hash, along with
+// the block that includes it.
+func (c *Client) TransactionReceipt(ctx context.Context, transactionHash *felt.Felt) (*TransactionReceiptResponse, error) {
+	return query[TransactionReceiptResponse](ctx, c, "get_transaction_receipt", map[string]string{
+		"transactionHash": transactionHash.String(),
+	})
+}
 
-	txStatus := new(TransactionStatus)
-	if err = json.Unmarshal(body, txStatus); err != nil {
-		return nil, err
-	}
-	return txStatus, nil
+// TransactionStatus returns the status of the transaction with the given hash.
+func (c *Client) TransactionStatus(ctx context.Context, transactionHash *felt.Felt) (*TransactionStatusResponse, error) {
+	return query[TransactionStatusResponse](ctx, c, "get_transaction_status", map[string]string{
+		"transactionHash": transactionHash.String(),
+	})
 }
 
 func (c *Client) Block(ctx context.Context, blockNumber uint64) (*Block, error) {
-	queryURL := c.buildQueryString("get_block", map[string]string{
-		"blockNumber": strconv.FormatUint(blockNumber, 10),
-	})
+	return c.block(ctx, BlockNumber(blockNumber))
+}
 
-	body, err := c.get(ctx, queryURL)
-	if err != nil {
-		return nil, err
-	}
+func (c *Client) BlockByHash(ctx context.Context, blockHash *felt.Felt) (*Block, error) {
+	return c.block(ctx, BlockHash(blockHash))
+}
 
-	block := new(Block)
-	if err = json.Unmarshal(body, block); err != nil {
-		return nil, err
-	}
-	return block, nil
+func (c *Client) LatestBlock(ctx context.Context) (*Block, error) {
+	return c.block(ctx, Latest)
+}
+
+// PendingBlock returns the block that is currently being built. Its hash and state root are
+// not known yet and left nil, and its number is left zero.
+func (c *Client) PendingBlock(ctx context.Context) (*Block, error) {
+	return c.block(ctx, Pending)
+}
+
+func (c *Client) block(ctx context.Context, block BlockID) (*Block, error) {
+	return query[Block](ctx, c, "get_block", map[string]string{block.arg: block.value})
+}
+
+// Signature returns the signature of the sequencer on a block.
+func (c *Client) Signature(ctx context.Context, block BlockID) (*Signature, error) {
+	return query[Signature](ctx, c, "get_signature", map[string]string{block.arg: block.value})
+}
+
+// PublicKey returns the public key that the sequencer signs blocks with, see [Client.Signature].
+func (c *Client) PublicKey(ctx context.Context) (*felt.Felt, error) {
+	return query[felt.Felt](ctx, c, "get_public_key", nil)
+}
+
+// ContractAddresses returns the addresses of the Starknet contracts on L1.
+func (c *Client) ContractAddresses(ctx context.Context) (*ContractAddresses, error) {
+	return query[ContractAddresses](ctx, c, "get_contract_addresses", nil)
 }
 
 func (c *Client) ClassDefinition(ctx context.Context, classHash *felt.Felt) (*ClassDefinition, error) {
-	queryURL := c.buildQueryString("get_class_by_hash", map[string]string{
+	return query[ClassDefinition](ctx, c, "get_class_by_hash", map[string]string{
 		"classHash": classHash.String(),
 	})
+}
 
-	body, err := c.get(ctx, queryURL)
-	if err != nil {
-		return nil, err
-	}
+func (c *Client) CompiledClassDefinition(ctx context.Context, classHash *felt.Felt) (*CompiledClass, error) {
+	return query[CompiledClass](ctx, c, "get_compiled_class_by_class_hash", map[string]string{
+		"classHash": classHash.String(),
+	})
+}
 
-	class := new(ClassDefinition)
-	if err = json.Unmarshal(body, class); err != nil {
-		return nil, err
-	}
-	return class, nil
+// ClassHashAt returns the hash of the class of the contract at the given address, as of the given block.
+func (c *Client) ClassHashAt(ctx context.Context, contractAddress *felt.Felt, block BlockID) (*felt.Felt, error) {
+	return query[felt.Felt](ctx, c, "get_class_hash_at", map[string]string{
+		"contractAddress": contractAddress.String(),
+		block.arg:         block.value,
+	})
+}
+
+// StorageAt returns the value at the given key of the storage of a contract, as of the given block.
+func (c *Client) StorageAt(ctx context.Context, contractAddress, key *felt.Felt, block BlockID) (*felt.Felt, error) {
+	return query[felt.Felt](ctx, c, "get_storage_at", map[string]string{
+		"contractAddress": contractAddress.String(),
+		// the feeder gateway expects storage keys in decimal
+		"key":     key.Text(10),
+		block.arg: block.value,
+	})
+}
+
+// Nonce returns the nonce of the contract at the given address, as of the given block.
+func (c *Client) Nonce(ctx context.Context, contractAddress *felt.Felt, block BlockID) (*felt.Felt, error) {
+	return query[felt.Felt](ctx, c, "get_nonce", map[string]string{
+		"contractAddress": contractAddress.String(),
+		block.arg:         block.value,
+	})
 }
@@ -2,6 +2,7 @@ package feeder_test
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -376,6 +377,39 @@ func TestHttpError(t *testing.T) {
 	}
 }
 
+func TestFeederError(t *testing.T) {
+	try := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		try++
+		if r.URL.Query().Has("classHash") {
+			_, err := w.Write([]byte(`{"not a class"`))
+			require.NoError(t, err)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte(`{"code": "StarknetErrorCode.BLOCK_NOT_FOUND", "message": "Block number 1000000 was not found."}`))
+		require.NoError(t, err)
+	}))
+	t.Cleanup(srv.Close)
+	client := feeder.NewClient(srv.URL).WithBackoff(feeder.NopBackoff).WithMaxRetries(2)
+
+	t.Run("error responses are not retried", func(t *testing.T) {
+		try = 0
+		_, err := client.Block(context.Background(), 1000000)
+
+		var feederErr *feeder.Error
+		require.ErrorAs(t, err, &feederErr)
+		assert.Equal(t, feeder.BlockNotFound, feederErr.Code)
+		assert.EqualError(t, err, "StarknetErrorCode.BLOCK_NOT_FOUND: Block number 1000000 was not found.")
+		assert.Equal(t, 1, try)
+	})
+
+	t.Run("malformed response", func(t *testing.T) {
+		_, err := client.ClassDefinition(context.Background(), new(felt.Felt))
+		assert.ErrorAs(t, err, new(feeder.DecodeError))
+	})
+}
+
 func TestBackoffFailure(t *testing.T) {
 	maxRetries := 5
 	try := 0
@@ -391,3 +425,236 @@ func TestBackoffFailure(t *testing.T) {
 	assert.EqualError(t, err, "500 Internal Server Error")
 	assert.Equal(t, maxRetries, try-1) // we have retried `maxRetries` times
 }
+
+// compiledClassJSON is a made up CASM, trimmed down to the fields Juno reads, with bytecode segments.
+const compiledClassJSON = `{
+	"prime": "0x800000000000011000000000000000000000000000000000000000000000001",
+	"compiler_version": "2.6.0",
+	"bytecode": ["0xa0680017fff8000", "0x7", "0x482680017ffa8000", "0x400280007ff97fff", "0x1", "0x208b7fff7fff7ffe"],
+	"bytecode_segment_lengths": [2, [1, 2], 1],
+	"hints": [[0, [{"TestLessThanOrEqual": {}}]]],
+	"pythonic_hints": [[0, ["memory[ap + 0] = 2620 <= memory[fp + -6]"]]],
+	"entry_points_by_type": {
+		"EXTERNAL": [{"selector": "0x22ff5f21f0b81b113e63f7db6da94fedef11b2119b4088b89664fb9a3cb658", "offset": 0,
+			"builtins": ["range_check"]}],
+		"L1_HANDLER": [],
+		"CONSTRUCTOR": []
+	}
+}`
+
+func TestCompiledClassDefinition(t *testing.T) {
+	classHash := utils.HexToFelt(t, "0x4e70b19333ae94bd958625f7b61ce9eec631653597e68645e13780061b2136c")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/get_compiled_class_by_class_hash" || r.URL.Query().Get("classHash") != classHash.String() {
+			w.WriteHeader(http.StatusBadRequest)
+			_, err := w.Write([]byte(`{"code": "StarknetErrorCode.UNDECLARED_CLASS", "message": "Class is not declared."}`))
+			require.NoError(t, err)
+			return
+		}
+		_, err := w.Write([]byte(compiledClassJSON))
+		require.NoError(t, err)
+	}))
+	t.Cleanup(srv.Close)
+	client := feeder.NewClient(srv.URL).WithBackoff(feeder.NopBackoff).WithMaxRetries(0)
+
+	t.Run("Test normal case", func(t *testing.T) {
+		compiledClass, err := client.CompiledClassDefinition(context.Background(), classHash)
+		require.NoError(t, err)
+
+		assert.Equal(t, "0x800000000000011000000000000000000000000000000000000000000000001", compiledClass.Prime)
+		assert.Equal(t, "2.6.0", compiledClass.CompilerVersion)
+		assert.Equal(t, 6, len(compiledClass.Bytecode))
+		assert.Equal(t, &feeder.SegmentLengths{Children: []feeder.SegmentLengths{
+			{Length: 2},
+			{Children: []feeder.SegmentLengths{{Length: 1}, {Length: 2}}},
+			{Length: 1},
+		}}, compiledClass.BytecodeSegmentLengths)
+		assert.NotEmpty(t, compiledClass.Hints)
+		assert.NotEmpty(t, compiledClass.PythonicHints)
+		assert.Equal(t, 0, len(compiledClass.EntryPoints.Constructor))
+		assert.Equal(t, 0, len(compiledClass.EntryPoints.L1Handler))
+		require.Equal(t, 1, len(compiledClass.EntryPoints.External))
+		assert.Equal(t, []string{"range_check"}, compiledClass.EntryPoints.External[0].Builtins)
+	})
+	t.Run("Test classHash not find", func(t *testing.T) {
+		classHash := utils.HexToFelt(t, "0x000")
+		compiledClass, err := client.CompiledClassDefinition(context.Background(), classHash)
+		assert.Nil(t, compiledClass)
+		assert.Error(t, err)
+	})
+}
+
+func TestSegmentLengthsJSON(t *testing.T) {
+	for _, input := range []string{`3`, `[2,[1,2],1]`, `[[[4]],5]`} {
+		var lengths feeder.SegmentLengths
+		require.NoError(t, json.Unmarshal([]byte(input), &lengths))
+		output, err := json.Marshal(lengths)
+		require.NoError(t, err)
+		assert.JSONEq(t, input, string(output))
+	}
+
+	var lengths feeder.SegmentLengths
+	assert.Error(t, json.Unmarshal([]byte(`"3"`), &lengths))
+}
+
+func TestBlockByID(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	ctx := context.Background()
+
+	t.Run("by hash", func(t *testing.T) {
+		expected, err := client.Block(ctx, 0)
+		require.NoError(t, err)
+
+		block, err := client.BlockByHash(ctx, expected.Hash)
+		require.NoError(t, err)
+		assert.Equal(t, expected, block)
+
+		_, err = client.BlockByHash(ctx, utils.HexToFelt(t, "0xffff"))
+		var feederErr *feeder.Error
+		require.ErrorAs(t, err, &feederErr)
+		assert.Equal(t, feeder.BlockNotFound, feederErr.Code)
+	})
+	t.Run("latest", func(t *testing.T) {
+		expected, err := client.Block(ctx, 19199)
+		require.NoError(t, err)
+
+		block, err := client.LatestBlock(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, expected, block)
+	})
+	t.Run("pending", func(t *testing.T) {
+		parentHash := utils.HexToFelt(t, "0x157b9e756f15e002e63580dddb8c8e342b9336c6d69a8cd6dc8eb8a75644040")
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/get_block" || r.URL.Query().Get("blockNumber") != "pending" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			// a pending block, trimmed down to the fields that differ from accepted blocks
+			_, err := w.Write([]byte(`{"parent_block_hash": "` + parentHash.String() + `", "status": "PENDING",
+				"transactions": [], "transaction_receipts": [], "timestamp": 1671087773}`))
+			require.NoError(t, err)
+		}))
+		t.Cleanup(srv.Close)
+		client := feeder.NewClient(srv.URL).WithBackoff(feeder.NopBackoff).WithMaxRetries(0)
+
+		block, err := client.PendingBlock(ctx)
+		require.NoError(t, err)
+		assert.Nil(t, block.Hash)
+		assert.Nil(t, block.StateRoot)
+		assert.Equal(t, uint64(0), block.Number)
+		assert.Equal(t, "PENDING", block.Status)
+		assert.Equal(t, parentHash, block.ParentHash)
+	})
+}
+
+func TestTransactionReceipt(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	ctx := context.Background()
+	transactionHash := utils.HexToFelt(t, "0x723b57825c177d66fdc1ee1b7d22bd937503cd66808edf87294e88ee26601b6")
+
+	t.Run("receipt", func(t *testing.T) {
+		block, err := client.Block(ctx, 2)
+		require.NoError(t, err)
+
+		receipt, err := client.TransactionReceipt(ctx, transactionHash)
+		require.NoError(t, err)
+		assert.Equal(t, "ACCEPTED_ON_L1", receipt.Status)
+		assert.Equal(t, block.Hash, receipt.BlockHash)
+		assert.Equal(t, uint64(2), receipt.BlockNumber)
+		assert.Equal(t, *block.Receipts[0], receipt.TransactionReceipt)
+
+		_, err = client.TransactionReceipt(ctx, utils.HexToFelt(t, "0xffff"))
+		assert.Error(t, err)
+	})
+	t.Run("status", func(t *testing.T) {
+		status, err := client.TransactionStatus(ctx, transactionHash)
+		require.NoError(t, err)
+		assert.Equal(t, "ACCEPTED_ON_L1", status.Status)
+		assert.Equal(t, utils.HexToFelt(t, "0x4e1f77f39545afe866ac151ac908bd1a347a2a8a7d58bef1276db4f06fdf2f6"), status.BlockHash)
+		assert.Nil(t, status.FailureReason)
+	})
+}
+
+func TestSignature(t *testing.T) {
+	// the values are made up, only the format of the responses is the gateway's
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body string
+		switch {
+		case r.URL.Path == "/get_signature" && r.URL.Query().Get("blockNumber") == "1":
+			body = `{"block_number": 1, "signature": ["0x1", "0x2"],
+				"signature_input": {"block_hash": "0x3", "state_diff_commitment": "0x4"}}`
+		case r.URL.Path == "/get_public_key":
+			body = `"0x5"`
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			body = `{"code": "StarknetErrorCode.BLOCK_NOT_FOUND", "message": "Block number 1000000 was not found."}`
+		}
+		_, err := w.Write([]byte(body))
+		require.NoError(t, err)
+	}))
+	t.Cleanup(srv.Close)
+	client := feeder.NewClient(srv.URL).WithBackoff(feeder.NopBackoff).WithMaxRetries(0)
+	ctx := context.Background()
+
+	signature, err := client.Signature(ctx, feeder.BlockNumber(1))
+	require.NoError(t, err)
+	assert.Equal(t, &feeder.Signature{
+		BlockNumber: 1,
+		Signature:   []*felt.Felt{utils.HexToFelt(t, "0x1"), utils.HexToFelt(t, "0x2")},
+		SignatureInput: feeder.SignatureInput{
+			BlockHash:           utils.HexToFelt(t, "0x3"),
+			StateDiffCommitment: utils.HexToFelt(t, "0x4"),
+		},
+	}, signature)
+
+	_, err = client.Signature(ctx, feeder.BlockNumber(1000000))
+	var feederErr *feeder.Error
+	require.ErrorAs(t, err, &feederErr)
+	assert.Equal(t, feeder.BlockNotFound, feederErr.Code)
+
+	publicKey, err := client.PublicKey(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, utils.HexToFelt(t, "0x5"), publicKey)
+}
+
+func TestContractAddresses(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+
+	addresses, err := client.ContractAddresses(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &feeder.ContractAddresses{
+		Starknet:             "0xc662c410C0ECf747543f5bA90660f6ABeBD9C8c4",
+		GpsStatementVerifier: "0x47312450B3Ac8b5b8e247a6bB6d523e7605bDb60",
+	}, addresses)
+}
+
+func TestContractState(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	ctx := context.Background()
+	address := utils.HexToFelt(t, "0x5790719f16afe1450b67a92461db7d0e36298d6a5f8bab4f7fd282050e02f4f")
+
+	t.Run("class hash", func(t *testing.T) {
+		classHash, err := client.ClassHashAt(ctx, address, feeder.BlockNumber(2))
+		require.NoError(t, err)
+		assert.Equal(t, utils.HexToFelt(t, "0x10455c752b86932ce552f2b0fe81a880746649b9aee7e0d842bf3f52378f9f8"), classHash)
+	})
+	t.Run("storage", func(t *testing.T) {
+		key := utils.HexToFelt(t, "0x772c29fae85f8321bb38c9c3f6edb0957379abedc75c17f32bcef4e9657911a")
+		value, err := client.StorageAt(ctx, address, key, feeder.BlockNumber(2))
+		require.NoError(t, err)
+		assert.Equal(t, utils.HexToFelt(t, "0x6d4ca0f72b553f5338a95625782a939a49b98f82f449c20f49b42ec60ed891c"), value)
+	})
+	t.Run("nonce", func(t *testing.T) {
+		nonce, err := client.Nonce(ctx, address, feeder.BlockNumber(2))
+		require.NoError(t, err)
+		assert.True(t, nonce.IsZero())
+	})
+	t.Run("unknown contract", func(t *testing.T) {
+		_, err := client.Nonce(ctx, utils.HexToFelt(t, "0xffff"), feeder.BlockNumber(2))
+		assert.Error(t, err)
+	})
+}
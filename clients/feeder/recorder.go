@@ -0,0 +1,74 @@
+package feeder
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// WithRecorder makes the client store every successful response in dir, laid out like the
+// feeder testdata of a network. The recorded responses can be served by a [ReplayServer] or
+// read with [NewArchiveClient].
+func (c *Client) WithRecorder(dir string) *Client {
+	c.client = &http.Client{Transport: &recordingTransport{next: c.transport(), dir: dir}}
+	return c
+}
+
+func (c *Client) transport() http.RoundTripper {
+	if c.client.Transport != nil {
+		return c.client.Transport
+	}
+	return http.DefaultTransport
+}
+
+// NewRecordingProxy returns a handler that forwards feeder gateway requests to upstream, e.g.
+// https://alpha-mainnet.starknet.io, and stores every successful response in dir the same
+// way [Client.WithRecorder] does. It can be used to record the traffic of any feeder client.
+func NewRecordingProxy(upstream *url.URL, dir string) http.Handler {
+	proxy := httputil.NewSingleHostReverseProxy(upstream)
+	director := proxy.Director
+	proxy.Director = func(req *http.Request) {
+		director(req)
+		req.Host = upstream.Host
+	}
+	proxy.Transport = &recordingTransport{next: http.DefaultTransport, dir: dir}
+	return proxy
+}
+
+// recordingTransport stores the successful responses of the wrapped RoundTripper in dir.
+type recordingTransport struct {
+	next http.RoundTripper
+	dir  string
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.next.RoundTrip(req)
+	if err != nil || res.StatusCode != http.StatusOK {
+		return res, err
+	}
+
+	name, _, ok := archiveFileName(req.URL)
+	if !ok {
+		return res, nil
+	}
+
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	path := filepath.Join(t.dir, filepath.FromSlash(name))
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	if err = os.WriteFile(path, body, 0o600); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
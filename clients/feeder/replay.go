@@ -0,0 +1,63 @@
+package feeder
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// ReplayServer serves a recorded corpus of feeder gateway responses, such as the ones stored by
+// [Client.WithRecorder], over HTTP. Blocks and state updates that are not in the corpus are
+// reported the way the feeder gateway reports blocks that have not been produced yet.
+//
+// Latency and failures can be injected to test how clients deal with a slow or unreliable
+// feeder gateway.
+type ReplayServer struct {
+	archive   *archive
+	latency   time.Duration
+	failEvery uint64
+	requests  uint64
+}
+
+// NewReplayServer returns a server for the corpus in the directory or tarball at corpusPath,
+// see [NewArchiveClient] for its layout.
+func NewReplayServer(corpusPath string) (*ReplayServer, error) {
+	a, err := openArchive(corpusPath)
+	if err != nil {
+		return nil, err
+	}
+	return &ReplayServer{archive: a}, nil
+}
+
+// WithLatency delays every response by d.
+func (s *ReplayServer) WithLatency(d time.Duration) *ReplayServer {
+	s.latency = d
+	return s
+}
+
+// WithFailEvery makes every n-th request fail with 503 Service Unavailable. Zero disables failures.
+func (s *ReplayServer) WithFailEvery(n uint64) *ReplayServer {
+	s.failEvery = n
+	return s
+}
+
+func (s *ReplayServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.latency > 0 {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(s.latency):
+		}
+	}
+
+	if request := atomic.AddUint64(&s.requests, 1); s.failEvery > 0 && request%s.failEvery == 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	status, body := s.archive.response(r.URL)
+	w.WriteHeader(status)
+	if _, err := w.Write(body); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,128 @@
+package feeder_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NethermindEth/juno/clients/feeder"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	ctx := context.Background()
+	classHash := utils.HexToFelt(t, "0x1efa8f84fd4dff9e2902ec88717cf0dafc8c188f80c3450615944a469428f7f")
+
+	corpus := t.TempDir()
+	recorder, recorderCloseFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(recorderCloseFn)
+	recorder.WithRecorder(corpus)
+
+	block, err := recorder.Block(ctx, 0)
+	require.NoError(t, err)
+	update, err := recorder.StateUpdate(ctx, 0)
+	require.NoError(t, err)
+	class, err := recorder.ClassDefinition(ctx, classHash)
+	require.NoError(t, err)
+
+	t.Run("only successful responses are recorded", func(t *testing.T) {
+		_, err := recorder.Block(ctx, 1000000)
+		require.Error(t, err)
+
+		_, err = os.Stat(filepath.Join(corpus, "block", "1000000.json"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("recorded responses match the original ones", func(t *testing.T) {
+		expected, err := client.Block(ctx, 0)
+		require.NoError(t, err)
+		assert.Equal(t, expected, block)
+	})
+
+	replay, err := feeder.NewReplayServer(corpus)
+	require.NoError(t, err)
+	srv := httptest.NewServer(replay)
+	t.Cleanup(srv.Close)
+	replayClient := feeder.NewClient(srv.URL).WithBackoff(feeder.NopBackoff).WithMaxRetries(0)
+
+	t.Run("replay", func(t *testing.T) {
+		replayedBlock, err := replayClient.Block(ctx, 0)
+		require.NoError(t, err)
+		assert.Equal(t, block, replayedBlock)
+
+		replayedUpdate, err := replayClient.StateUpdate(ctx, 0)
+		require.NoError(t, err)
+		assert.Equal(t, update, replayedUpdate)
+
+		replayedClass, err := replayClient.ClassDefinition(ctx, classHash)
+		require.NoError(t, err)
+		assert.Equal(t, class, replayedClass)
+
+		_, err = replayClient.Block(ctx, 1)
+		var feederErr *feeder.Error
+		require.ErrorAs(t, err, &feederErr)
+		assert.Equal(t, feeder.BlockNotFound, feederErr.Code)
+
+		_, err = replayClient.ClassDefinition(ctx, new(felt.Felt))
+		assert.EqualError(t, err, "404 Not Found")
+	})
+
+	t.Run("failure injection", func(t *testing.T) {
+		replay.WithFailEvery(2)
+		t.Cleanup(func() { replay.WithFailEvery(0) })
+
+		var failures int
+		for i := 0; i < 4; i++ {
+			if _, err := replayClient.Block(ctx, 0); err != nil {
+				assert.EqualError(t, err, "503 Service Unavailable")
+				failures++
+			}
+		}
+		assert.Equal(t, 2, failures)
+
+		// retries go through
+		_, err := feeder.NewClient(srv.URL).WithBackoff(feeder.NopBackoff).WithMaxRetries(1).Block(ctx, 0)
+		assert.NoError(t, err)
+	})
+
+	t.Run("latency injection", func(t *testing.T) {
+		replay.WithLatency(50 * time.Millisecond)
+		t.Cleanup(func() { replay.WithLatency(0) })
+
+		start := time.Now()
+		_, err := replayClient.Block(ctx, 0)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	})
+}
+
+func TestRecordingProxy(t *testing.T) {
+	replay, err := feeder.NewReplayServer(filepath.Join("testdata", "mainnet"))
+	require.NoError(t, err)
+	upstream := httptest.NewServer(replay)
+	t.Cleanup(upstream.Close)
+	upstreamURL, err := url.Parse(upstream.URL)
+	require.NoError(t, err)
+
+	corpus := t.TempDir()
+	proxy := httptest.NewServer(feeder.NewRecordingProxy(upstreamURL, corpus))
+	t.Cleanup(proxy.Close)
+
+	_, err = feeder.NewClient(proxy.URL).WithMaxRetries(0).StateUpdate(context.Background(), 1)
+	require.NoError(t, err)
+
+	recorded, err := os.ReadFile(filepath.Join(corpus, "state_update", "1.json"))
+	require.NoError(t, err)
+	original, err := os.ReadFile(filepath.Join("testdata", "mainnet", "state_update", "1.json"))
+	require.NoError(t, err)
+	assert.Equal(t, original, recorded)
+}
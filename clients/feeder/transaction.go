@@ -20,6 +20,18 @@ type Transaction struct {
 	EntryPointSelector  *felt.Felt   `json:"entry_point_selector"`
 	Nonce               *felt.Felt   `json:"nonce"`
 	CompiledClassHash   *felt.Felt   `json:"compiled_class_hash"`
+	// Fields of version 3 transactions
+	ResourceBounds        map[string]ResourceBounds `json:"resource_bounds"`
+	Tip                   *felt.Felt                `json:"tip"`
+	PaymasterData         []*felt.Felt              `json:"paymaster_data"`
+	NonceDAMode           string                    `json:"nonce_data_availability_mode"`
+	FeeDAMode             string                    `json:"fee_data_availability_mode"`
+	AccountDeploymentData []*felt.Felt              `json:"account_deployment_data"`
+}
+
+type ResourceBounds struct {
+	MaxAmount       *felt.Felt `json:"max_amount"`
+	MaxPricePerUnit *felt.Felt `json:"max_price_per_unit"`
 }
 
 type TransactionStatus struct {
@@ -54,15 +66,24 @@ type ExecutionResources struct {
 	Steps                  uint64                 `json:"n_steps"`
 	BuiltinInstanceCounter BuiltinInstanceCounter `json:"builtin_instance_counter"`
 	MemoryHoles            uint64                 `json:"n_memory_holes"`
+	TotalGasConsumed       *GasConsumed           `json:"total_gas_consumed"`
+}
+
+type GasConsumed struct {
+	L1Gas     uint64 `json:"l1_gas"`
+	L1DataGas uint64 `json:"l1_data_gas"`
 }
 
 type BuiltinInstanceCounter struct {
-	Pedersen   uint64 `json:"pedersen_builtin"`
-	RangeCheck uint64 `json:"range_check_builtin"`
-	Bitwise    uint64 `json:"bitwise_builtin"`
-	Output     uint64 `json:"output_builtin"`
-	Ecsda      uint64 `json:"ecdsa_builtin"`
-	EcOp       uint64 `json:"ec_op_builtin"`
+	Pedersen     uint64 `json:"pedersen_builtin"`
+	RangeCheck   uint64 `json:"range_check_builtin"`
+	Bitwise      uint64 `json:"bitwise_builtin"`
+	Output       uint64 `json:"output_builtin"`
+	Ecsda        uint64 `json:"ecdsa_builtin"`
+	EcOp         uint64 `json:"ec_op_builtin"`
+	Keccak       uint64 `json:"keccak_builtin"`
+	Poseidon     uint64 `json:"poseidon_builtin"`
+	SegmentArena uint64 `json:"segment_arena_builtin"`
 }
 
 type TransactionReceipt struct {
@@ -73,4 +94,27 @@ type TransactionReceipt struct {
 	L2ToL1Message      []*L2ToL1Message    `json:"l2_to_l1_messages"`
 	TransactionHash    *felt.Felt          `json:"transaction_hash"`
 	TransactionIndex   uint64              `json:"transaction_index"`
+	ExecutionStatus    string              `json:"execution_status"`
+	RevertError        string              `json:"revert_error"`
+}
+
+// TransactionReceiptResponse object returned by the feeder in JSON format for "get_transaction_receipt" endpoint
+type TransactionReceiptResponse struct {
+	Status      string     `json:"status"`
+	BlockHash   *felt.Felt `json:"block_hash"`
+	BlockNumber uint64     `json:"block_number"`
+	TransactionReceipt
+}
+
+// TransactionStatusResponse object returned by the feeder in JSON format for "get_transaction_status" endpoint
+type TransactionStatusResponse struct {
+	Status        string                    `json:"tx_status"`
+	BlockHash     *felt.Felt                `json:"block_hash"`
+	FailureReason *TransactionFailureReason `json:"tx_failure_reason"`
+}
+
+// TransactionFailureReason explains why a transaction was rejected.
+type TransactionFailureReason struct {
+	Code         string `json:"code"`
+	ErrorMessage string `json:"error_message"`
 }
@@ -25,17 +25,35 @@ const greeting = `
 Juno is a Go implementation of a Starknet full node client created by Nethermind.`
 
 const (
-	configF   = "config"
-	logLevelF = "log-level"
-	rpcPortF  = "rpc-port"
-	dbPathF   = "db-path"
-	networkF  = "network"
-	pprofF    = "pprof"
-
-	defaultConfig  = ""
-	defaultRPCPort = uint16(6060)
-	defaultDBPath  = ""
-	defaultPprof   = false
+	configF          = "config"
+	logLevelF        = "log-level"
+	rpcPortF         = "rpc-port"
+	dbPathF          = "db-path"
+	networkF         = "network"
+	pprofF           = "pprof"
+	pruneF           = "prune-keep-blocks"
+	syncFetchersF    = "sync-fetchers"
+	syncVerifiersF   = "sync-verifiers"
+	syncMaxInFlightF = "sync-max-in-flight"
+	syncStopAtF      = "sync-stop-at"
+	syncSourceF      = "sync-source"
+	syncVerifySigsF  = "sync-verify-signatures"
+	syncAccSigsF     = "sync-verify-account-signatures"
+	addressIndexF    = "address-index"
+
+	defaultConfig          = ""
+	defaultRPCPort         = uint16(6060)
+	defaultDBPath          = ""
+	defaultPprof           = false
+	defaultPrune           = uint64(0)
+	defaultSyncFetchers    = uint(0)
+	defaultSyncVerifiers   = uint(0)
+	defaultSyncMaxInFlight = uint(0)
+	defaultSyncStopAt      = uint64(0)
+	defaultSyncSource      = ""
+	defaultSyncVerifySigs  = false
+	defaultSyncAccSigs     = false
+	defaultAddressIndex    = false
 
 	configFlagUsage   = "The yaml configuration file."
 	logLevelFlagUsage = "Options: debug, info, warn, error."
@@ -44,6 +62,20 @@ const (
 	dbPathUsage  = "Location of the database files."
 	networkUsage = "Options: mainnet, goerli, goerli2, integration."
 	pprofUsage   = "Enables the pprof server and listens on port 9080."
+	pruneUsage   = "Number of most recent blocks for which state updates are kept, older ones are pruned. " +
+		"0 keeps everything (archive mode)."
+	syncFetchersUsage    = "Number of blocks downloaded concurrently. 0 uses the number of CPUs."
+	syncVerifiersUsage   = "Number of blocks verified concurrently. 0 uses the number of CPUs."
+	syncMaxInFlightUsage = "Maximum number of blocks held in memory while syncing. 0 means no limit."
+	syncStopAtUsage      = "Height after which the node stops syncing, the RPC server keeps running. " +
+		"Syncs forever if not set."
+	syncSourceUsage = "Directory or tarball of feeder gateway responses to sync from instead of the network, " +
+		"laid out like clients/feeder/testdata/<network>."
+	syncVerifySigsUsage = "Verify the signature of the sequencer on every block against the sequencer public key."
+	syncAccSigsUsage    = "Verify the signatures of transactions sent from standard accounts against the public " +
+		"keys the accounts store. Mismatches are logged."
+	addressIndexUsage = "Index transactions by the addresses of their senders and contracts. " +
+		"Blocks stored while the index was disabled are indexed on startup."
 )
 
 var Version string
@@ -71,6 +103,15 @@ func main() {
 		return nil
 	})
 
+	cmd.AddCommand(NewVerifyStateCmd(config, func(cmd *cobra.Command, _ []string) error {
+		n, err := node.New(config)
+		if err != nil {
+			return err
+		}
+
+		return n.VerifyState(cmd.Context())
+	}))
+
 	if err := cmd.ExecuteContext(ctx); err != nil {
 		os.Exit(1)
 	}
@@ -95,24 +136,7 @@ func NewCmd(config *node.Config, run func(*cobra.Command, []string) error) *cobr
 
 	// PreRunE populates the configuration struct from the Cobra flags and Viper configuration.
 	// This is called in step 3 of the process described above.
-	junoCmd.PreRunE = func(cmd *cobra.Command, _ []string) error {
-		v := viper.New()
-		if cfgFile != "" {
-			v.SetConfigType("yaml")
-			v.SetConfigFile(cfgFile)
-			if err := v.ReadInConfig(); err != nil {
-				return err
-			}
-		}
-
-		if err := v.BindPFlags(cmd.Flags()); err != nil {
-			return nil
-		}
-
-		// TextUnmarshallerHookFunc allows us to unmarshal values that satisfy the
-		// encoding.TextUnmarshaller interface (see the LogLevel type for an example).
-		return v.Unmarshal(config, viper.DecodeHook(mapstructure.TextUnmarshallerHookFunc()))
-	}
+	junoCmd.PreRunE = loadConfig(config, &cfgFile)
 
 	// For testing purposes, these variables cannot be declared outside the function because Cobra
 	// may mutate their values.
@@ -125,6 +149,72 @@ func NewCmd(config *node.Config, run func(*cobra.Command, []string) error) *cobr
 	junoCmd.Flags().String(dbPathF, defaultDBPath, dbPathUsage)
 	junoCmd.Flags().Var(&defaultNetwork, networkF, networkUsage)
 	junoCmd.Flags().Bool(pprofF, defaultPprof, pprofUsage)
+	junoCmd.Flags().Uint64(pruneF, defaultPrune, pruneUsage)
+	junoCmd.Flags().Uint(syncFetchersF, defaultSyncFetchers, syncFetchersUsage)
+	junoCmd.Flags().Uint(syncVerifiersF, defaultSyncVerifiers, syncVerifiersUsage)
+	junoCmd.Flags().Uint(syncMaxInFlightF, defaultSyncMaxInFlight, syncMaxInFlightUsage)
+	junoCmd.Flags().Uint64(syncStopAtF, defaultSyncStopAt, syncStopAtUsage)
+	junoCmd.Flags().String(syncSourceF, defaultSyncSource, syncSourceUsage)
+	junoCmd.Flags().Bool(syncVerifySigsF, defaultSyncVerifySigs, syncVerifySigsUsage)
+	junoCmd.Flags().Bool(syncAccSigsF, defaultSyncAccSigs, syncAccSigsUsage)
+	junoCmd.Flags().Bool(addressIndexF, defaultAddressIndex, addressIndexUsage)
 
 	return junoCmd
 }
+
+// NewVerifyStateCmd returns the verify-state sub-command. It follows the same steps as [NewCmd]:
+// the config struct is populated from the flags and the configuration file before the
+// user-provided run function is called.
+func NewVerifyStateCmd(config *node.Config, run func(*cobra.Command, []string) error) *cobra.Command {
+	verifyStateCmd := &cobra.Command{
+		Use:   "verify-state [flags]",
+		Short: "Verify that the stored state is internally consistent.",
+		Long: "Rebuilds every contract storage trie, the global state trie and the classes trie from " +
+			"their leaves and compares the results with the stored roots and the head block's global state root.",
+		RunE: run,
+	}
+
+	var cfgFile string
+	verifyStateCmd.PreRunE = loadConfig(config, &cfgFile)
+
+	defaultLogLevel := utils.INFO
+	defaultNetwork := utils.MAINNET
+
+	verifyStateCmd.Flags().StringVar(&cfgFile, configF, defaultConfig, configFlagUsage)
+	verifyStateCmd.Flags().Var(&defaultLogLevel, logLevelF, logLevelFlagUsage)
+	verifyStateCmd.Flags().String(dbPathF, defaultDBPath, dbPathUsage)
+	verifyStateCmd.Flags().Var(&defaultNetwork, networkF, networkUsage)
+
+	return verifyStateCmd
+}
+
+// loadConfig returns a function which populates the configuration struct from the Cobra flags
+// and the Viper configuration file found at cfgFile.
+func loadConfig(config *node.Config, cfgFile *string) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, _ []string) error {
+		v := viper.New()
+		if *cfgFile != "" {
+			v.SetConfigType("yaml")
+			v.SetConfigFile(*cfgFile)
+			if err := v.ReadInConfig(); err != nil {
+				return err
+			}
+		}
+
+		if err := v.BindPFlags(cmd.Flags()); err != nil {
+			return nil
+		}
+
+		// TextUnmarshallerHookFunc allows us to unmarshal values that satisfy the
+		// encoding.TextUnmarshaller interface (see the LogLevel type for an example).
+		if err := v.Unmarshal(config, viper.DecodeHook(mapstructure.TextUnmarshallerHookFunc())); err != nil {
+			return err
+		}
+
+		// Any height is a valid stop height, so the default of the flag must not be taken as one.
+		if !v.IsSet(syncStopAtF) {
+			config.SyncStopAt = nil
+		}
+		return nil
+	}
+}
@@ -25,6 +25,9 @@ func TestConfigPrecedence(t *testing.T) {
 	defaultDBPath := ""
 	defaultNetwork := utils.MAINNET
 	defaultPprof := false
+	stopAt := func(height uint64) *uint64 {
+		return &height
+	}
 
 	tests := map[string]struct {
 		cfgFile         bool
@@ -73,13 +76,31 @@ rpc-port: 4576
 db-path: /home/.juno
 network: goerli2
 pprof: true
+prune-keep-blocks: 128
+sync-fetchers: 4
+sync-verifiers: 2
+sync-max-in-flight: 64
+sync-stop-at: 1000
+sync-source: /archive/mainnet.tar.gz
+sync-verify-signatures: true
+sync-verify-account-signatures: true
+address-index: true
 `,
 			expectedConfig: &node.Config{
-				LogLevel:     utils.DEBUG,
-				RPCPort:      4576,
-				DatabasePath: "/home/.juno",
-				Network:      utils.GOERLI2,
-				Pprof:        true,
+				LogLevel:                    utils.DEBUG,
+				RPCPort:                     4576,
+				DatabasePath:                "/home/.juno",
+				Network:                     utils.GOERLI2,
+				Pprof:                       true,
+				PruneKeepBlocks:             128,
+				SyncFetchers:                4,
+				SyncVerifiers:               2,
+				SyncMaxInFlight:             64,
+				SyncStopAt:                  stopAt(1000),
+				SyncSource:                  "/archive/mainnet.tar.gz",
+				SyncVerifySignatures:        true,
+				SyncVerifyAccountSignatures: true,
+				AddressIndex:                true,
 			},
 		},
 		"config file with some settings but without any other flags": {
@@ -98,14 +119,26 @@ rpc-port: 4576
 		"all flags without config file": {
 			inputArgs: []string{
 				"--log-level", "debug", "--rpc-port", "4576",
-				"--db-path", "/home/.juno", "--network", "goerli", "--pprof",
+				"--db-path", "/home/.juno", "--network", "goerli", "--pprof", "--prune-keep-blocks", "64",
+				"--sync-fetchers", "4", "--sync-verifiers", "2", "--sync-max-in-flight", "64", "--sync-stop-at", "1000",
+				"--sync-source", "/archive/mainnet", "--sync-verify-signatures",
+				"--sync-verify-account-signatures", "--address-index",
 			},
 			expectedConfig: &node.Config{
-				LogLevel:     utils.DEBUG,
-				RPCPort:      4576,
-				DatabasePath: "/home/.juno",
-				Network:      utils.GOERLI,
-				Pprof:        true,
+				LogLevel:                    utils.DEBUG,
+				RPCPort:                     4576,
+				DatabasePath:                "/home/.juno",
+				Network:                     utils.GOERLI,
+				Pprof:                       true,
+				PruneKeepBlocks:             64,
+				SyncFetchers:                4,
+				SyncVerifiers:               2,
+				SyncMaxInFlight:             64,
+				SyncStopAt:                  stopAt(1000),
+				SyncSource:                  "/archive/mainnet",
+				SyncVerifySignatures:        true,
+				SyncVerifyAccountSignatures: true,
+				AddressIndex:                true,
 			},
 		},
 		"some flags without config file": {
@@ -127,17 +160,33 @@ rpc-port: 4576
 db-path: /home/config-file/.juno
 network: goerli
 pprof: true
+prune-keep-blocks: 128
+sync-stop-at: 1000
 `,
 			inputArgs: []string{
 				"--log-level", "error", "--rpc-port", "4577",
-				"--db-path", "/home/flag/.juno", "--network", "integration", "--pprof",
+				"--db-path", "/home/flag/.juno", "--network", "integration", "--pprof", "--prune-keep-blocks", "64",
+				"--sync-stop-at", "2000",
 			},
 			expectedConfig: &node.Config{
-				LogLevel:     utils.ERROR,
-				RPCPort:      4577,
-				DatabasePath: "/home/flag/.juno",
-				Network:      utils.INTEGRATION,
-				Pprof:        true,
+				LogLevel:        utils.ERROR,
+				RPCPort:         4577,
+				DatabasePath:    "/home/flag/.juno",
+				Network:         utils.INTEGRATION,
+				Pprof:           true,
+				PruneKeepBlocks: 64,
+				SyncStopAt:      stopAt(2000),
+			},
+		},
+		"stop at genesis": {
+			inputArgs: []string{"--sync-stop-at", "0"},
+			expectedConfig: &node.Config{
+				LogLevel:     defaultLogLevel,
+				RPCPort:      defaultRPCPort,
+				DatabasePath: defaultDBPath,
+				Network:      defaultNetwork,
+				Pprof:        defaultPprof,
+				SyncStopAt:   stopAt(0),
 			},
 		},
 		"some setting set in both config file and flags": {
@@ -192,6 +241,58 @@ network: goerli
 	}
 }
 
+func TestVerifyStateConfig(t *testing.T) {
+	tests := map[string]struct {
+		cfgFile         bool
+		cfgFileContents string
+		inputArgs       []string
+		expectedConfig  *node.Config
+	}{
+		"default config with no flags": {
+			inputArgs: []string{},
+			expectedConfig: &node.Config{
+				LogLevel: utils.INFO,
+				Network:  utils.MAINNET,
+			},
+		},
+		"config file and flags": {
+			cfgFile: true,
+			cfgFileContents: `log-level: debug
+db-path: /home/config-file/.juno
+network: goerli
+`,
+			inputArgs: []string{"--db-path", "/home/flag/.juno"},
+			expectedConfig: &node.Config{
+				LogLevel:     utils.DEBUG,
+				DatabasePath: "/home/flag/.juno",
+				Network:      utils.GOERLI,
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if tc.cfgFile {
+				fileN := tempCfgFile(t, tc.cfgFileContents)
+				tc.inputArgs = append(tc.inputArgs, "--config", fileN)
+			}
+
+			config := new(node.Config)
+			ran := false
+			cmd := juno.NewCmd(new(node.Config), func(_ *cobra.Command, _ []string) error { return nil })
+			cmd.AddCommand(juno.NewVerifyStateCmd(config, func(_ *cobra.Command, _ []string) error {
+				ran = true
+				return nil
+			}))
+			cmd.SetArgs(append([]string{"verify-state"}, tc.inputArgs...))
+
+			require.NoError(t, cmd.ExecuteContext(context.Background()))
+			assert.True(t, ran)
+			assert.Equal(t, tc.expectedConfig, config)
+		})
+	}
+}
+
 func tempCfgFile(t *testing.T, cfg string) string {
 	t.Helper()
 
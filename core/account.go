@@ -0,0 +1,77 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/NethermindEth/juno/core/crypto"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db"
+)
+
+// ErrSignatureNotVerifiable is returned by [VerifyAccountSignature] when the signature of
+// a transaction cannot be checked offline, for example because it was not sent by an
+// account or the account does not keep its public key in a well-known storage variable.
+var ErrSignatureNotVerifiable = errors.New("signature cannot be verified offline")
+
+// accountPublicKeyStorageKeys are the storage addresses where standard accounts keep the
+// public key that signs their transactions.
+var accountPublicKeyStorageKeys = func() []*felt.Felt {
+	names := []string{
+		"Account_public_key", // OpenZeppelin
+		"_signer",            // Argent
+	}
+
+	keys := make([]*felt.Felt, 0, len(names))
+	for _, name := range names {
+		key, err := crypto.StarknetKeccak([]byte(name))
+		if err != nil {
+			panic(err)
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}()
+
+// VerifyAccountSignature checks the signature of a transaction sent from an
+// OpenZeppelin or Argent style account against the public key that the account keeps
+// in its storage. The state must be the state the transaction was executed on.
+//
+// Such accounts sign the transaction hash with [Stark ECDSA] and put (r, s) at the
+// start of the transaction signature. [ErrSignatureNotVerifiable] is returned if the
+// transaction doesn't follow this scheme.
+//
+// [Stark ECDSA]: https://docs.starkware.co/starkex/crypto/signatures.html
+func VerifyAccountSignature(state *State, t Transaction) error {
+	var sender *felt.Felt
+	switch tx := t.(type) {
+	case *InvokeTransaction:
+		sender = tx.SenderAddress
+	case *DeclareTransaction:
+		if tx.Version != nil && !tx.Version.IsZero() {
+			sender = tx.SenderAddress
+		}
+	}
+	signature := t.Signature()
+	if sender == nil || len(signature) < 2 {
+		return ErrSignatureNotVerifiable
+	}
+
+	for _, key := range accountPublicKeyStorageKeys {
+		publicKey, err := state.ContractStorage(sender, key)
+		if errors.Is(err, db.ErrKeyNotFound) {
+			continue
+		} else if errors.Is(err, ErrContractNotDeployed) {
+			return ErrSignatureNotVerifiable
+		} else if err != nil {
+			return err
+		}
+
+		if !crypto.Verify(t.Hash(), signature[0], signature[1], publicKey) {
+			return fmt.Errorf("signature of transaction %s does not match public key %s of account %s",
+				t.Hash(), publicKey, sender)
+		}
+		return nil
+	}
+	return ErrSignatureNotVerifiable
+}
@@ -0,0 +1,101 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/crypto"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db/pebble"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyAccountSignature(t *testing.T) {
+	testDB := pebble.NewMemTest()
+	txn := testDB.NewTransaction(true)
+	t.Cleanup(func() {
+		require.NoError(t, txn.Discard())
+	})
+	state := core.NewState(txn)
+
+	privateKey := utils.HexToFelt(t, "0x3c1e9550e66958296d11b60f8e8e7a7ad990d07fa65d5f7652c4a6c87d4e3cc")
+	publicKey, err := crypto.PublicKey(privateKey)
+	require.NoError(t, err)
+
+	deployAccount := func(addr *felt.Felt, publicKeyVariable string) {
+		contract, err := core.DeployContract(addr, utils.HexToFelt(t, "0xACC"), txn)
+		require.NoError(t, err)
+		if publicKeyVariable == "" {
+			return
+		}
+		key, err := crypto.StarknetKeccak([]byte(publicKeyVariable))
+		require.NoError(t, err)
+		require.NoError(t, contract.UpdateStorage([]core.StorageDiff{{Key: key, Value: publicKey}}))
+	}
+
+	openZeppelin := utils.HexToFelt(t, "0x1")
+	deployAccount(openZeppelin, "Account_public_key")
+	argent := utils.HexToFelt(t, "0x2")
+	deployAccount(argent, "_signer")
+	unknown := utils.HexToFelt(t, "0x3")
+	deployAccount(unknown, "")
+
+	signedInvoke := func(t *testing.T, sender *felt.Felt) *core.InvokeTransaction {
+		hash := utils.HexToFelt(t, "0x397e76d1667c4454bfb83514e120583af836f8e32a516765497823eb85a5c84")
+		r, s, err := crypto.Sign(hash, privateKey)
+		require.NoError(t, err)
+		return &core.InvokeTransaction{
+			TransactionHash:      hash,
+			SenderAddress:        sender,
+			TransactionSignature: []*felt.Felt{r, s},
+			Version:              new(felt.Felt).SetUint64(1),
+		}
+	}
+
+	t.Run("OpenZeppelin account", func(t *testing.T) {
+		assert.NoError(t, core.VerifyAccountSignature(state, signedInvoke(t, openZeppelin)))
+	})
+
+	t.Run("Argent account", func(t *testing.T) {
+		assert.NoError(t, core.VerifyAccountSignature(state, signedInvoke(t, argent)))
+	})
+
+	t.Run("declare transaction", func(t *testing.T) {
+		invoke := signedInvoke(t, openZeppelin)
+		declare := &core.DeclareTransaction{
+			TransactionHash:      invoke.TransactionHash,
+			SenderAddress:        openZeppelin,
+			TransactionSignature: invoke.TransactionSignature,
+			Version:              new(felt.Felt).SetUint64(1),
+		}
+		assert.NoError(t, core.VerifyAccountSignature(state, declare))
+	})
+
+	t.Run("signature does not match", func(t *testing.T) {
+		invoke := signedInvoke(t, openZeppelin)
+		invoke.TransactionHash = utils.HexToFelt(t, "0x1234")
+		assert.EqualError(t, core.VerifyAccountSignature(state, invoke), "signature of transaction 0x1234 does not match public key "+
+			publicKey.String()+" of account 0x1")
+	})
+
+	t.Run("not verifiable", func(t *testing.T) {
+		short := signedInvoke(t, openZeppelin)
+		short.TransactionSignature = short.TransactionSignature[:1]
+
+		tests := map[string]core.Transaction{
+			"invoke v0":              &core.InvokeTransaction{TransactionSignature: short.TransactionSignature},
+			"short signature":        short,
+			"unknown account":        signedInvoke(t, unknown),
+			"undeployed account":     signedInvoke(t, utils.HexToFelt(t, "0x4")),
+			"l1 handler transaction": &core.L1HandlerTransaction{},
+		}
+		for name, tx := range tests {
+			tx := tx
+			t.Run(name, func(t *testing.T) {
+				assert.ErrorIs(t, core.VerifyAccountSignature(state, tx), core.ErrSignatureNotVerifiable)
+			})
+		}
+	})
+}
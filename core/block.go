@@ -1,6 +1,7 @@
 package core
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
 
@@ -30,8 +31,36 @@ type Header struct {
 	ProtocolVersion string
 	// Extraneous data that might be useful for running transactions
 	ExtraData *felt.Felt
+	// The price of L1 gas in wei
+	GasPrice *felt.Felt
+	// The price of L1 gas in fri (STRK), since 0.13.0
+	GasPriceSTRK *felt.Felt
+	// The price of L1 data gas, since 0.13.1
+	L1DataGasPrice *GasPrice
+	// How the state diff of this block is posted to L1, since 0.13.1
+	L1DAMode L1DAMode
+	// The commitment to the state diff of this block, see [StateDiff.Commitment]
+	StateDiffCommitment *felt.Felt
+	// The number of changes in the state diff of this block, see [StateDiff.Length]
+	StateDiffLength uint64
+	// The signature of the sequencer on this block, nil if it was not verified
+	Signature []*felt.Felt
 }
 
+// GasPrice is the price of a unit of gas in wei and in fri (STRK).
+type GasPrice struct {
+	PriceInWei *felt.Felt
+	PriceInFri *felt.Felt
+}
+
+// L1DAMode tells how the state diff of a block is posted to L1.
+type L1DAMode uint8
+
+const (
+	Calldata L1DAMode = iota
+	Blob
+)
+
 type Block struct {
 	*Header
 	Transactions []Transaction
@@ -42,6 +71,9 @@ type blockHashMetaInfo struct {
 	First07Block             uint64     // First block that uses the post-0.7.0 block hash algorithm
 	UnverifiableRange        []uint64   // Range of blocks that are not verifiable
 	FallBackSequencerAddress *felt.Felt // The sequencer address to use for blocks that do not have one
+	// First block known to only include transactions hashed with version and max_fee, transactions
+	// of earlier blocks may have been hashed with algorithms that have been deprecated since
+	FirstVersionedTxHashBlock uint64
 }
 
 func networkBlockHashMetaInfo(network utils.Network) *blockHashMetaInfo {
@@ -59,14 +91,16 @@ func networkBlockHashMetaInfo(network utils.Network) *blockHashMetaInfo {
 			panic(fmt.Sprintf("Error while creating FallBackSequencerAddress %s", err))
 		}
 		return &blockHashMetaInfo{
-			First07Block:             833,
-			FallBackSequencerAddress: fallBackSequencerAddress,
+			First07Block:              833,
+			FallBackSequencerAddress:  fallBackSequencerAddress,
+			FirstVersionedTxHashBlock: 2889,
 		}
 	case utils.GOERLI:
 		return &blockHashMetaInfo{
-			First07Block:             47028,
-			UnverifiableRange:        []uint64{119802, 148428},
-			FallBackSequencerAddress: fallBackSequencerAddress,
+			First07Block:              47028,
+			UnverifiableRange:         []uint64{119802, 148428},
+			FallBackSequencerAddress:  fallBackSequencerAddress,
+			FirstVersionedTxHashBlock: 156000,
 		}
 	case utils.GOERLI2:
 		return &blockHashMetaInfo{
@@ -75,9 +109,10 @@ func networkBlockHashMetaInfo(network utils.Network) *blockHashMetaInfo {
 		}
 	case utils.INTEGRATION:
 		return &blockHashMetaInfo{
-			First07Block:             110511,
-			UnverifiableRange:        []uint64{0, 110511},
-			FallBackSequencerAddress: fallBackSequencerAddress,
+			First07Block:              110511,
+			UnverifiableRange:         []uint64{0, 110511},
+			FallBackSequencerAddress:  fallBackSequencerAddress,
+			FirstVersionedTxHashBlock: 283364,
 		}
 	default:
 		// This should never happen
@@ -85,9 +120,17 @@ func networkBlockHashMetaInfo(network utils.Network) *blockHashMetaInfo {
 	}
 }
 
-// VerifyBlockHash verifies the block hash. Due to bugs in Starknet alpha, not all blocks have
-// verifiable hashes.
+// VerifyBlockHash verifies the block hash and checks that the block follows the rules of its
+// protocol version. Due to bugs in Starknet alpha, not all blocks have verifiable hashes.
 func VerifyBlockHash(b *Block, network utils.Network) error {
+	rules, err := rulesFor(b.ProtocolVersion)
+	if err != nil {
+		return err
+	}
+	if err = rules.verifyHeader(b.Header); err != nil {
+		return err
+	}
+
 	if len(b.Transactions) != len(b.Receipts) {
 		return fmt.Errorf("len of transactions: %v do not match len of receipts: %v",
 			len(b.Transactions), len(b.Receipts))
@@ -101,9 +144,39 @@ func VerifyBlockHash(b *Block, network utils.Network) error {
 		}
 	}
 
-	if err := verifyTransactions(b.Transactions, network); err != nil {
+	if err = rules.verifyTransactions(b); err != nil {
 		return err
 	}
+	if err = verifyContractAddresses(b.Transactions); err != nil {
+		return err
+	}
+	if err = verifyHeaderHash(b, network, rules); err != nil {
+		return err
+	}
+	// Transactions in early blocks were hashed with algorithms that are not all known, so a
+	// transaction hash that cannot be verified does not make the block invalid on its own. It is
+	// checked last so that the block hash is always verified.
+	return verifyTransactions(b.Transactions, network, legacyTransactionHashes(b, network))
+}
+
+// legacyTransactionHashes reports whether the transactions of the block may have been hashed with
+// the algorithms that were in use before version and max_fee were introduced. Only blocks from
+// before the protocol version was reported can, and only up to a network specific height.
+func legacyTransactionHashes(b *Block, network utils.Network) bool {
+	return b.ProtocolVersion == "" && b.Number < networkBlockHashMetaInfo(network).FirstVersionedTxHashBlock
+}
+
+func verifyHeaderHash(b *Block, network utils.Network, rules *protocolRules) error {
+	if rules.poseidonBlockHash {
+		hash, err := post0132Hash(b)
+		if err != nil {
+			return err
+		}
+		if !hash.Equal(b.Hash) {
+			return errors.New("can not verify hash in block header")
+		}
+		return nil
+	}
 
 	metaInfo := networkBlockHashMetaInfo(network)
 
@@ -122,7 +195,7 @@ func VerifyBlockHash(b *Block, network utils.Network) error {
 			overrideSeq = fallbackSeq
 		}
 
-		hash, err := blockHash(b, network, overrideSeq)
+		hash, err := blockHash(b, network, overrideSeq, rules.allSignatures)
 		if err != nil {
 			return err
 		}
@@ -134,19 +207,43 @@ func VerifyBlockHash(b *Block, network utils.Network) error {
 	return errors.New("can not verify hash in block header")
 }
 
-// blockHash computes the block hash, with option to override sequence address
-func blockHash(b *Block, network utils.Network, overrideSeqAddr *felt.Felt) (*felt.Felt, error) {
+// BlockSignature is the signature of the sequencer on a block, together with the values it signed.
+type BlockSignature struct {
+	BlockHash           *felt.Felt
+	StateDiffCommitment *felt.Felt
+	Signature           []*felt.Felt
+}
+
+// Verify checks that the signature was made with the private key of the given public key.
+// Sequencers sign Pedersen(block hash, state diff commitment) with [Stark ECDSA] and the
+// signature is (r, s).
+//
+// [Stark ECDSA]: https://docs.starkware.co/starkex/crypto/signatures.html
+func (s *BlockSignature) Verify(publicKey *felt.Felt) error {
+	if len(s.Signature) != 2 {
+		return fmt.Errorf("signature of block %s has %d elements, expected 2", s.BlockHash, len(s.Signature))
+	}
+
+	msgHash := crypto.Pedersen(s.BlockHash, s.StateDiffCommitment)
+	if !crypto.Verify(msgHash, s.Signature[0], s.Signature[1], publicKey) {
+		return fmt.Errorf("signature of block %s does not match sequencer public key %s", s.BlockHash, publicKey)
+	}
+	return nil
+}
+
+// blockHash computes the Pedersen block hash, with option to override sequence address
+func blockHash(b *Block, network utils.Network, overrideSeqAddr *felt.Felt, allSignatures bool) (*felt.Felt, error) {
 	metaInfo := networkBlockHashMetaInfo(network)
 
 	if b.Number < metaInfo.First07Block {
 		return pre07Hash(b, network.ChainID())
 	}
-	return post07Hash(b, overrideSeqAddr)
+	return post07Hash(b, overrideSeqAddr, allSignatures)
 }
 
 // pre07Hash computes the block hash for blocks generated before Cairo 0.7.0
 func pre07Hash(b *Block, chain *felt.Felt) (*felt.Felt, error) {
-	txCommitment, err := transactionCommitment(b.Transactions)
+	txCommitment, err := transactionCommitment(b.Transactions, false)
 	if err != nil {
 		return nil, err
 	}
@@ -168,13 +265,13 @@ func pre07Hash(b *Block, chain *felt.Felt) (*felt.Felt, error) {
 }
 
 // post07Hash computes the block hash for blocks generated after Cairo 0.7.0
-func post07Hash(b *Block, overrideSeqAddr *felt.Felt) (*felt.Felt, error) {
+func post07Hash(b *Block, overrideSeqAddr *felt.Felt, allSignatures bool) (*felt.Felt, error) {
 	seqAddr := b.SequencerAddress
 	if overrideSeqAddr != nil {
 		seqAddr = overrideSeqAddr
 	}
 
-	txCommitment, err := transactionCommitment(b.Transactions)
+	txCommitment, err := transactionCommitment(b.Transactions, allSignatures)
 	if err != nil {
 		return nil, err
 	}
@@ -204,3 +301,75 @@ func post07Hash(b *Block, overrideSeqAddr *felt.Felt) (*felt.Felt, error) {
 		b.ParentHash,                                 // parent block hash
 	), nil
 }
+
+var (
+	blockHashPrefix = new(felt.Felt).SetBytes([]byte("STARKNET_BLOCK_HASH0"))
+	gasPricesPrefix = new(felt.Felt).SetBytes([]byte("STARKNET_GAS_PRICES0"))
+)
+
+// post0132Hash computes the block hash for blocks generated since Starknet 0.13.2. Unlike the
+// Pedersen hashes, it also commits to the state diff, the receipts and the gas prices.
+func post0132Hash(b *Block) (*felt.Felt, error) {
+	if b.StateDiffCommitment == nil {
+		return nil, errors.New("state diff commitment is missing")
+	}
+
+	txCommitment, err := transactionCommitmentPoseidon(b.Transactions)
+	if err != nil {
+		return nil, err
+	}
+	eCommitment, err := eventCommitmentPoseidon(b.Receipts)
+	if err != nil {
+		return nil, err
+	}
+	rCommitment, err := receiptCommitment(b.Receipts)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := concatCounts(b.TransactionCount, b.EventCount, b.StateDiffLength, b.L1DAMode)
+	protocolVersion := new(felt.Felt).SetBytes([]byte(b.ProtocolVersion))
+	return crypto.PoseidonArray(
+		blockHashPrefix,
+		new(felt.Felt).SetUint64(b.Number),    // block number
+		b.GlobalStateRoot,                     // global state root
+		b.SequencerAddress,                    // sequencer address
+		new(felt.Felt).SetUint64(b.Timestamp), // block timestamp
+		counts,                                // transaction, event and state diff counts, L1 DA mode
+		b.StateDiffCommitment,                 // state diff commitment
+		txCommitment,                          // transaction commitment
+		eCommitment,                           // event commitment
+		rCommitment,                           // receipt commitment
+		gasPricesHash(b.Header),               // gas prices
+		protocolVersion,                       // protocol version
+		&felt.Zero,                            // reserved: extra data
+		b.ParentHash,                          // parent block hash
+	), nil
+}
+
+// concatCounts packs the transaction, event and state diff counts of a block into a single felt,
+// 64 bits each, followed by a bit that is set if the state diff is posted to L1 in a blob.
+func concatCounts(txCount, eventCount, stateDiffLength uint64, l1DAMode L1DAMode) *felt.Felt {
+	var counts [32]byte
+	binary.BigEndian.PutUint64(counts[:8], txCount)
+	binary.BigEndian.PutUint64(counts[8:16], eventCount)
+	binary.BigEndian.PutUint64(counts[16:24], stateDiffLength)
+	if l1DAMode == Blob {
+		counts[24] = 0b1000_0000
+	}
+	return new(felt.Felt).SetBytes(counts[:])
+}
+
+func gasPricesHash(h *Header) *felt.Felt {
+	dataGasPrice := new(GasPrice)
+	if h.L1DataGasPrice != nil {
+		dataGasPrice = h.L1DataGasPrice
+	}
+	return crypto.PoseidonArray(
+		gasPricesPrefix,
+		feltOrZero(h.GasPrice),
+		feltOrZero(h.GasPriceSTRK),
+		feltOrZero(dataGasPrice.PriceInWei),
+		feltOrZero(dataGasPrice.PriceInFri),
+	)
+}
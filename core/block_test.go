@@ -8,6 +8,7 @@ import (
 
 	"github.com/NethermindEth/juno/clients/feeder"
 	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/crypto"
 	"github.com/NethermindEth/juno/core/felt"
 	adaptfeeder "github.com/NethermindEth/juno/starknetdata/feeder"
 	"github.com/NethermindEth/juno/utils"
@@ -228,4 +229,212 @@ func TestBlockHash(t *testing.T) {
 				mainnetBlock1.Receipts[1].TransactionHash)
 			assert.EqualError(t, core.VerifyBlockHash(mainnetBlock1, utils.MAINNET), expectedErr)
 		})
+
+	t.Run("error if contract address of deployed contract cannot be verified", func(t *testing.T) {
+		mainnetBlock1, err := mainnetGW.BlockByNumber(context.Background(), 1)
+		require.NoError(t, err)
+
+		deployTx, ok := mainnetBlock1.Transactions[0].(*core.DeployTransaction)
+		require.True(t, ok)
+		// the salt is not part of the transaction hash
+		deployTx.ContractAddressSalt = h1
+		calculatedAddress := core.ContractAddress(&felt.Zero, deployTx.ClassHash, h1, deployTx.ConstructorCallData)
+
+		err = core.VerifyBlockHash(mainnetBlock1, utils.MAINNET)
+		var mismatchErr *core.ContractAddressMismatchError
+		require.ErrorAs(t, err, &mismatchErr)
+		assert.False(t, errors.As(err, new(core.CantVerifyTransactionHashError)))
+		assert.Equal(t, 0, mismatchErr.Index)
+		assert.Equal(t, deployTx.Hash(), mismatchErr.TransactionHash)
+		assert.Equal(t, deployTx.ContractAddress, mismatchErr.Address)
+		assert.Equal(t, calculatedAddress, mismatchErr.CalculatedAddress)
+		assert.Nil(t, errors.Unwrap(err))
+	})
+
+	t.Run("every contract address mismatch is reported", func(t *testing.T) {
+		mainnetBlock1, err := mainnetGW.BlockByNumber(context.Background(), 1)
+		require.NoError(t, err)
+
+		var mismatched []int
+		for i, tx := range mainnetBlock1.Transactions {
+			if deployTx, ok := tx.(*core.DeployTransaction); ok {
+				deployTx.ContractAddressSalt = h1
+				mismatched = append(mismatched, i)
+			}
+		}
+		require.Greater(t, len(mismatched), 1)
+
+		var reported []int
+		for err = core.VerifyBlockHash(mainnetBlock1, utils.MAINNET); err != nil; err = errors.Unwrap(err) {
+			var mismatchErr *core.ContractAddressMismatchError
+			require.ErrorAs(t, err, &mismatchErr)
+			reported = append(reported, mismatchErr.Index)
+		}
+		assert.Equal(t, mismatched, reported)
+	})
+
+	t.Run("error if deployed contract has no class hash", func(t *testing.T) {
+		mainnetBlock1, err := mainnetGW.BlockByNumber(context.Background(), 1)
+		require.NoError(t, err)
+
+		deployTx, ok := mainnetBlock1.Transactions[0].(*core.DeployTransaction)
+		require.True(t, ok)
+		deployTx.ClassHash = nil
+
+		err = core.VerifyBlockHash(mainnetBlock1, utils.MAINNET)
+		require.Error(t, err)
+		assert.False(t, errors.As(err, new(*core.ContractAddressMismatchError)))
+	})
+
+	t.Run("block hash is verified before transaction hashes", func(t *testing.T) {
+		mainnetBlock1, err := mainnetGW.BlockByNumber(context.Background(), 1)
+		require.NoError(t, err)
+
+		mainnetBlock1.Transactions[2].(*core.InvokeTransaction).MaxFee = h1
+		mainnetBlock1.Hash = h1
+		assert.EqualError(t, core.VerifyBlockHash(mainnetBlock1, utils.MAINNET), "can not verify hash in block header")
+	})
+}
+
+func TestBlockSignatureVerify(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+	ctx := context.Background()
+
+	// the blocks are signed with a test key rather than by the sequencer
+	privateKey := utils.HexToFelt(t, "0x1234567890abcdef")
+	publicKey, err := crypto.PublicKey(privateKey)
+	require.NoError(t, err)
+	sign := func(t *testing.T, number uint64) *core.BlockSignature {
+		t.Helper()
+		block, err := gw.BlockByNumber(ctx, number)
+		require.NoError(t, err)
+		stateUpdate, err := gw.StateUpdate(ctx, number)
+		require.NoError(t, err)
+
+		commitment := stateUpdate.StateDiff.Commitment()
+		r, s, err := crypto.Sign(crypto.Pedersen(block.Hash, commitment), privateKey)
+		require.NoError(t, err)
+		return &core.BlockSignature{BlockHash: block.Hash, StateDiffCommitment: commitment, Signature: []*felt.Felt{r, s}}
+	}
+	signature := sign(t, 1)
+
+	t.Run("valid signature", func(t *testing.T) {
+		assert.NoError(t, signature.Verify(publicKey))
+	})
+	t.Run("signature of another key", func(t *testing.T) {
+		otherPublicKey, err := crypto.PublicKey(utils.HexToFelt(t, "0xfedcba0987654321"))
+		require.NoError(t, err)
+		assert.EqualError(t, signature.Verify(otherPublicKey), fmt.Sprintf(
+			"signature of block %s does not match sequencer public key %s", signature.BlockHash, otherPublicKey))
+	})
+	t.Run("signature over another block", func(t *testing.T) {
+		other := sign(t, 2)
+
+		tampered := *signature
+		tampered.Signature = other.Signature
+		assert.EqualError(t, tampered.Verify(publicKey), fmt.Sprintf(
+			"signature of block %s does not match sequencer public key %s", signature.BlockHash, publicKey))
+	})
+	t.Run("malformed signature", func(t *testing.T) {
+		tampered := *signature
+		tampered.Signature = signature.Signature[:1]
+		assert.EqualError(t, tampered.Verify(publicKey), fmt.Sprintf(
+			"signature of block %s has 1 elements, expected 2", signature.BlockHash))
+	})
+}
+
+// post0132Block returns a made-up Starknet 0.13.2 integration block with a version 3 invoke
+// transaction, whose hash is computed from its contents.
+func post0132Block(t *testing.T) *core.Block {
+	t.Helper()
+
+	tx := &core.InvokeTransaction{
+		TransactionHash:      utils.HexToFelt(t, "0x3a94edbc959af535b175019388eac611acb11cac0be843862050c79245c3631"),
+		SenderAddress:        utils.HexToFelt(t, "0x3f6f3bc663aedc5285d6013cc3ffcbc4341d86ab488b8b68d297f8258793c41"),
+		Nonce:                utils.HexToFelt(t, "0x8"),
+		CallData:             []*felt.Felt{utils.HexToFelt(t, "0x1"), utils.HexToFelt(t, "0x2")},
+		TransactionSignature: []*felt.Felt{utils.HexToFelt(t, "0x11"), utils.HexToFelt(t, "0x22")},
+		Version:              utils.HexToFelt(t, "0x3"),
+		ResourceBounds: map[core.Resource]core.ResourceBounds{
+			core.ResourceL1Gas: {MaxAmount: 0x186a0, MaxPricePerUnit: utils.HexToFelt(t, "0x5af3107a4000")},
+			core.ResourceL2Gas: {MaxAmount: 0, MaxPricePerUnit: &felt.Zero},
+		},
+		PaymasterData:         []*felt.Felt{},
+		AccountDeploymentData: []*felt.Felt{},
+	}
+	receipt := &core.TransactionReceipt{
+		TransactionHash: tx.TransactionHash,
+		Fee:             utils.HexToFelt(t, "0x2a"),
+		Events: []*core.Event{{
+			From: utils.HexToFelt(t, "0x49d36570d4e46f48e99674bd3fcc84644ddd6b96f7c741b1562b82f9e004dc7"),
+			Keys: []*felt.Felt{utils.HexToFelt(t, "0x99")},
+			Data: []*felt.Felt{utils.HexToFelt(t, "0x1"), utils.HexToFelt(t, "0x2")},
+		}},
+		ExecutionResources: &core.ExecutionResources{
+			TotalGasConsumed: &core.GasConsumed{L1Gas: 1000, L1DataGas: 128},
+		},
+	}
+	stateDiff := &core.StateDiff{
+		Nonces: map[felt.Felt]*felt.Felt{*tx.SenderAddress: utils.HexToFelt(t, "0x9")},
+	}
+
+	return &core.Block{
+		Header: &core.Header{
+			Hash:             utils.HexToFelt(t, "0x37a6a6dda2e8ed6bd65e316101b6b6458419778a52aadb05a465a16883ce458"),
+			ParentHash:       utils.HexToFelt(t, "0x123"),
+			Number:           5,
+			GlobalStateRoot:  utils.HexToFelt(t, "0x456"),
+			SequencerAddress: utils.HexToFelt(t, "0x1176a1bd84444c89232ec27754698e5d2e7e1a7f1539f12027f28b23ec9f3d8"),
+			TransactionCount: 1,
+			EventCount:       1,
+			Timestamp:        1720000000,
+			ProtocolVersion:  "0.13.2",
+			GasPrice:         utils.HexToFelt(t, "0x3b9aca00"),
+			GasPriceSTRK:     utils.HexToFelt(t, "0x2540be400"),
+			L1DataGasPrice: &core.GasPrice{
+				PriceInWei: utils.HexToFelt(t, "0x1"),
+				PriceInFri: utils.HexToFelt(t, "0x2"),
+			},
+			L1DAMode:            core.Blob,
+			StateDiffCommitment: stateDiff.Commitment(),
+			StateDiffLength:     stateDiff.Length(),
+		},
+		Transactions: []core.Transaction{tx},
+		Receipts:     []*core.TransactionReceipt{receipt},
+	}
+}
+
+func TestPost0132BlockHash(t *testing.T) {
+	t.Run("valid block", func(t *testing.T) {
+		assert.NoError(t, core.VerifyBlockHash(post0132Block(t), utils.INTEGRATION))
+	})
+
+	// the Poseidon block hash commits to values that the Pedersen block hash ignores
+	tamper := map[string]func(b *core.Block){
+		"gas price":        func(b *core.Block) { b.GasPriceSTRK = utils.HexToFelt(t, "0x2540be401") },
+		"data gas price":   func(b *core.Block) { b.L1DataGasPrice.PriceInFri = utils.HexToFelt(t, "0x3") },
+		"DA mode":          func(b *core.Block) { b.L1DAMode = core.Calldata },
+		"state diff":       func(b *core.Block) { b.StateDiffLength = 2 },
+		"receipt fee":      func(b *core.Block) { b.Receipts[0].Fee = utils.HexToFelt(t, "0x2b") },
+		"revert reason":    func(b *core.Block) { b.Receipts[0].RevertReason = "reverted" },
+		"gas consumed":     func(b *core.Block) { b.Receipts[0].ExecutionResources.TotalGasConsumed.L1DataGas = 0 },
+		"protocol version": func(b *core.Block) { b.ProtocolVersion = "0.13.2.1" },
+	}
+	for name, fn := range tamper {
+		fn := fn
+		t.Run(name, func(t *testing.T) {
+			block := post0132Block(t)
+			fn(block)
+			assert.EqualError(t, core.VerifyBlockHash(block, utils.INTEGRATION), "can not verify hash in block header")
+		})
+	}
+
+	t.Run("version 3 transaction hash", func(t *testing.T) {
+		block := post0132Block(t)
+		block.Transactions[0].(*core.InvokeTransaction).Tip = 1
+		err := core.VerifyBlockHash(block, utils.INTEGRATION)
+		assert.True(t, errors.As(err, new(core.CantVerifyTransactionHashError)))
+	})
 }
@@ -1,6 +1,10 @@
 package core
 
 import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
 	"github.com/NethermindEth/juno/core/crypto"
 	"github.com/NethermindEth/juno/core/felt"
 )
@@ -80,6 +84,9 @@ type Cairo1Class struct {
 	Program         []*felt.Felt
 	ProgramHash     *felt.Felt
 	SemanticVersion string
+	// Compiled is the CASM the class compiles to. It is stored separately,
+	// keyed by the compiled class hash.
+	Compiled *CompiledClass `cbor:"-"`
 }
 
 type SierraEntryPoint struct {
@@ -112,3 +119,126 @@ func flattenSierraEntryPoints(entryPoints []SierraEntryPoint) []*felt.Felt {
 	}
 	return result
 }
+
+// VerifyClassHashes checks that every class hashes to the class hash it is keyed by.
+func VerifyClassHashes(classes map[felt.Felt]Class) error {
+	for classHash, class := range classes {
+		classHash := classHash
+		if class == nil {
+			return fmt.Errorf("class %s: definition is missing", &classHash)
+		}
+		if hash := class.Hash(); !hash.Equal(&classHash) {
+			return fmt.Errorf("class %s: calculated class hash %s does not match", &classHash, hash)
+		}
+	}
+	return nil
+}
+
+// CompiledClass is the Cairo assembly (CASM) a [Cairo1Class] is compiled to.
+type CompiledClass struct {
+	Bytecode []*felt.Felt
+	// BytecodeSegmentLengths splits the bytecode into segments that are hashed separately, it is
+	// nil for CASM compiled before segments were introduced.
+	BytecodeSegmentLengths *SegmentLengths
+	PythonicHints          json.RawMessage
+	CompilerVersion        string
+	Hints                  json.RawMessage
+	Prime                  *big.Int
+	External               []CompiledEntryPoint
+	L1Handler              []CompiledEntryPoint
+	Constructor            []CompiledEntryPoint
+}
+
+type CompiledEntryPoint struct {
+	Offset   uint64
+	Builtins []string
+	Selector *felt.Felt
+}
+
+// SegmentLengths is either the length of a bytecode segment, if it has no children, or the
+// list of the segments it is made of.
+type SegmentLengths struct {
+	Length   uint64
+	Children []SegmentLengths
+}
+
+// Total returns the number of bytecode words covered by the segment, or false if it overflows.
+func (s *SegmentLengths) Total() (uint64, bool) {
+	if len(s.Children) == 0 {
+		return s.Length, true
+	}
+	var total uint64
+	for i := range s.Children {
+		length, ok := s.Children[i].Total()
+		if !ok || total+length < total {
+			return 0, false
+		}
+		total += length
+	}
+	return total, true
+}
+
+var compiledClassVersion = new(felt.Felt).SetBytes([]byte("COMPILED_CLASS_V1"))
+
+// Hash computes the compiled class hash that is committed to in the leaves of the classes trie.
+func (c *CompiledClass) Hash() (*felt.Felt, error) {
+	bytecodeHash, err := c.bytecodeHash()
+	if err != nil {
+		return nil, err
+	}
+	return crypto.PoseidonArray(
+		compiledClassVersion,
+		crypto.PoseidonArray(flattenCompiledEntryPoints(c.External)...),
+		crypto.PoseidonArray(flattenCompiledEntryPoints(c.L1Handler)...),
+		crypto.PoseidonArray(flattenCompiledEntryPoints(c.Constructor)...),
+		bytecodeHash,
+	), nil
+}
+
+func (c *CompiledClass) bytecodeHash() (*felt.Felt, error) {
+	if c.BytecodeSegmentLengths == nil {
+		return crypto.PoseidonArray(c.Bytecode...), nil
+	}
+	if total, ok := c.BytecodeSegmentLengths.Total(); !ok || total != uint64(len(c.Bytecode)) {
+		return nil, fmt.Errorf("bytecode segments do not cover the %d bytecode words", len(c.Bytecode))
+	}
+	return segmentHash(c.Bytecode, c.BytecodeSegmentLengths), nil
+}
+
+// segmentHash hashes the bytecode segment the same way the sequencer does: a segment without
+// children is hashed as a whole, otherwise the length and the hash of each child are hashed
+// together and one is added to the result. The segment starts at the beginning of bytecode,
+// which must cover it.
+func segmentHash(bytecode []*felt.Felt, segment *SegmentLengths) *felt.Felt {
+	if len(segment.Children) == 0 {
+		return crypto.PoseidonArray(bytecode[:segment.Length]...)
+	}
+
+	var offset uint64
+	lengthsAndHashes := make([]*felt.Felt, 0, 2*len(segment.Children))
+	for i := range segment.Children {
+		child := &segment.Children[i]
+		length, _ := child.Total()
+		lengthsAndHashes = append(lengthsAndHashes, new(felt.Felt).SetUint64(length),
+			segmentHash(bytecode[offset:], child))
+		offset += length
+	}
+	hash := crypto.PoseidonArray(lengthsAndHashes...)
+	return hash.Add(hash, new(felt.Felt).SetUint64(1))
+}
+
+func flattenCompiledEntryPoints(entryPoints []CompiledEntryPoint) []*felt.Felt {
+	result := make([]*felt.Felt, len(entryPoints)*3)
+	for i, entryPoint := range entryPoints {
+		builtins := make([]*felt.Felt, len(entryPoint.Builtins))
+		for idx, builtin := range entryPoint.Builtins {
+			builtins[idx] = new(felt.Felt).SetBytes([]byte(builtin))
+		}
+		// It is important that Selector is first, followed by Offset and the hash of the
+		// builtins because the order influences the compiled class hash.
+		result[3*i] = entryPoint.Selector
+		result[3*i+1] = new(felt.Felt).SetUint64(entryPoint.Offset)
+		result[3*i+2] = crypto.PoseidonArray(builtins...)
+	}
+	return result
+}
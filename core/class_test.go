@@ -7,6 +7,7 @@ import (
 
 	"github.com/NethermindEth/juno/clients/feeder"
 	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/crypto"
 	"github.com/NethermindEth/juno/core/felt"
 	"github.com/NethermindEth/juno/encoder"
 	adaptfeeder "github.com/NethermindEth/juno/starknetdata/feeder"
@@ -144,3 +145,130 @@ func checkClassSymmetry(t *testing.T, input core.Class) {
 		assert.Fail(t, "not a class")
 	}
 }
+
+func TestVerifyClassHashes(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.INTEGRATION)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+
+	classHash := utils.HexToFelt(t, "0x4e70b19333ae94bd958625f7b61ce9eec631653597e68645e13780061b2136c")
+	class, err := gw.Class(context.Background(), classHash)
+	require.NoError(t, err)
+
+	t.Run("valid class", func(t *testing.T) {
+		assert.NoError(t, core.VerifyClassHashes(map[felt.Felt]core.Class{*classHash: class}))
+	})
+
+	t.Run("class definition is nil", func(t *testing.T) {
+		assert.EqualError(t, core.VerifyClassHashes(map[felt.Felt]core.Class{*classHash: nil}),
+			"class "+classHash.String()+": definition is missing")
+	})
+
+	t.Run("class hash mismatch", func(t *testing.T) {
+		wrongHash := new(felt.Felt).SetUint64(1)
+		err := core.VerifyClassHashes(map[felt.Felt]core.Class{*wrongHash: class})
+		assert.EqualError(t, err, "class "+wrongHash.String()+": calculated class hash "+classHash.String()+" does not match")
+	})
+}
+
+func TestCompiledClassHash(t *testing.T) {
+	selector := utils.HexToFelt(t, "0x22ff5f21f0b81b113e63f7db6da94fedef11b2119b4088b89664fb9a3cb658")
+	compiled := &core.CompiledClass{
+		Bytecode: []*felt.Felt{utils.HexToFelt(t, "0xa0680017fff8000"), utils.HexToFelt(t, "0x7")},
+		External: []core.CompiledEntryPoint{
+			{Selector: selector, Offset: 1, Builtins: []string{"range_check", "pedersen"}},
+		},
+		L1Handler:   []core.CompiledEntryPoint{},
+		Constructor: []core.CompiledEntryPoint{},
+	}
+
+	// https://docs.starknet.io/documentation/architecture_and_concepts/Contracts/class-hash/
+	builtinsHash := crypto.PoseidonArray(
+		new(felt.Felt).SetBytes([]byte("range_check")),
+		new(felt.Felt).SetBytes([]byte("pedersen")),
+	)
+	expected := crypto.PoseidonArray(
+		new(felt.Felt).SetBytes([]byte("COMPILED_CLASS_V1")),
+		crypto.PoseidonArray(selector, new(felt.Felt).SetUint64(1), builtinsHash),
+		crypto.PoseidonArray(),
+		crypto.PoseidonArray(),
+		crypto.PoseidonArray(compiled.Bytecode...),
+	)
+	hash, err := compiled.Hash()
+	require.NoError(t, err)
+	assert.Equal(t, expected, hash)
+
+	t.Run("order of entry points matters", func(t *testing.T) {
+		swapped := *compiled
+		swapped.External, swapped.L1Handler = swapped.L1Handler, swapped.External
+		swappedHash, err := swapped.Hash()
+		require.NoError(t, err)
+		assert.NotEqual(t, hash, swappedHash)
+	})
+	t.Run("bytecode split into segments", func(t *testing.T) {
+		bytecode := make([]*felt.Felt, 6)
+		for i := range bytecode {
+			bytecode[i] = new(felt.Felt).SetUint64(uint64(i + 1))
+		}
+		segmented := *compiled
+		segmented.Bytecode = bytecode
+		// [2, [1, 2], 1]
+		segmented.BytecodeSegmentLengths = &core.SegmentLengths{Children: []core.SegmentLengths{
+			{Length: 2},
+			{Children: []core.SegmentLengths{{Length: 1}, {Length: 2}}},
+			{Length: 1},
+		}}
+		total, ok := segmented.BytecodeSegmentLengths.Total()
+		require.True(t, ok)
+		assert.Equal(t, uint64(6), total)
+
+		one := new(felt.Felt).SetUint64(1)
+		plusOne := func(x *felt.Felt) *felt.Felt {
+			return new(felt.Felt).Add(x, one)
+		}
+		length := func(l uint64) *felt.Felt {
+			return new(felt.Felt).SetUint64(l)
+		}
+		nested := plusOne(crypto.PoseidonArray(
+			length(1), crypto.PoseidonArray(bytecode[2]),
+			length(2), crypto.PoseidonArray(bytecode[3:5]...),
+		))
+		bytecodeHash := plusOne(crypto.PoseidonArray(
+			length(2), crypto.PoseidonArray(bytecode[:2]...),
+			length(3), nested,
+			length(1), crypto.PoseidonArray(bytecode[5]),
+		))
+		expected := crypto.PoseidonArray(
+			new(felt.Felt).SetBytes([]byte("COMPILED_CLASS_V1")),
+			crypto.PoseidonArray(selector, new(felt.Felt).SetUint64(1), builtinsHash),
+			crypto.PoseidonArray(),
+			crypto.PoseidonArray(),
+			bytecodeHash,
+		)
+		segmentedHash, err := segmented.Hash()
+		require.NoError(t, err)
+		assert.Equal(t, expected, segmentedHash)
+
+		flat := segmented
+		flat.BytecodeSegmentLengths = nil
+		flatHash, err := flat.Hash()
+		require.NoError(t, err)
+		assert.NotEqual(t, flatHash, segmentedHash)
+	})
+	t.Run("segments out of the bytecode bounds", func(t *testing.T) {
+		tests := map[string]*core.SegmentLengths{
+			"too long":   {Children: []core.SegmentLengths{{Length: 1}, {Length: 2}}},
+			"too short":  {Children: []core.SegmentLengths{{Length: 1}}},
+			"overflowed": {Children: []core.SegmentLengths{{Length: ^uint64(0)}, {Length: 3}}},
+		}
+		for name, lengths := range tests {
+			lengths := lengths
+			t.Run(name, func(t *testing.T) {
+				segmented := *compiled
+				segmented.BytecodeSegmentLengths = lengths
+				_, err := segmented.Hash()
+				assert.EqualError(t, err, "bytecode segments do not cover the 2 bytecode words")
+			})
+		}
+	})
+}
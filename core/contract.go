@@ -211,5 +211,5 @@ func storage(addr *felt.Felt, txn db.Transaction) (*trie.Trie, error) {
 		return nil, err
 	}
 	trieTxn := NewTransactionStorage(txn, db.ContractStorage.Key(addrBytes))
-	return trie.NewTriePedersen(trieTxn, contractStorageTrieHeight, contractRootKey)
+	return contractStorageTrieConfig.New(trieTxn, contractRootKey)
 }
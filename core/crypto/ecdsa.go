@@ -0,0 +1,205 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+
+	"github.com/NethermindEth/juno/core/felt"
+	starkcurve "github.com/consensys/gnark-crypto/ecc/stark-curve"
+	"github.com/consensys/gnark-crypto/ecc/stark-curve/fp"
+	"github.com/consensys/gnark-crypto/ecc/stark-curve/fr"
+)
+
+// ecdsaBits is the bit length bound of message hashes, r and w in [Stark ECDSA].
+//
+// [Stark ECDSA]: https://docs.starkware.co/starkex/crypto/signatures.html
+const ecdsaBits = 251
+
+var (
+	ErrInvalidPrivateKey = errors.New("private key is not in the range [1, curve order)")
+	ErrInvalidMessage    = errors.New("message hash is not smaller than 2^251")
+
+	curveOrder   = fr.Modulus()
+	ecdsaBound   = new(big.Int).Lsh(big.NewInt(1), ecdsaBits)
+	curveBeta, _ = new(fp.Element).SetString(
+		"3141592653589793238462643383279502884197169399375105820974944592307816406665")
+)
+
+// PublicKey returns the x coordinate of the Stark curve point that corresponds to
+// the given private key. Starknet accounts store and sign with this coordinate only.
+func PublicKey(privateKey *felt.Felt) (*felt.Felt, error) {
+	priv := privateKey.BigInt(new(big.Int))
+	if priv.Sign() == 0 || priv.Cmp(curveOrder) >= 0 {
+		return nil, ErrInvalidPrivateKey
+	}
+	var point starkcurve.G1Affine
+	point.ScalarMultiplicationBase(priv)
+	return felt.NewFelt(&point.X), nil
+}
+
+// Sign signs msgHash with [Stark ECDSA]. The nonce is derived deterministically from the
+// private key and the message as described in [RFC6979], so the same inputs always result
+// in the same signature.
+//
+// [Stark ECDSA]: https://docs.starkware.co/starkex/crypto/signatures.html
+// [RFC6979]: https://www.rfc-editor.org/rfc/rfc6979
+func Sign(msgHash, privateKey *felt.Felt) (r, s *felt.Felt, err error) {
+	msg := msgHash.BigInt(new(big.Int))
+	if msg.Cmp(ecdsaBound) >= 0 {
+		return nil, nil, ErrInvalidMessage
+	}
+	priv := privateKey.BigInt(new(big.Int))
+	if priv.Sign() == 0 || priv.Cmp(curveOrder) >= 0 {
+		return nil, nil, ErrInvalidPrivateKey
+	}
+
+	var point starkcurve.G1Affine
+	for seed := int64(0); ; seed++ {
+		k := rfc6979Nonce(msg, priv, seed)
+
+		point.ScalarMultiplicationBase(k)
+		rInt := point.X.BigInt(new(big.Int))
+		if rInt.Sign() == 0 || rInt.Cmp(ecdsaBound) >= 0 {
+			continue
+		}
+
+		// w = k / (msg + r * priv) mod n
+		denominator := new(big.Int).Mul(rInt, priv)
+		denominator.Add(denominator, msg).Mod(denominator, curveOrder)
+		if denominator.Sign() == 0 {
+			continue
+		}
+		w := new(big.Int).ModInverse(denominator, curveOrder)
+		w.Mul(w, k).Mod(w, curveOrder)
+		if w.Sign() == 0 || w.Cmp(ecdsaBound) >= 0 {
+			continue
+		}
+
+		sInt := w.ModInverse(w, curveOrder)
+		return new(felt.Felt).SetBytes(rInt.Bytes()), new(felt.Felt).SetBytes(sInt.Bytes()), nil
+	}
+}
+
+// Verify checks that (r, s) is a valid [Stark ECDSA] signature of msgHash by the owner
+// of the public key. As only the x coordinate of the public key is known, the signature
+// is accepted for either of the two points with that coordinate.
+//
+// [Stark ECDSA]: https://docs.starkware.co/starkex/crypto/signatures.html
+func Verify(msgHash, r, s, publicKey *felt.Felt) bool {
+	msg := msgHash.BigInt(new(big.Int))
+	rInt := r.BigInt(new(big.Int))
+	sInt := s.BigInt(new(big.Int))
+	if msg.Cmp(ecdsaBound) >= 0 ||
+		rInt.Sign() == 0 || rInt.Cmp(ecdsaBound) >= 0 ||
+		sInt.Sign() == 0 || sInt.Cmp(curveOrder) >= 0 {
+		return false
+	}
+
+	w := new(big.Int).ModInverse(sInt, curveOrder)
+	if w == nil || w.Cmp(ecdsaBound) >= 0 {
+		return false
+	}
+
+	key, ok := pointFromX(publicKey.Impl())
+	if !ok {
+		return false
+	}
+
+	u1 := new(big.Int).Mul(msg, w)
+	u1.Mod(u1, curveOrder)
+	u2 := new(big.Int).Mul(rInt, w)
+	u2.Mod(u2, curveOrder)
+
+	for _, candidate := range []*big.Int{u2, new(big.Int).Neg(u2)} {
+		var jac starkcurve.G1Jac
+		var point starkcurve.G1Affine
+		point.FromJacobian(jac.JointScalarMultiplicationBase(key, u1, candidate))
+		if !point.IsInfinity() && point.X.Equal(r.Impl()) {
+			return true
+		}
+	}
+	return false
+}
+
+// pointFromX returns a Stark curve point with the given x coordinate, if there is one.
+func pointFromX(x *fp.Element) (*starkcurve.G1Affine, bool) {
+	// y^2 = x^3 + x + beta
+	var ySquared fp.Element
+	ySquared.Square(x).Mul(&ySquared, x).Add(&ySquared, x).Add(&ySquared, curveBeta)
+
+	point := &starkcurve.G1Affine{X: *x}
+	if point.Y.Sqrt(&ySquared) == nil {
+		return nil, false
+	}
+	return point, true
+}
+
+// rfc6979Nonce generates the nonce for a signature the same way StarkWare's reference
+// implementation does: [RFC6979] with SHA-256, where the message is pre-shifted if its
+// length would otherwise make it lose significant bits and seed is used as additional
+// entropy to get a different nonce when a previous one could not be used.
+//
+// [RFC6979]: https://www.rfc-editor.org/rfc/rfc6979#section-3.2
+func rfc6979Nonce(msg, priv *big.Int, seed int64) *big.Int {
+	const qlen = 252
+	const rolen = (qlen + 7) / 8
+
+	if bitLen := msg.BitLen(); bitLen >= 248 && bitLen%8 >= 1 && bitLen%8 <= 4 {
+		msg = new(big.Int).Lsh(msg, 4)
+	}
+
+	bitsToInt := func(b []byte) *big.Int {
+		x := new(big.Int).SetBytes(b)
+		if l := len(b) * 8; l > qlen {
+			x.Rsh(x, uint(l-qlen))
+		}
+		return x
+	}
+
+	z := bitsToInt(msg.Bytes())
+	if z.Cmp(curveOrder) >= 0 {
+		z.Sub(z, curveOrder)
+	}
+
+	bx := priv.FillBytes(make([]byte, rolen))
+	bx = append(bx, z.FillBytes(make([]byte, rolen))...)
+	if seed > 0 {
+		bx = append(bx, big.NewInt(seed).Bytes()...)
+	}
+
+	mac := func(key []byte, data ...[]byte) []byte {
+		h := hmac.New(sha256.New, key)
+		for _, d := range data {
+			h.Write(d)
+		}
+		return h.Sum(nil)
+	}
+
+	v := make([]byte, sha256.Size)
+	for i := range v {
+		v[i] = 0x01
+	}
+	k := make([]byte, sha256.Size)
+
+	k = mac(k, v, []byte{0x00}, bx)
+	v = mac(k, v)
+	k = mac(k, v, []byte{0x01}, bx)
+	v = mac(k, v)
+
+	for {
+		var t []byte
+		for len(t) < rolen {
+			v = mac(k, v)
+			t = append(t, v...)
+		}
+
+		nonce := bitsToInt(t[:rolen])
+		if nonce.Sign() > 0 && nonce.Cmp(curveOrder) < 0 {
+			return nonce
+		}
+		k = mac(k, v, []byte{0x00})
+		v = mac(k, v)
+	}
+}
@@ -0,0 +1,124 @@
+package crypto_test
+
+import (
+	"testing"
+
+	"github.com/NethermindEth/juno/core/crypto"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicKey(t *testing.T) {
+	// test vector from StarkWare's reference implementation
+	privateKey := utils.HexToFelt(t, "0x3c1e9550e66958296d11b60f8e8e7a7ad990d07fa65d5f7652c4a6c87d4e3cc")
+	publicKey, err := crypto.PublicKey(privateKey)
+	require.NoError(t, err)
+	assert.Equal(t, utils.HexToFelt(t, "0x77a3b314db07c45076d11f62b6f9e748a39790441823307743cf00d6597ea43"), publicKey)
+
+	t.Run("starknet.js", func(t *testing.T) {
+		publicKey, err := crypto.PublicKey(utils.HexToFelt(t, "0x19800ea6a9a73f94aee6a3d2edf018fc770443e90c7ba121e8303ec6b349279"))
+		require.NoError(t, err)
+		assert.Equal(t, utils.HexToFelt(t, "0x33f45f07e1bd1a51b45fc24ec8c8c9908db9e42191be9e169bfcac0c0d99745"), publicKey)
+	})
+
+	t.Run("zero private key", func(t *testing.T) {
+		_, err := crypto.PublicKey(new(felt.Felt))
+		assert.ErrorIs(t, err, crypto.ErrInvalidPrivateKey)
+	})
+}
+
+func TestSign(t *testing.T) {
+	privateKey := utils.HexToFelt(t, "0x3c1e9550e66958296d11b60f8e8e7a7ad990d07fa65d5f7652c4a6c87d4e3cc")
+	msgHash := utils.HexToFelt(t, "0x397e76d1667c4454bfb83514e120583af836f8e32a516765497823eb85a5c84")
+
+	r, s, err := crypto.Sign(msgHash, privateKey)
+	require.NoError(t, err)
+
+	publicKey, err := crypto.PublicKey(privateKey)
+	require.NoError(t, err)
+	assert.True(t, crypto.Verify(msgHash, r, s, publicKey))
+
+	t.Run("signatures are deterministic", func(t *testing.T) {
+		r2, s2, err := crypto.Sign(msgHash, privateKey)
+		require.NoError(t, err)
+		assert.Equal(t, r, r2)
+		assert.Equal(t, s, s2)
+	})
+
+	// known answers from StarkWare's RFC6979 test vectors and starknet.js
+	t.Run("compatible with the reference implementations", func(t *testing.T) {
+		tests := []struct {
+			privateKey, msgHash, r, s string
+		}{
+			{
+				privateKey: "0x3c1e9550e66958296d11b60f8e8e7a7ad990d07fa65d5f7652c4a6c87d4e3cc",
+				msgHash:    "0x1",
+				r:          "3162358736122783857144396205516927012128897537504463716197279730251407200037",
+				s:          "1447067116407676619871126378936374427636662490882969509559888874644844560850",
+			},
+			{
+				privateKey: "0x3c1e9550e66958296d11b60f8e8e7a7ad990d07fa65d5f7652c4a6c87d4e3cc",
+				msgHash:    "0x11",
+				r:          "2282960348362869237018441985726545922711140064809058182483721438101695251648",
+				s:          "2905868291002627709651322791912000820756370440695830310841564989426104902684",
+			},
+			{
+				privateKey: "0x3c1e9550e66958296d11b60f8e8e7a7ad990d07fa65d5f7652c4a6c87d4e3cc",
+				msgHash:    "0x223",
+				r:          "2851492577225522862152785068304516872062840835882746625971400995051610132955",
+				s:          "2227464623243182122770469099770977514100002325017609907274766387592987135410",
+			},
+			{
+				privateKey: "0x3c1e9550e66958296d11b60f8e8e7a7ad990d07fa65d5f7652c4a6c87d4e3cc",
+				msgHash:    "0x9999",
+				r:          "3551214266795401081823453828727326248401688527835302880992409448142527576296",
+				s:          "2580950807716503852408066180369610390914312729170066679103651110985466032285",
+			},
+			// a 251 bit message hash, which is padded before the nonce is derived
+			{
+				privateKey: "0x19800ea6a9a73f94aee6a3d2edf018fc770443e90c7ba121e8303ec6b349279",
+				msgHash:    "0x6d1706bd3d1ba7c517be2a2a335996f63d4738e2f182144d078a1dd9997062e",
+				r:          "1427981024487605678086498726488552139932400435436186597196374630267616399345",
+				s:          "1853664302719670721837677288395394946745467311923401353018029119631574115563",
+			},
+		}
+
+		for _, test := range tests {
+			r, s, err := crypto.Sign(utils.HexToFelt(t, test.msgHash), utils.HexToFelt(t, test.privateKey))
+			require.NoError(t, err)
+			assert.Equal(t, test.r, r.Text(felt.Base10), test.msgHash)
+			assert.Equal(t, test.s, s.Text(felt.Base10), test.msgHash)
+		}
+	})
+
+	t.Run("message hash out of range", func(t *testing.T) {
+		_, _, err := crypto.Sign(utils.HexToFelt(t, "0x800000000000000000000000000000000000000000000000000000000000000"), privateKey)
+		assert.ErrorIs(t, err, crypto.ErrInvalidMessage)
+	})
+}
+
+func TestVerify(t *testing.T) {
+	privateKey := utils.HexToFelt(t, "0x1234567890987654321")
+	publicKey, err := crypto.PublicKey(privateKey)
+	require.NoError(t, err)
+
+	for _, msg := range []string{"0x0", "0x1", "0x2a", "0x7ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"} {
+		msgHash := utils.HexToFelt(t, msg)
+		r, s, err := crypto.Sign(msgHash, privateKey)
+		require.NoError(t, err)
+
+		t.Run(msg, func(t *testing.T) {
+			assert.True(t, crypto.Verify(msgHash, r, s, publicKey))
+
+			one := new(felt.Felt).SetUint64(1)
+			assert.False(t, crypto.Verify(new(felt.Felt).Add(msgHash, one), r, s, publicKey), "tampered message")
+			assert.False(t, crypto.Verify(msgHash, new(felt.Felt).Add(r, one), s, publicKey), "tampered r")
+			assert.False(t, crypto.Verify(msgHash, r, new(felt.Felt).Add(s, one), publicKey), "tampered s")
+			assert.False(t, crypto.Verify(msgHash, r, s, new(felt.Felt).Add(publicKey, one)), "wrong public key")
+			assert.False(t, crypto.Verify(msgHash, &felt.Zero, s, publicKey), "zero r")
+			assert.False(t, crypto.Verify(msgHash, r, &felt.Zero, publicKey), "zero s")
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/NethermindEth/juno/core/felt"
+)
+
+// LatestProtocolVersion is the newest Starknet protocol version whose blocks can be validated.
+var LatestProtocolVersion = semver.MustParse("0.13.2")
+
+// protocolRules are the rules that the blocks of a range of protocol versions follow.
+type protocolRules struct {
+	// since is the first protocol version the rules apply to.
+	since *semver.Version
+	// poseidonBlockHash is set if block hashes are Poseidon hashes that also commit to the
+	// state diff, the receipts and the gas prices. Otherwise, they are Pedersen hashes, see
+	// pre07Hash and post07Hash.
+	poseidonBlockHash bool
+	// allSignatures is set if the transaction commitment includes the signatures of all
+	// transactions. Otherwise, only the signatures of invoke transactions are included.
+	allSignatures bool
+	// maxTransactionVersion is the highest transaction version that blocks may include.
+	maxTransactionVersion uint64
+	// revertedTransactions is set if blocks may include reverted transactions.
+	revertedTransactions bool
+	// gasPriceFri is set if blocks report the price of L1 gas in fri next to the price in wei.
+	gasPriceFri bool
+	// l1DataGasPrice is set if blocks report the price of L1 data gas.
+	l1DataGasPrice bool
+}
+
+// protocolVersions is the compatibility table of the protocol versions, ordered by version.
+//
+//	version | block hash | tx commitment signatures | tx versions | reverts | gas prices
+//	--------+------------+--------------------------+-------------+---------+-----------------
+//	< 0.11  | Pedersen   | invoke                   | 0-1         | no      | wei
+//	0.11.0  | Pedersen   | invoke                   | 0-2         | no      | wei
+//	0.11.1  | Pedersen   | all                      | 0-2         | no      | wei
+//	0.12.1  | Pedersen   | all                      | 0-2         | yes     | wei
+//	0.13.0  | Pedersen   | all                      | 0-3         | yes     | wei, fri
+//	0.13.1  | Pedersen   | all                      | 0-3         | yes     | wei, fri, data gas
+//	0.13.2  | Poseidon   | all                      | 0-3         | yes     | wei, fri, data gas
+//
+// Blocks from before the protocol version was reported follow the rules of the first entry.
+var protocolVersions = []protocolRules{
+	{
+		since:                 semver.MustParse("0.0.0"),
+		maxTransactionVersion: 1,
+	},
+	{
+		since:                 semver.MustParse("0.11.0"),
+		maxTransactionVersion: 2,
+	},
+	{
+		since:                 semver.MustParse("0.11.1"),
+		allSignatures:         true,
+		maxTransactionVersion: 2,
+	},
+	{
+		since:                 semver.MustParse("0.12.1"),
+		allSignatures:         true,
+		maxTransactionVersion: 2,
+		revertedTransactions:  true,
+	},
+	{
+		since:                 semver.MustParse("0.13.0"),
+		allSignatures:         true,
+		maxTransactionVersion: 3,
+		revertedTransactions:  true,
+		gasPriceFri:           true,
+	},
+	{
+		since:                 semver.MustParse("0.13.1"),
+		allSignatures:         true,
+		maxTransactionVersion: 3,
+		revertedTransactions:  true,
+		gasPriceFri:           true,
+		l1DataGasPrice:        true,
+	},
+	{
+		since:                 semver.MustParse("0.13.2"),
+		poseidonBlockHash:     true,
+		allSignatures:         true,
+		maxTransactionVersion: 3,
+		revertedTransactions:  true,
+		gasPriceFri:           true,
+		l1DataGasPrice:        true,
+	},
+}
+
+// ParseProtocolVersion parses a Starknet protocol version such as 0.13.2.1. Only the first three
+// components are kept since later ones never change the rules. The empty version of blocks from
+// before the protocol version was reported is parsed as 0.0.0.
+func ParseProtocolVersion(version string) (*semver.Version, error) {
+	if version == "" {
+		return semver.MustParse("0.0.0"), nil
+	}
+
+	components := strings.Split(version, ".")
+	if len(components) > 3 {
+		components = components[:3]
+	}
+	return semver.NewVersion(strings.Join(components, "."))
+}
+
+// CheckProtocolVersion returns an error if the blocks of the given protocol version can't be validated.
+func CheckProtocolVersion(version string) error {
+	_, err := rulesFor(version)
+	return err
+}
+
+// rulesFor returns the rules of the given protocol version. Versions newer than
+// [LatestProtocolVersion] are rejected since their rules are not known.
+func rulesFor(version string) (*protocolRules, error) {
+	v, err := ParseProtocolVersion(version)
+	if err != nil {
+		return nil, fmt.Errorf("invalid protocol version %q: %w", version, err)
+	}
+	if v.GreaterThan(LatestProtocolVersion) {
+		return nil, fmt.Errorf("unsupported protocol version %s, the latest supported version is %s",
+			version, LatestProtocolVersion)
+	}
+
+	for i := len(protocolVersions) - 1; i > 0; i-- {
+		if !v.LessThan(protocolVersions[i].since) {
+			return &protocolVersions[i], nil
+		}
+	}
+	return &protocolVersions[0], nil
+}
+
+// verifyHeader checks that the header reports the gas prices required by the rules.
+func (r *protocolRules) verifyHeader(h *Header) error {
+	if r.gasPriceFri && h.GasPriceSTRK == nil {
+		return fmt.Errorf("block %d of protocol version %s has no gas price in fri", h.Number, h.ProtocolVersion)
+	}
+	if r.l1DataGasPrice && (h.L1DataGasPrice == nil || h.L1DataGasPrice.PriceInWei == nil ||
+		h.L1DataGasPrice.PriceInFri == nil) {
+		return fmt.Errorf("block %d of protocol version %s has no L1 data gas price", h.Number, h.ProtocolVersion)
+	}
+	return nil
+}
+
+// verifyTransactions checks that the versions of the transactions and the execution statuses
+// of their receipts are allowed by the rules.
+func (r *protocolRules) verifyTransactions(b *Block) error {
+	maxVersion := new(felt.Felt).SetUint64(r.maxTransactionVersion)
+	for _, tx := range b.Transactions {
+		if version := transactionVersion(tx); version != nil && version.Cmp(maxVersion) > 0 {
+			return fmt.Errorf("transaction %s has version %s, protocol version %s allows up to %d",
+				tx.Hash(), version.Text(felt.Base10), b.ProtocolVersion, r.maxTransactionVersion)
+		}
+	}
+	if !r.revertedTransactions {
+		for _, receipt := range b.Receipts {
+			if receipt.ExecutionStatus == Reverted {
+				return fmt.Errorf("transaction %s is reverted, protocol version %s does not allow reverted transactions",
+					receipt.TransactionHash, b.ProtocolVersion)
+			}
+		}
+	}
+	return nil
+}
+
+func transactionVersion(t Transaction) *felt.Felt {
+	switch tx := t.(type) {
+	case *DeployTransaction:
+		return tx.Version
+	case *DeployAccountTransaction:
+		return tx.Version
+	case *InvokeTransaction:
+		return tx.Version
+	case *DeclareTransaction:
+		return tx.Version
+	case *L1HandlerTransaction:
+		return tx.Version
+	default:
+		return nil
+	}
+}
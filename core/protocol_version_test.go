@@ -0,0 +1,93 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProtocolVersion(t *testing.T) {
+	tests := map[string]string{
+		"":         "0.0.0",
+		"0.9.1":    "0.9.1",
+		"0.11.0":   "0.11.0",
+		"0.13.2.1": "0.13.2",
+	}
+	for version, expected := range tests {
+		parsed, err := core.ParseProtocolVersion(version)
+		require.NoError(t, err, version)
+		assert.Equal(t, expected, parsed.String(), version)
+	}
+
+	_, err := core.ParseProtocolVersion("notasemver")
+	assert.Error(t, err)
+}
+
+func TestCheckProtocolVersion(t *testing.T) {
+	for _, version := range []string{"", "0.9.1", "0.11.0", "0.12.3", "0.13.1.1", "0.13.2", "0.13.2.1"} {
+		assert.NoError(t, core.CheckProtocolVersion(version), version)
+	}
+
+	assert.EqualError(t, core.CheckProtocolVersion("0.13.3"),
+		"unsupported protocol version 0.13.3, the latest supported version is 0.13.2")
+	assert.EqualError(t, core.CheckProtocolVersion("99.0.0"),
+		"unsupported protocol version 99.0.0, the latest supported version is 0.13.2")
+	assert.ErrorContains(t, core.CheckProtocolVersion("notasemver"), "invalid protocol version \"notasemver\"")
+}
+
+func TestProtocolVersionRules(t *testing.T) {
+	newBlock := func(version string) *core.Block {
+		tx := &core.InvokeTransaction{
+			TransactionHash: utils.HexToFelt(t, "0x1"),
+			Version:         new(felt.Felt).SetUint64(3),
+		}
+		return &core.Block{
+			Header: &core.Header{
+				Number:          7,
+				ProtocolVersion: version,
+			},
+			Transactions: []core.Transaction{tx},
+			Receipts:     []*core.TransactionReceipt{{TransactionHash: tx.TransactionHash}},
+		}
+	}
+
+	t.Run("transaction version is higher than allowed", func(t *testing.T) {
+		block := newBlock("0.12.3")
+		assert.EqualError(t, core.VerifyBlockHash(block, utils.MAINNET),
+			"transaction 0x1 has version 3, protocol version 0.12.3 allows up to 2")
+	})
+
+	t.Run("reverted transaction before 0.12.1", func(t *testing.T) {
+		block := newBlock("0.11.2")
+		block.Transactions[0].(*core.InvokeTransaction).Version = new(felt.Felt).SetUint64(1)
+		block.Receipts[0].ExecutionStatus = core.Reverted
+		assert.EqualError(t, core.VerifyBlockHash(block, utils.MAINNET),
+			"transaction 0x1 is reverted, protocol version 0.11.2 does not allow reverted transactions")
+	})
+
+	t.Run("gas price in fri is missing", func(t *testing.T) {
+		block := newBlock("0.13.0")
+		assert.EqualError(t, core.VerifyBlockHash(block, utils.MAINNET),
+			"block 7 of protocol version 0.13.0 has no gas price in fri")
+	})
+
+	t.Run("L1 data gas price is missing", func(t *testing.T) {
+		block := newBlock("0.13.1")
+		block.GasPriceSTRK = new(felt.Felt).SetUint64(1)
+		assert.EqualError(t, core.VerifyBlockHash(block, utils.MAINNET),
+			"block 7 of protocol version 0.13.1 has no L1 data gas price")
+
+		block.L1DataGasPrice = &core.GasPrice{PriceInWei: new(felt.Felt).SetUint64(1)}
+		assert.EqualError(t, core.VerifyBlockHash(block, utils.MAINNET),
+			"block 7 of protocol version 0.13.1 has no L1 data gas price")
+	})
+
+	t.Run("unsupported protocol version", func(t *testing.T) {
+		assert.EqualError(t, core.VerifyBlockHash(newBlock("0.14.0"), utils.MAINNET),
+			"unsupported protocol version 0.14.0, the latest supported version is 0.13.2")
+	})
+}
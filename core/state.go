@@ -1,6 +1,8 @@
 package core
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 
@@ -55,6 +57,15 @@ func (s *State) ContractNonce(addr *felt.Felt) (*felt.Felt, error) {
 	return contract.Nonce()
 }
 
+// ContractStorage returns the value stored at the given key of a contract's storage.
+func (s *State) ContractStorage(addr, key *felt.Felt) (*felt.Felt, error) {
+	contract, err := NewContract(addr, s.txn)
+	if err != nil {
+		return nil, err
+	}
+	return contract.Storage(key)
+}
+
 // Root returns the state commitment.
 func (s *State) Root() (*felt.Felt, error) {
 	var storageRoot, classesRoot *felt.Felt
@@ -94,14 +105,14 @@ func (s *State) Root() (*felt.Felt, error) {
 
 // storage returns a [core.Trie] that represents the Starknet global state in the given Txn context.
 func (s *State) storage() (*trie.Trie, func() error, error) {
-	return s.globalTrie(db.StateTrie, trie.NewTriePedersen)
+	return s.globalTrie(db.StateTrie, stateTrieConfig)
 }
 
 func (s *State) classesTrie() (*trie.Trie, func() error, error) {
-	return s.globalTrie(db.ClassesTrie, trie.NewTriePoseidon)
+	return s.globalTrie(db.ClassesTrie, classesTrieConfig)
 }
 
-func (s *State) globalTrie(bucket db.Bucket, newTrie trie.NewTrieFunc) (*trie.Trie, func() error, error) {
+func (s *State) globalTrie(bucket db.Bucket, config trie.Config) (*trie.Trie, func() error, error) {
 	dbPrefix := bucket.Key()
 	tTxn := NewTransactionStorage(s.txn, dbPrefix)
 
@@ -118,7 +129,7 @@ func (s *State) globalTrie(bucket db.Bucket, newTrie trie.NewTrieFunc) (*trie.Tr
 		return nil, nil, err
 	}
 
-	gTrie, err := newTrie(tTxn, globalTrieHeight, rootKey)
+	gTrie, err := config.New(tTxn, rootKey)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -165,6 +176,16 @@ func (s *State) Update(update *StateUpdate, declaredClasses map[felt.Felt]Class)
 		}
 	}
 
+	for _, declaredClass := range update.StateDiff.DeclaredV1Classes {
+		class, ok := declaredClasses[*declaredClass.ClassHash].(*Cairo1Class)
+		if !ok || class.Compiled == nil {
+			continue
+		}
+		if err = s.putCompiledClass(declaredClass.CompiledClassHash, class.Compiled); err != nil {
+			return err
+		}
+	}
+
 	if err = s.updateDeclaredClasses(update.StateDiff.DeclaredV1Classes); err != nil {
 		return err
 	}
@@ -182,6 +203,211 @@ func (s *State) Update(update *StateUpdate, declaredClasses map[felt.Felt]Class)
 	return nil
 }
 
+// StateReverseDiff holds what applying a [StateUpdate] overwrites, for [State.Revert] to restore
+// it. The contracts deployed by the update did not exist before it and are left out.
+type StateReverseDiff struct {
+	// StorageDiffs, Nonces and ReplacedClasses hold the values from before the update. Storage
+	// values that were not set are zero.
+	StorageDiffs    map[felt.Felt][]StorageDiff
+	Nonces          map[felt.Felt]*felt.Felt
+	ReplacedClasses []ReplacedClass
+	// StoredClasses and StoredCompiledClasses are the hashes of the classes and compiled classes
+	// that the update stored, as they were not stored before it.
+	StoredClasses         []*felt.Felt
+	StoredCompiledClasses []*felt.Felt
+}
+
+// ReverseDiff returns what applying update with the given declared classes overwrites. It must be
+// called before the update is applied.
+func (s *State) ReverseDiff(update *StateUpdate, declaredClasses map[felt.Felt]Class) (*StateReverseDiff, error) {
+	diff := update.StateDiff
+	deployed := make(map[felt.Felt]bool, len(diff.DeployedContracts))
+	for _, contract := range diff.DeployedContracts {
+		deployed[*contract.Address] = true
+	}
+
+	reverse := &StateReverseDiff{
+		StorageDiffs: make(map[felt.Felt][]StorageDiff, len(diff.StorageDiffs)),
+		Nonces:       make(map[felt.Felt]*felt.Felt, len(diff.Nonces)),
+	}
+	for addr, storageDiff := range diff.StorageDiffs {
+		addr := addr
+		if deployed[addr] {
+			continue
+		}
+		contract, err := NewContract(&addr, s.txn)
+		if err != nil {
+			return nil, err
+		}
+
+		oldValues := make([]StorageDiff, 0, len(storageDiff))
+		for _, pair := range storageDiff {
+			value, err := contract.Storage(pair.Key)
+			if errors.Is(err, db.ErrKeyNotFound) {
+				value = new(felt.Felt)
+			} else if err != nil {
+				return nil, err
+			}
+			oldValues = append(oldValues, StorageDiff{Key: pair.Key, Value: value})
+		}
+		reverse.StorageDiffs[addr] = oldValues
+	}
+
+	for addr := range diff.Nonces {
+		addr := addr
+		if deployed[addr] {
+			continue
+		}
+		nonce, err := s.ContractNonce(&addr)
+		if err != nil {
+			return nil, err
+		}
+		reverse.Nonces[addr] = nonce
+	}
+
+	for _, replaced := range diff.ReplacedClasses {
+		if deployed[*replaced.Address] {
+			continue
+		}
+		classHash, err := s.ContractClassHash(replaced.Address)
+		if err != nil {
+			return nil, err
+		}
+		reverse.ReplacedClasses = append(reverse.ReplacedClasses, ReplacedClass{Address: replaced.Address, ClassHash: classHash})
+	}
+
+	// mirrors the classes that Update stores
+	for classHash := range declaredClasses {
+		classHash := classHash
+		stored, err := s.has(db.Class.Key(classHash.Marshal()))
+		if err != nil {
+			return nil, err
+		} else if !stored {
+			reverse.StoredClasses = append(reverse.StoredClasses, &classHash)
+		}
+	}
+	for _, declaredClass := range diff.DeclaredV1Classes {
+		class, ok := declaredClasses[*declaredClass.ClassHash].(*Cairo1Class)
+		if !ok || class.Compiled == nil {
+			continue
+		}
+		stored, err := s.has(db.CompiledClass.Key(declaredClass.CompiledClassHash.Marshal()))
+		if err != nil {
+			return nil, err
+		} else if !stored {
+			reverse.StoredCompiledClasses = append(reverse.StoredCompiledClasses, declaredClass.CompiledClassHash)
+		}
+	}
+	return reverse, nil
+}
+
+// has reports whether key is stored.
+func (s *State) has(key []byte) (bool, error) {
+	err := s.txn.Get(key, func([]byte) error { return nil })
+	if errors.Is(err, db.ErrKeyNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Revert undoes update, which must be the last StateUpdate applied to the State, given what it
+// overwrote as returned by [State.ReverseDiff]. If update's new root does not match the state's
+// root before the revert, or its old root does not match the state's root after it, an error is
+// returned.
+func (s *State) Revert(update *StateUpdate, reverse *StateReverseDiff) error {
+	currentRoot, err := s.Root()
+	if err != nil {
+		return err
+	} else if !update.NewRoot.Equal(currentRoot) {
+		return fmt.Errorf("state's current root: %s does not match state update's new root: %s", currentRoot, update.NewRoot)
+	}
+
+	// restore the contracts that existed before the update
+	if err = s.updateContracts(&StateDiff{
+		StorageDiffs:    reverse.StorageDiffs,
+		Nonces:          reverse.Nonces,
+		ReplacedClasses: reverse.ReplacedClasses,
+	}); err != nil {
+		return err
+	}
+
+	for _, contract := range update.StateDiff.DeployedContracts {
+		if err = s.removeContract(contract.Address, update.StateDiff.StorageDiffs[*contract.Address]); err != nil {
+			return err
+		}
+	}
+
+	if err = s.removeDeclaredClasses(update.StateDiff.DeclaredV1Classes); err != nil {
+		return err
+	}
+	for _, classHash := range reverse.StoredClasses {
+		if err = s.txn.Delete(db.Class.Key(classHash.Marshal())); err != nil {
+			return err
+		}
+	}
+	for _, compiledClassHash := range reverse.StoredCompiledClasses {
+		if err = s.txn.Delete(db.CompiledClass.Key(compiledClassHash.Marshal())); err != nil {
+			return err
+		}
+	}
+
+	oldRoot, err := s.Root()
+	if err != nil {
+		return err
+	} else if !update.OldRoot.Equal(oldRoot) {
+		return fmt.Errorf("state's reverted root: %s does not match state update's old root: %s", oldRoot, update.OldRoot)
+	}
+	return nil
+}
+
+// removeContract removes a contract deployed by the last applied StateUpdate, whose storage is
+// therefore made of the given diff only, from the state.
+func (s *State) removeContract(addr *felt.Felt, storageDiff []StorageDiff) error {
+	contract, err := NewContract(addr, s.txn)
+	if err != nil {
+		return err
+	}
+
+	cleared := make([]StorageDiff, 0, len(storageDiff))
+	for _, pair := range storageDiff {
+		cleared = append(cleared, StorageDiff{Key: pair.Key, Value: new(felt.Felt)})
+	}
+	if err = contract.UpdateStorage(cleared); err != nil {
+		return err
+	}
+
+	if err = s.txn.Delete(db.ContractClassHash.Key(addr.Marshal())); err != nil {
+		return err
+	}
+	if err = s.txn.Delete(db.ContractNonce.Key(addr.Marshal())); err != nil {
+		return err
+	}
+
+	state, storageCloser, err := s.storage()
+	if err != nil {
+		return err
+	}
+	if _, err = state.Put(addr, new(felt.Felt)); err != nil {
+		return err
+	}
+	return storageCloser()
+}
+
+func (s *State) removeDeclaredClasses(declaredClasses []DeclaredV1Class) error {
+	classesTrie, classesCloser, err := s.classesTrie()
+	if err != nil {
+		return err
+	}
+
+	for _, declaredClass := range declaredClasses {
+		if _, err = classesTrie.Put(declaredClass.ClassHash, new(felt.Felt)); err != nil {
+			return err
+		}
+	}
+
+	return classesCloser()
+}
+
 func (s *State) updateContracts(diff *StateDiff) error {
 	// register deployed contracts
 	for _, contract := range diff.DeployedContracts {
@@ -229,23 +455,47 @@ func (s *State) replaceContract(addr, classHash *felt.Felt) error {
 }
 
 func (s *State) putClass(classHash *felt.Felt, class Class) error {
-	classKey := db.Class.Key(classHash.Marshal())
+	return s.putIfAbsent(db.Class.Key(classHash.Marshal()), class)
+}
+
+func (s *State) putCompiledClass(compiledClassHash *felt.Felt, class *CompiledClass) error {
+	return s.putIfAbsent(db.CompiledClass.Key(compiledClassHash.Marshal()), class)
+}
 
-	err := s.txn.Get(classKey, func(val []byte) error {
+// putIfAbsent encodes and stores value at key unless the key already exists.
+func (s *State) putIfAbsent(key []byte, value any) error {
+	err := s.txn.Get(key, func(val []byte) error {
 		return nil
 	})
 
 	if errors.Is(err, db.ErrKeyNotFound) {
-		classEncoded, encErr := encoder.Marshal(class)
+		valueEncoded, encErr := encoder.Marshal(value)
 		if encErr != nil {
 			return encErr
 		}
 
-		return s.txn.Set(classKey, classEncoded)
+		return s.txn.Set(key, valueEncoded)
 	}
 	return err
 }
 
+// Class returns the class with the given class hash.
+func (s *State) Class(classHash *felt.Felt) (Class, error) {
+	var class Class
+	return class, s.txn.Get(db.Class.Key(classHash.Marshal()), func(val []byte) error {
+		return encoder.Unmarshal(val, &class)
+	})
+}
+
+// CompiledClass returns the compiled class with the given compiled class hash.
+func (s *State) CompiledClass(compiledClassHash *felt.Felt) (*CompiledClass, error) {
+	var class *CompiledClass
+	return class, s.txn.Get(db.CompiledClass.Key(compiledClassHash.Marshal()), func(val []byte) error {
+		class = new(CompiledClass)
+		return encoder.Unmarshal(val, class)
+	})
+}
+
 // updateContractStorage applies the diff set to the Trie of the
 // contract at the given address in the given Txn context.
 func (s *State) updateContractStorage(addr *felt.Felt, diff []StorageDiff) error {
@@ -327,3 +577,160 @@ func (s *State) updateDeclaredClasses(declaredClasses []DeclaredV1Class) error {
 
 	return classesCloser()
 }
+
+// Verify rebuilds every contract storage trie, the global state trie and the classes trie
+// from their leaves in fresh in-memory tries and compares the results with the stored roots.
+// The first inconsistency found is returned as an error, otherwise the rebuilt state
+// commitment is returned.
+func (s *State) Verify(ctx context.Context) (*felt.Felt, error) {
+	sStorage, closer, err := s.storage()
+	if err != nil {
+		return nil, err
+	}
+
+	var storageRoot *felt.Felt
+	if err = stateTrieConfig.RunOnTemp(func(rebuilt *trie.Trie) error {
+		if err = s.verifyContracts(ctx, sStorage, rebuilt); err != nil {
+			return err
+		}
+		storageRoot, err = rebuilt.Root()
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	storedStorageRoot, err := sStorage.Root()
+	if err != nil {
+		return nil, err
+	}
+	if err = closer(); err != nil {
+		return nil, err
+	}
+
+	if !storageRoot.Equal(storedStorageRoot) {
+		return nil, fmt.Errorf("stored state trie root: %s does not match rebuilt root: %s", storedStorageRoot, storageRoot)
+	}
+
+	classes, closer, err := s.classesTrie()
+	if err != nil {
+		return nil, err
+	}
+
+	storedClassesRoot, err := classes.Root()
+	if err != nil {
+		return nil, err
+	}
+	if err = closer(); err != nil {
+		return nil, err
+	}
+
+	classesRoot, err := rebuildTrieRoot(classesTrieConfig, NewTransactionStorage(s.txn, db.ClassesTrie.Key()))
+	if err != nil {
+		return nil, err
+	}
+
+	if !classesRoot.Equal(storedClassesRoot) {
+		return nil, fmt.Errorf("stored classes trie root: %s does not match rebuilt root: %s", storedClassesRoot, classesRoot)
+	}
+
+	if classesRoot.IsZero() {
+		return storageRoot, nil
+	}
+	return crypto.PoseidonArray(stateVersion, storageRoot, classesRoot), nil
+}
+
+// verifyContracts checks the storage root and commitment of every deployed contract and
+// puts the recalculated commitments into the rebuilt global state trie.
+func (s *State) verifyContracts(ctx context.Context, stored, rebuilt *trie.Trie) error {
+	iterator, err := s.txn.NewIterator()
+	if err != nil {
+		return err
+	}
+
+	prefix := db.ContractClassHash.Key()
+	for iterator.Seek(prefix); iterator.Valid(); iterator.Next() {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return db.CloseAndWrapOnError(iterator.Close, ctxErr)
+		}
+
+		key := iterator.Key()
+		if !bytes.HasPrefix(key, prefix) {
+			break
+		}
+
+		addr := new(felt.Felt).SetBytes(key[len(prefix):])
+		commitment, err := s.verifyContract(addr)
+		if err != nil {
+			return db.CloseAndWrapOnError(iterator.Close, err)
+		}
+
+		storedCommitment, err := stored.Get(addr)
+		if err != nil {
+			return db.CloseAndWrapOnError(iterator.Close,
+				fmt.Errorf("contract %s: cannot get commitment from state trie: %w", addr, err))
+		}
+		if !commitment.Equal(storedCommitment) {
+			return db.CloseAndWrapOnError(iterator.Close,
+				fmt.Errorf("contract %s: commitment in state trie: %s does not match rebuilt commitment: %s",
+					addr, storedCommitment, commitment))
+		}
+
+		if _, err = rebuilt.Put(addr, commitment); err != nil {
+			return db.CloseAndWrapOnError(iterator.Close, err)
+		}
+	}
+
+	return iterator.Close()
+}
+
+// verifyContract rebuilds the storage trie of the contract at the given address, checks it
+// against the stored storage root and returns the recalculated contract commitment.
+func (s *State) verifyContract(addr *felt.Felt) (*felt.Felt, error) {
+	contract, err := NewContract(addr, s.txn)
+	if err != nil {
+		return nil, err
+	}
+
+	storedRoot, err := contract.Root()
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := rebuildTrieRoot(contractStorageTrieConfig, NewTransactionStorage(s.txn, db.ContractStorage.Key(addr.Marshal())))
+	if err != nil {
+		return nil, err
+	}
+
+	if !root.Equal(storedRoot) {
+		return nil, fmt.Errorf("contract %s: stored storage root: %s does not match rebuilt root: %s", addr, storedRoot, root)
+	}
+
+	cHash, err := contract.ClassHash()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := contract.Nonce()
+	if err != nil {
+		return nil, err
+	}
+
+	return calculateContractCommitment(root, cHash, nonce), nil
+}
+
+// rebuildTrieRoot puts every leaf found in storage into a fresh in-memory trie and returns its root.
+func rebuildTrieRoot(config trie.Config, storage *TransactionStorage) (*felt.Felt, error) {
+	var root *felt.Felt
+	return root, config.RunOnTemp(func(tempTrie *trie.Trie) error {
+		if err := storage.Leaves(config, func(key, value *felt.Felt) error {
+			_, err := tempTrie.Put(key, value)
+			return err
+		}); err != nil {
+			return err
+		}
+
+		var err error
+		root, err = tempTrie.Root()
+		return err
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/NethermindEth/juno/clients/feeder"
 	"github.com/NethermindEth/juno/core"
 	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db"
 	"github.com/NethermindEth/juno/db/pebble"
 	adaptfeeder "github.com/NethermindEth/juno/starknetdata/feeder"
 	"github.com/NethermindEth/juno/utils"
@@ -210,3 +211,222 @@ func TestNonce(t *testing.T) {
 		assert.Equal(t, expectedNonce, gotNonce)
 	})
 }
+
+func TestRevert(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+
+	gw := adaptfeeder.New(client)
+
+	testDB := pebble.NewMemTest()
+	txn := testDB.NewTransaction(true)
+	t.Cleanup(func() {
+		require.NoError(t, txn.Discard())
+	})
+
+	state := core.NewState(txn)
+
+	// update applies su and returns what it overwrote
+	update := func(t *testing.T, su *core.StateUpdate, declaredClasses map[felt.Felt]core.Class) *core.StateReverseDiff {
+		t.Helper()
+		reverse, err := state.ReverseDiff(su, declaredClasses)
+		require.NoError(t, err)
+		require.NoError(t, state.Update(su, declaredClasses))
+		return reverse
+	}
+	assertRoot := func(t *testing.T, expected *felt.Felt) {
+		t.Helper()
+		root, err := state.Root()
+		require.NoError(t, err)
+		assert.Equal(t, expected, root)
+	}
+
+	su0, err := gw.StateUpdate(context.Background(), 0)
+	require.NoError(t, err)
+	su1, err := gw.StateUpdate(context.Background(), 1)
+	require.NoError(t, err)
+	su2, err := gw.StateUpdate(context.Background(), 2)
+	require.NoError(t, err)
+
+	deployed := su0.StateDiff.DeployedContracts[0]
+	class, err := gw.Class(context.Background(), deployed.ClassHash)
+	require.NoError(t, err)
+
+	reverse0 := update(t, su0, map[felt.Felt]core.Class{*deployed.ClassHash: class})
+	reverse1 := update(t, su1, nil)
+
+	t.Run("error when state current root doesn't match state update's new root", func(t *testing.T) {
+		expectedErr := fmt.Sprintf("state's current root: %s does not match state update's new root: %s",
+			su1.NewRoot, su0.NewRoot)
+		require.EqualError(t, state.Revert(su0, reverse0), expectedErr)
+	})
+
+	t.Run("revert replaced class", func(t *testing.T) {
+		replaced := su1.StateDiff.DeployedContracts[0]
+		replaceUpdate := &core.StateUpdate{
+			OldRoot: su1.NewRoot,
+			NewRoot: utils.HexToFelt(t, "0x484ff378143158f9af55a1210b380853ae155dfdd8cd4c228f9ece918bb982b"),
+			StateDiff: &core.StateDiff{
+				ReplacedClasses: []core.ReplacedClass{{Address: replaced.Address, ClassHash: utils.HexToFelt(t, "0x1337")}},
+			},
+		}
+		reverse := update(t, replaceUpdate, nil)
+
+		require.NoError(t, state.Revert(replaceUpdate, reverse))
+		assertRoot(t, su1.NewRoot)
+		gotClassHash, err := state.ContractClassHash(replaced.Address)
+		require.NoError(t, err)
+		assert.Equal(t, replaced.ClassHash, gotClassHash)
+	})
+
+	reverse2 := update(t, su2, nil)
+
+	t.Run("revert declared class", func(t *testing.T) {
+		declareUpdate := &core.StateUpdate{
+			OldRoot: su2.NewRoot,
+			NewRoot: utils.HexToFelt(t, "0x46f1033cfb8e0b2e16e1ad6f95c41fd3a123f168fe72665452b6cddbc1d8e7a"),
+			StateDiff: &core.StateDiff{
+				DeclaredV1Classes: []core.DeclaredV1Class{
+					{
+						ClassHash:         utils.HexToFelt(t, "0xDEADBEEF"),
+						CompiledClassHash: utils.HexToFelt(t, "0xBEEFDEAD"),
+					},
+				},
+			},
+		}
+		reverse := update(t, declareUpdate, nil)
+
+		require.NoError(t, state.Revert(declareUpdate, reverse))
+		assertRoot(t, su2.NewRoot)
+	})
+
+	t.Run("revert storage and deployed contracts down to the empty state", func(t *testing.T) {
+		require.NoError(t, state.Revert(su2, reverse2))
+		assertRoot(t, su1.NewRoot)
+		require.NoError(t, state.Revert(su1, reverse1))
+		assertRoot(t, su0.NewRoot)
+		require.NoError(t, state.Revert(su0, reverse0))
+		assertRoot(t, &felt.Zero)
+
+		_, err := state.ContractClassHash(deployed.Address)
+		assert.ErrorIs(t, err, core.ErrContractNotDeployed)
+		_, err = state.Class(deployed.ClassHash)
+		assert.ErrorIs(t, err, db.ErrKeyNotFound)
+
+		// the reverted updates can be applied again
+		update(t, su0, nil)
+		update(t, su1, nil)
+		assertRoot(t, su1.NewRoot)
+	})
+
+	t.Run("revert nonce", func(t *testing.T) {
+		nonceTxn := pebble.NewMemTest().NewTransaction(true)
+		t.Cleanup(func() {
+			require.NoError(t, nonceTxn.Discard())
+		})
+		state = core.NewState(nonceTxn)
+
+		addr := utils.HexToFelt(t, "0x20cfa74ee3564b4cd5435cdace0f9c4d43b939620e4a0bb5076105df0a626c6")
+		deployUpdate := &core.StateUpdate{
+			OldRoot: &felt.Zero,
+			NewRoot: utils.HexToFelt(t, "0x4bdef7bf8b81a868aeab4b48ef952415fe105ab479e2f7bc671c92173542368"),
+			StateDiff: &core.StateDiff{
+				DeployedContracts: []core.DeployedContract{{
+					Address:   addr,
+					ClassHash: utils.HexToFelt(t, "0x10455c752b86932ce552f2b0fe81a880746649b9aee7e0d842bf3f52378f9f8"),
+				}},
+			},
+		}
+		nonceUpdate := &core.StateUpdate{
+			OldRoot: deployUpdate.NewRoot,
+			NewRoot: utils.HexToFelt(t, "0x6210642ffd49f64617fc9e5c0bbe53a6a92769e2996eb312a42d2bdb7f2afc1"),
+			StateDiff: &core.StateDiff{
+				Nonces: map[felt.Felt]*felt.Felt{*addr: new(felt.Felt).SetUint64(1)},
+			},
+		}
+		update(t, deployUpdate, nil)
+		reverse := update(t, nonceUpdate, nil)
+
+		require.NoError(t, state.Revert(nonceUpdate, reverse))
+		assertRoot(t, deployUpdate.NewRoot)
+		nonce, err := state.ContractNonce(addr)
+		require.NoError(t, err)
+		assert.Equal(t, &felt.Zero, nonce)
+	})
+}
+
+func TestVerify(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+
+	gw := adaptfeeder.New(client)
+
+	testDB := pebble.NewMemTest()
+	txn := testDB.NewTransaction(true)
+	t.Cleanup(func() {
+		require.NoError(t, txn.Discard())
+	})
+
+	state := core.NewState(txn)
+
+	t.Run("empty state", func(t *testing.T) {
+		root, err := state.Verify(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, &felt.Zero, root)
+	})
+
+	su0, err := gw.StateUpdate(context.Background(), 0)
+	require.NoError(t, err)
+	require.NoError(t, state.Update(su0, nil))
+
+	su1, err := gw.StateUpdate(context.Background(), 1)
+	require.NoError(t, err)
+	require.NoError(t, state.Update(su1, nil))
+
+	su2, err := gw.StateUpdate(context.Background(), 2)
+	require.NoError(t, err)
+	require.NoError(t, state.Update(su2, nil))
+
+	t.Run("consistent state", func(t *testing.T) {
+		root, err := state.Verify(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, su2.NewRoot, root)
+	})
+
+	t.Run("consistent state with declared classes", func(t *testing.T) {
+		su := &core.StateUpdate{
+			OldRoot: su2.NewRoot,
+			NewRoot: utils.HexToFelt(t, "0x46f1033cfb8e0b2e16e1ad6f95c41fd3a123f168fe72665452b6cddbc1d8e7a"),
+			StateDiff: &core.StateDiff{
+				DeclaredV1Classes: []core.DeclaredV1Class{
+					{
+						ClassHash:         utils.HexToFelt(t, "0xDEADBEEF"),
+						CompiledClassHash: utils.HexToFelt(t, "0xBEEFDEAD"),
+					},
+				},
+			},
+		}
+		require.NoError(t, state.Update(su, nil))
+
+		root, err := state.Verify(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, su.NewRoot, root)
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := state.Verify(ctx)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("corrupted contract nonce", func(t *testing.T) {
+		addr := su0.StateDiff.DeployedContracts[0].Address
+		require.NoError(t, txn.Set(db.ContractNonce.Key(addr.Marshal()), new(felt.Felt).SetUint64(42).Marshal()))
+
+		_, err := state.Verify(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "contract "+addr.String())
+	})
+}
@@ -1,6 +1,11 @@
 package core
 
-import "github.com/NethermindEth/juno/core/felt"
+import (
+	"sort"
+
+	"github.com/NethermindEth/juno/core/crypto"
+	"github.com/NethermindEth/juno/core/felt"
+)
 
 type StateUpdate struct {
 	BlockHash *felt.Felt
@@ -37,3 +42,194 @@ type ReplacedClass struct {
 	Address   *felt.Felt
 	ClassHash *felt.Felt
 }
+
+// stateDiffCommitmentPrefix is the first element of the state diff commitment, "STARKNET_STATE_DIFF0"
+// as a short string.
+var stateDiffCommitmentPrefix = new(felt.Felt).SetBytes([]byte("STARKNET_STATE_DIFF0"))
+
+// Length returns the number of changes in the state diff.
+func (d *StateDiff) Length() uint64 {
+	length := len(d.Nonces) + len(d.DeployedContracts) + len(d.DeclaredV0Classes) +
+		len(d.DeclaredV1Classes) + len(d.ReplacedClasses)
+	for _, storageDiffs := range d.StorageDiffs {
+		length += len(storageDiffs)
+	}
+	return uint64(length)
+}
+
+// Commitment computes the Poseidon hash that commits to the state diff. The changes are hashed in
+// canonical order, so that the commitment does not depend on the order in which the diff lists them:
+//
+//	[STARKNET_STATE_DIFF0,
+//	 number of deployed and replaced contracts, address, class hash, ... sorted by address,
+//	 number of declared classes, class hash, compiled class hash, ... sorted by class hash,
+//	 number of declared Cairo 0 classes, class hash, ... sorted,
+//	 1, 0 (the only data availability mode, L1),
+//	 number of contracts with storage diffs, address, number of diffs, key, value, ...
+//	   sorted by address and key,
+//	 number of nonces, address, nonce, ... sorted by address]
+func (d *StateDiff) Commitment() *felt.Felt {
+	length := func(n int) *felt.Felt {
+		return new(felt.Felt).SetUint64(uint64(n))
+	}
+	elems := []*felt.Felt{stateDiffCommitmentPrefix}
+
+	updatedContracts := make(map[felt.Felt]*felt.Felt, len(d.DeployedContracts)+len(d.ReplacedClasses))
+	for _, deployedContract := range d.DeployedContracts {
+		updatedContracts[*deployedContract.Address] = deployedContract.ClassHash
+	}
+	for _, replacedClass := range d.ReplacedClasses {
+		updatedContracts[*replacedClass.Address] = replacedClass.ClassHash
+	}
+	elems = append(elems, length(len(updatedContracts)))
+	for _, addr := range sortedKeys(updatedContracts) {
+		elems = append(elems, addr, updatedContracts[*addr])
+	}
+
+	declaredV1Classes := append([]DeclaredV1Class{}, d.DeclaredV1Classes...)
+	sort.Slice(declaredV1Classes, func(i, j int) bool {
+		return declaredV1Classes[i].ClassHash.Cmp(declaredV1Classes[j].ClassHash) < 0
+	})
+	elems = append(elems, length(len(declaredV1Classes)))
+	for _, declaredClass := range declaredV1Classes {
+		elems = append(elems, declaredClass.ClassHash, declaredClass.CompiledClassHash)
+	}
+
+	declaredV0Classes := append([]*felt.Felt{}, d.DeclaredV0Classes...)
+	sortFelts(declaredV0Classes)
+	elems = append(elems, length(len(declaredV0Classes)))
+	elems = append(elems, declaredV0Classes...)
+
+	elems = append(elems, length(1), length(0))
+
+	elems = append(elems, length(len(d.StorageDiffs)))
+	for _, addr := range sortedKeys(d.StorageDiffs) {
+		storageDiffs := append([]StorageDiff{}, d.StorageDiffs[*addr]...)
+		sort.Slice(storageDiffs, func(i, j int) bool {
+			return storageDiffs[i].Key.Cmp(storageDiffs[j].Key) < 0
+		})
+		elems = append(elems, addr, length(len(storageDiffs)))
+		for _, storageDiff := range storageDiffs {
+			elems = append(elems, storageDiff.Key, storageDiff.Value)
+		}
+	}
+
+	elems = append(elems, length(len(d.Nonces)))
+	for _, addr := range sortedKeys(d.Nonces) {
+		elems = append(elems, addr, d.Nonces[*addr])
+	}
+
+	return crypto.PoseidonArray(elems...)
+}
+
+func sortedKeys[V any](m map[felt.Felt]V) []*felt.Felt {
+	keys := make([]*felt.Felt, 0, len(m))
+	for key := range m {
+		key := key
+		keys = append(keys, &key)
+	}
+	sortFelts(keys)
+	return keys
+}
+
+func sortFelts(felts []*felt.Felt) {
+	sort.Slice(felts, func(i, j int) bool {
+		return felts[i].Cmp(felts[j]) < 0
+	})
+}
+
+// StateDiffAggregator folds consecutive state diffs into a single diff that has the same effect
+// on the state as applying all of them in order.
+type StateDiffAggregator struct {
+	diff *StateDiff
+
+	storageIndices  map[felt.Felt]map[felt.Felt]int
+	deployedIndices map[felt.Felt]int
+	declaredV0      map[felt.Felt]struct{}
+	declaredV1      map[felt.Felt]struct{}
+	replacedIndices map[felt.Felt]int
+}
+
+func NewStateDiffAggregator() *StateDiffAggregator {
+	return &StateDiffAggregator{
+		diff: &StateDiff{
+			StorageDiffs:      make(map[felt.Felt][]StorageDiff),
+			Nonces:            make(map[felt.Felt]*felt.Felt),
+			DeployedContracts: []DeployedContract{},
+			DeclaredV0Classes: []*felt.Felt{},
+			DeclaredV1Classes: []DeclaredV1Class{},
+			ReplacedClasses:   []ReplacedClass{},
+		},
+		storageIndices:  make(map[felt.Felt]map[felt.Felt]int),
+		deployedIndices: make(map[felt.Felt]int),
+		declaredV0:      make(map[felt.Felt]struct{}),
+		declaredV1:      make(map[felt.Felt]struct{}),
+		replacedIndices: make(map[felt.Felt]int),
+	}
+}
+
+// Add folds diff into the aggregated diff. diff must come after all the previously added diffs.
+//
+// Later storage values and nonces override earlier ones. A class replacement of a contract that was
+// deployed in one of the aggregated diffs changes the class hash of the deployment instead of being
+// recorded as a replacement.
+func (a *StateDiffAggregator) Add(diff *StateDiff) {
+	for addr, storageDiffs := range diff.StorageDiffs {
+		indices, found := a.storageIndices[addr]
+		if !found {
+			indices = make(map[felt.Felt]int)
+			a.storageIndices[addr] = indices
+		}
+
+		for _, storageDiff := range storageDiffs {
+			if idx, found := indices[*storageDiff.Key]; found {
+				a.diff.StorageDiffs[addr][idx].Value = storageDiff.Value
+				continue
+			}
+			indices[*storageDiff.Key] = len(a.diff.StorageDiffs[addr])
+			a.diff.StorageDiffs[addr] = append(a.diff.StorageDiffs[addr], StorageDiff{
+				Key:   storageDiff.Key,
+				Value: storageDiff.Value,
+			})
+		}
+	}
+
+	for addr, nonce := range diff.Nonces {
+		a.diff.Nonces[addr] = nonce
+	}
+
+	for _, deployedContract := range diff.DeployedContracts {
+		a.deployedIndices[*deployedContract.Address] = len(a.diff.DeployedContracts)
+		a.diff.DeployedContracts = append(a.diff.DeployedContracts, deployedContract)
+	}
+
+	for _, classHash := range diff.DeclaredV0Classes {
+		if _, found := a.declaredV0[*classHash]; !found {
+			a.declaredV0[*classHash] = struct{}{}
+			a.diff.DeclaredV0Classes = append(a.diff.DeclaredV0Classes, classHash)
+		}
+	}
+
+	for _, declaredClass := range diff.DeclaredV1Classes {
+		if _, found := a.declaredV1[*declaredClass.ClassHash]; !found {
+			a.declaredV1[*declaredClass.ClassHash] = struct{}{}
+			a.diff.DeclaredV1Classes = append(a.diff.DeclaredV1Classes, declaredClass)
+		}
+	}
+
+	for _, replacedClass := range diff.ReplacedClasses {
+		if idx, found := a.deployedIndices[*replacedClass.Address]; found {
+			a.diff.DeployedContracts[idx].ClassHash = replacedClass.ClassHash
+		} else if idx, found := a.replacedIndices[*replacedClass.Address]; found {
+			a.diff.ReplacedClasses[idx].ClassHash = replacedClass.ClassHash
+		} else {
+			a.replacedIndices[*replacedClass.Address] = len(a.diff.ReplacedClasses)
+			a.diff.ReplacedClasses = append(a.diff.ReplacedClasses, replacedClass)
+		}
+	}
+}
+
+// StateDiff returns the aggregated diff.
+func (a *StateDiffAggregator) StateDiff() *StateDiff {
+	return a.diff
+}
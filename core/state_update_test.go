@@ -0,0 +1,151 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/crypto"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateDiffAggregator(t *testing.T) {
+	addr1 := utils.HexToFelt(t, "0x1")
+	addr2 := utils.HexToFelt(t, "0x2")
+	addr3 := utils.HexToFelt(t, "0x3")
+
+	first := &core.StateDiff{
+		StorageDiffs: map[felt.Felt][]core.StorageDiff{
+			*addr1: {
+				{Key: utils.HexToFelt(t, "0xA"), Value: utils.HexToFelt(t, "0x1")},
+				{Key: utils.HexToFelt(t, "0xB"), Value: utils.HexToFelt(t, "0x2")},
+			},
+		},
+		Nonces: map[felt.Felt]*felt.Felt{
+			*addr1: utils.HexToFelt(t, "0x1"),
+		},
+		DeployedContracts: []core.DeployedContract{
+			{Address: addr2, ClassHash: utils.HexToFelt(t, "0xC1")},
+		},
+		DeclaredV0Classes: []*felt.Felt{utils.HexToFelt(t, "0xC1")},
+		DeclaredV1Classes: []core.DeclaredV1Class{
+			{ClassHash: utils.HexToFelt(t, "0xC2"), CompiledClassHash: utils.HexToFelt(t, "0xCC2")},
+		},
+	}
+
+	second := &core.StateDiff{
+		StorageDiffs: map[felt.Felt][]core.StorageDiff{
+			*addr1: {
+				{Key: utils.HexToFelt(t, "0xB"), Value: utils.HexToFelt(t, "0x3")},
+				{Key: utils.HexToFelt(t, "0xC"), Value: utils.HexToFelt(t, "0x4")},
+			},
+			*addr2: {
+				{Key: utils.HexToFelt(t, "0xA"), Value: utils.HexToFelt(t, "0x5")},
+			},
+		},
+		Nonces: map[felt.Felt]*felt.Felt{
+			*addr1: utils.HexToFelt(t, "0x2"),
+			*addr3: utils.HexToFelt(t, "0x1"),
+		},
+		DeclaredV0Classes: []*felt.Felt{utils.HexToFelt(t, "0xC1"), utils.HexToFelt(t, "0xC3")},
+		ReplacedClasses: []core.ReplacedClass{
+			{Address: addr2, ClassHash: utils.HexToFelt(t, "0xC2")},
+			{Address: addr3, ClassHash: utils.HexToFelt(t, "0xC1")},
+		},
+	}
+
+	third := &core.StateDiff{
+		ReplacedClasses: []core.ReplacedClass{
+			{Address: addr3, ClassHash: utils.HexToFelt(t, "0xC3")},
+		},
+	}
+
+	aggregator := core.NewStateDiffAggregator()
+	aggregator.Add(first)
+	aggregator.Add(second)
+	aggregator.Add(third)
+
+	assert.Equal(t, &core.StateDiff{
+		StorageDiffs: map[felt.Felt][]core.StorageDiff{
+			*addr1: {
+				{Key: utils.HexToFelt(t, "0xA"), Value: utils.HexToFelt(t, "0x1")},
+				{Key: utils.HexToFelt(t, "0xB"), Value: utils.HexToFelt(t, "0x3")},
+				{Key: utils.HexToFelt(t, "0xC"), Value: utils.HexToFelt(t, "0x4")},
+			},
+			*addr2: {
+				{Key: utils.HexToFelt(t, "0xA"), Value: utils.HexToFelt(t, "0x5")},
+			},
+		},
+		Nonces: map[felt.Felt]*felt.Felt{
+			*addr1: utils.HexToFelt(t, "0x2"),
+			*addr3: utils.HexToFelt(t, "0x1"),
+		},
+		DeployedContracts: []core.DeployedContract{
+			{Address: addr2, ClassHash: utils.HexToFelt(t, "0xC2")},
+		},
+		DeclaredV0Classes: []*felt.Felt{utils.HexToFelt(t, "0xC1"), utils.HexToFelt(t, "0xC3")},
+		DeclaredV1Classes: []core.DeclaredV1Class{
+			{ClassHash: utils.HexToFelt(t, "0xC2"), CompiledClassHash: utils.HexToFelt(t, "0xCC2")},
+		},
+		ReplacedClasses: []core.ReplacedClass{
+			{Address: addr3, ClassHash: utils.HexToFelt(t, "0xC3")},
+		},
+	}, aggregator.StateDiff())
+
+	t.Run("inputs are not modified", func(t *testing.T) {
+		assert.Equal(t, utils.HexToFelt(t, "0xC1"), first.DeployedContracts[0].ClassHash)
+		assert.Equal(t, utils.HexToFelt(t, "0x2"), first.StorageDiffs[*addr1][1].Value)
+		assert.Equal(t, utils.HexToFelt(t, "0xC1"), second.ReplacedClasses[1].ClassHash)
+	})
+}
+
+func TestStateDiffCommitment(t *testing.T) {
+	t.Run("empty diff", func(t *testing.T) {
+		diff := new(core.StateDiff)
+		zero, one := new(felt.Felt), new(felt.Felt).SetUint64(1)
+		prefix := new(felt.Felt).SetBytes([]byte("STARKNET_STATE_DIFF0"))
+
+		assert.Equal(t, uint64(0), diff.Length())
+		assert.Equal(t, crypto.PoseidonArray(prefix, zero, zero, zero, one, zero, zero, zero), diff.Commitment())
+	})
+
+	t.Run("changes are hashed in canonical order", func(t *testing.T) {
+		diff := &core.StateDiff{
+			StorageDiffs: map[felt.Felt][]core.StorageDiff{
+				*utils.HexToFelt(t, "0x2"): {
+					{Key: utils.HexToFelt(t, "0xB"), Value: utils.HexToFelt(t, "0x2")},
+					{Key: utils.HexToFelt(t, "0xA"), Value: utils.HexToFelt(t, "0x1")},
+				},
+			},
+			Nonces: map[felt.Felt]*felt.Felt{
+				*utils.HexToFelt(t, "0x2"): utils.HexToFelt(t, "0x7"),
+			},
+			DeployedContracts: []core.DeployedContract{
+				{Address: utils.HexToFelt(t, "0x3"), ClassHash: utils.HexToFelt(t, "0xC3")},
+			},
+			DeclaredV0Classes: []*felt.Felt{utils.HexToFelt(t, "0xD2"), utils.HexToFelt(t, "0xD1")},
+			DeclaredV1Classes: []core.DeclaredV1Class{
+				{ClassHash: utils.HexToFelt(t, "0xE1"), CompiledClassHash: utils.HexToFelt(t, "0xF1")},
+			},
+			ReplacedClasses: []core.ReplacedClass{
+				{Address: utils.HexToFelt(t, "0x1"), ClassHash: utils.HexToFelt(t, "0xC1")},
+			},
+		}
+
+		hex := func(s string) *felt.Felt {
+			return utils.HexToFelt(t, s)
+		}
+		expected := crypto.PoseidonArray(
+			new(felt.Felt).SetBytes([]byte("STARKNET_STATE_DIFF0")),
+			hex("0x2"), hex("0x1"), hex("0xC1"), hex("0x3"), hex("0xC3"), // deployed and replaced contracts
+			hex("0x1"), hex("0xE1"), hex("0xF1"), // declared classes
+			hex("0x2"), hex("0xD1"), hex("0xD2"), // declared Cairo 0 classes
+			hex("0x1"), hex("0x0"), // data availability mode
+			hex("0x1"), hex("0x2"), hex("0x2"), hex("0xA"), hex("0x1"), hex("0xB"), hex("0x2"), // storage diffs
+			hex("0x1"), hex("0x2"), hex("0x7"), // nonces
+		)
+		assert.Equal(t, expected, diff.Commitment())
+		assert.Equal(t, uint64(8), diff.Length())
+	})
+}
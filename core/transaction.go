@@ -1,6 +1,7 @@
 package core
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"reflect"
@@ -10,6 +11,7 @@ import (
 	"github.com/NethermindEth/juno/core/trie"
 	"github.com/NethermindEth/juno/utils"
 	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/crypto/sha3"
 )
 
 type Event struct {
@@ -26,34 +28,103 @@ type L1ToL2Message struct {
 	To       *felt.Felt
 }
 
+// Hash returns the hash that the Starknet core contract on L1 computes for the message, that is
+// keccak256 over the sender, the recipient, the nonce, the selector, the length of the payload
+// and the payload, each as a 32 byte word.
+func (m *L1ToL2Message) Hash() common.Hash {
+	return messageHash(m.Payload, new(felt.Felt).SetBytes(m.From.Bytes()), m.To, m.Nonce, m.Selector)
+}
+
+// messageHash returns keccak256 over the given words followed by the length of the payload and
+// the payload, each as a 32 byte word.
+func messageHash(payload []*felt.Felt, words ...*felt.Felt) common.Hash {
+	words = append(words, new(felt.Felt).SetUint64(uint64(len(payload))))
+	words = append(words, payload...)
+
+	h := sha3.NewLegacyKeccak256()
+	for _, word := range words {
+		b := word.Bytes()
+		h.Write(b[:]) //nolint:errcheck // writing to a hash never fails
+	}
+	return common.BytesToHash(h.Sum(nil))
+}
+
 type L2ToL1Message struct {
 	From    *felt.Felt
 	Payload []*felt.Felt
 	To      common.Address
 }
 
+// Hash returns the hash that the Starknet core contract on L1 computes for the message, that is
+// keccak256 over the sender, the recipient, the length of the payload and the payload, each as a
+// 32 byte word.
+func (m *L2ToL1Message) Hash() common.Hash {
+	return messageHash(m.Payload, m.From, new(felt.Felt).SetBytes(m.To.Bytes()))
+}
+
 type ExecutionResources struct {
 	BuiltinInstanceCounter BuiltinInstanceCounter
 	MemoryHoles            uint64
 	Steps                  uint64
+	// The L1 gas and data gas consumed by the transaction, reported since 0.13.1
+	TotalGasConsumed *GasConsumed
+}
+
+type GasConsumed struct {
+	L1Gas     uint64
+	L1DataGas uint64
 }
 
 type BuiltinInstanceCounter struct {
-	Bitwise    uint64
-	EcOp       uint64
-	Ecsda      uint64
-	Output     uint64
-	Pedersen   uint64
-	RangeCheck uint64
+	Bitwise      uint64
+	EcOp         uint64
+	Ecsda        uint64
+	Keccak       uint64
+	Output       uint64
+	Pedersen     uint64
+	Poseidon     uint64
+	RangeCheck   uint64
+	SegmentArena uint64
+}
+
+// ExecutionStatus tells whether a transaction included in a block was executed successfully.
+// Transactions can be reverted since 0.12.1, their fee is charged but their changes are discarded.
+type ExecutionStatus uint8
+
+const (
+	Succeeded ExecutionStatus = iota
+	Reverted
+)
+
+// FeeUnit is the unit of the fee paid by a transaction.
+type FeeUnit uint8
+
+const (
+	// WEI is the unit of fees paid in ETH, by transactions up to version 2
+	WEI FeeUnit = iota
+	// FRI is the unit of fees paid in STRK, by version 3 transactions
+	FRI
+)
+
+// FeeUnitOf returns the unit of the fee paid by the given transaction.
+func FeeUnitOf(t Transaction) FeeUnit {
+	if version := transactionVersion(t); version != nil && version.Equal(version3) {
+		return FRI
+	}
+	return WEI
 }
 
 type TransactionReceipt struct {
 	Fee                *felt.Felt
+	FeeUnit            FeeUnit
 	Events             []*Event
 	ExecutionResources *ExecutionResources
 	L1ToL2Message      *L1ToL2Message
 	L2ToL1Message      []*L2ToL1Message
 	TransactionHash    *felt.Felt
+	ExecutionStatus    ExecutionStatus
+	// Why the transaction was reverted, empty if it succeeded
+	RevertReason string
 }
 
 type Transaction interface {
@@ -61,6 +132,40 @@ type Transaction interface {
 	Signature() []*felt.Felt
 }
 
+// Resource is a resource that version 3 transactions pay for.
+type Resource uint32
+
+const (
+	ResourceL1Gas Resource = iota + 1
+	ResourceL2Gas
+)
+
+func (r Resource) String() string {
+	switch r {
+	case ResourceL1Gas:
+		return "L1_GAS"
+	case ResourceL2Gas:
+		return "L2_GAS"
+	default:
+		return fmt.Sprintf("Resource(%d)", uint32(r))
+	}
+}
+
+// ResourceBounds are the maximum amount of a resource a transaction may use and the maximum
+// price it pays per unit.
+type ResourceBounds struct {
+	MaxAmount       uint64
+	MaxPricePerUnit *felt.Felt
+}
+
+// DataAvailabilityMode tells on which layer the data of an account is kept.
+type DataAvailabilityMode uint32
+
+const (
+	DAModeL1 DataAvailabilityMode = iota
+	DAModeL2
+)
+
 var (
 	_ Transaction = (*DeployTransaction)(nil)
 	_ Transaction = (*DeployAccountTransaction)(nil)
@@ -104,6 +209,17 @@ type DeployAccountTransaction struct {
 	TransactionSignature []*felt.Felt
 	// The transaction nonce.
 	Nonce *felt.Felt
+
+	// Version 3 fields
+	// The maximum amount and price of each resource the sender is willing to pay for.
+	ResourceBounds map[Resource]ResourceBounds
+	// The tip paid to the sequencer on top of the resource prices.
+	Tip uint64
+	// Data for the paymaster of the transaction, empty as long as paymasters are not supported.
+	PaymasterData []*felt.Felt
+	// Where the nonce and the fee token balance of the sender are kept.
+	NonceDAMode DataAvailabilityMode
+	FeeDAMode   DataAvailabilityMode
 }
 
 func (d *DeployAccountTransaction) Hash() *felt.Felt {
@@ -138,6 +254,19 @@ type InvokeTransaction struct {
 	Nonce *felt.Felt
 	// The address of the sender of this transaction
 	SenderAddress *felt.Felt
+
+	// Version 3 fields
+	// The maximum amount and price of each resource the sender is willing to pay for.
+	ResourceBounds map[Resource]ResourceBounds
+	// The tip paid to the sequencer on top of the resource prices.
+	Tip uint64
+	// Data for the paymaster of the transaction, empty as long as paymasters are not supported.
+	PaymasterData []*felt.Felt
+	// Where the nonce and the fee token balance of the sender are kept.
+	NonceDAMode DataAvailabilityMode
+	FeeDAMode   DataAvailabilityMode
+	// Data used to deploy the account of the sender if it doesn't exist yet.
+	AccountDeploymentData []*felt.Felt
 }
 
 func (i *InvokeTransaction) Hash() *felt.Felt {
@@ -169,6 +298,19 @@ type DeclareTransaction struct {
 
 	// Version 2 fields
 	CompiledClassHash *felt.Felt
+
+	// Version 3 fields
+	// The maximum amount and price of each resource the sender is willing to pay for.
+	ResourceBounds map[Resource]ResourceBounds
+	// The tip paid to the sequencer on top of the resource prices.
+	Tip uint64
+	// Data for the paymaster of the transaction, empty as long as paymasters are not supported.
+	PaymasterData []*felt.Felt
+	// Where the nonce and the fee token balance of the sender are kept.
+	NonceDAMode DataAvailabilityMode
+	FeeDAMode   DataAvailabilityMode
+	// Data used to deploy the account of the sender if it doesn't exist yet.
+	AccountDeploymentData []*felt.Felt
 }
 
 func (d *DeclareTransaction) Hash() *felt.Felt {
@@ -203,18 +345,38 @@ func (l *L1HandlerTransaction) Signature() []*felt.Felt {
 	return make([]*felt.Felt, 0)
 }
 
-func transactionHash(transaction Transaction, n utils.Network) (*felt.Felt, error) {
+// Message returns the L1 to L2 message consumed by the transaction. The first element of the
+// calldata is the sender of the message on L1 and the rest is its payload. Nil is returned for
+// transactions without calldata or nonce, the latter come from before messages had nonces and
+// their hashes can't be computed.
+func (l *L1HandlerTransaction) Message() *L1ToL2Message {
+	if len(l.CallData) == 0 || l.Nonce == nil {
+		return nil
+	}
+
+	from := l.CallData[0].Bytes()
+	return &L1ToL2Message{
+		From:     common.BytesToAddress(from[:]),
+		Nonce:    l.Nonce,
+		Payload:  l.CallData[1:],
+		Selector: l.EntryPointSelector,
+		To:       l.ContractAddress,
+	}
+}
+
+// transactionHash computes the hash of the transaction. If legacy is set, the transaction may also
+// have been hashed with one of the algorithms that were in use before version and max_fee were
+// introduced, see legacyTransactionHashes.
+func transactionHash(transaction Transaction, n utils.Network, legacy bool) (*felt.Felt, error) {
 	switch t := transaction.(type) {
 	case *DeclareTransaction:
-		return declareTransactionHash(t, n)
+		return declareTransactionHash(t, n, legacy)
 	case *InvokeTransaction:
-		return invokeTransactionHash(t, n)
+		return invokeTransactionHash(t, n, legacy)
 	case *DeployTransaction:
-		// deploy transactions are deprecated after re-genesis therefore we don't verify
-		// transaction hash
-		return t.TransactionHash, nil
+		return deployTransactionHash(t, n, legacy)
 	case *L1HandlerTransaction:
-		return l1HandlerTransactionHash(t, n)
+		return l1HandlerTransactionHash(t, n, legacy)
 	case *DeployAccountTransaction:
 		return deployAccountTransactionHash(t, n)
 	default:
@@ -225,19 +387,112 @@ func transactionHash(transaction Transaction, n utils.Network) (*felt.Felt, erro
 var (
 	invokeFelt        = new(felt.Felt).SetBytes([]byte("invoke"))
 	declareFelt       = new(felt.Felt).SetBytes([]byte("declare"))
+	deployFelt        = new(felt.Felt).SetBytes([]byte("deploy"))
 	l1HandlerFelt     = new(felt.Felt).SetBytes([]byte("l1_handler"))
 	deployAccountFelt = new(felt.Felt).SetBytes([]byte("deploy_account"))
 )
 
+// constructorSelector is the entry point selector of constructors
+var constructorSelector = func() *felt.Felt {
+	selector, err := crypto.StarknetKeccak([]byte("constructor"))
+	if err != nil {
+		panic(err)
+	}
+	return selector
+}()
+
+// matchingHash returns the current hash, unless legacy is set and one of the deprecated hashes is
+// equal to expected. Transactions in early blocks were hashed with algorithms that have been
+// deprecated since, which are only tried for those blocks. Deprecated hashes are computed lazily
+// since in most cases the current one matches.
+func matchingHash(expected *felt.Felt, legacy bool, current func() *felt.Felt,
+	deprecated ...func() *felt.Felt,
+) *felt.Felt {
+	hash := current()
+	if !legacy || hash.Equal(expected) {
+		return hash
+	}
+	for _, candidate := range deprecated {
+		if deprecatedHash := candidate(); deprecatedHash.Equal(expected) {
+			return deprecatedHash
+		}
+	}
+	return hash
+}
+
+// feltOrZero returns f, or zero if f is not set. Fields such as max_fee are missing from
+// transactions in early blocks.
+func feltOrZero(f *felt.Felt) *felt.Felt {
+	if f == nil {
+		return &felt.Zero
+	}
+	return f
+}
+
+// Transaction versions that are compared against. Versions 0 and 1 are checked with IsZero and IsOne.
+var (
+	version2 = new(felt.Felt).SetUint64(2)
+	version3 = new(felt.Felt).SetUint64(3)
+)
+
+// tipAndResourcesHash commits to the fee related fields of a version 3 transaction.
+func tipAndResourcesHash(tip uint64, resourceBounds map[Resource]ResourceBounds) *felt.Felt {
+	return crypto.PoseidonArray(
+		new(felt.Felt).SetUint64(tip),
+		resourceBoundsFelt(ResourceL1Gas, resourceBounds[ResourceL1Gas]),
+		resourceBoundsFelt(ResourceL2Gas, resourceBounds[ResourceL2Gas]),
+	)
+}
+
+// resourceBoundsFelt packs the bounds of a resource into a single felt: the name of the
+// resource as a short string, followed by the max amount (64 bits) and the max price per unit
+// (128 bits).
+func resourceBoundsFelt(resource Resource, bounds ResourceBounds) *felt.Felt {
+	var maxAmount [8]byte
+	binary.BigEndian.PutUint64(maxAmount[:], bounds.MaxAmount)
+	maxPrice := feltOrZero(bounds.MaxPricePerUnit).Bytes()
+
+	packed := append([]byte(resource.String()), maxAmount[:]...)
+	packed = append(packed, maxPrice[16:]...)
+	return new(felt.Felt).SetBytes(packed)
+}
+
+// dataAvailabilityModes packs the data availability modes of a version 3 transaction into a felt.
+func dataAvailabilityModes(nonceDAMode, feeDAMode DataAvailabilityMode) *felt.Felt {
+	return new(felt.Felt).SetUint64(uint64(nonceDAMode)<<32 | uint64(feeDAMode))
+}
+
 func errInvalidTransactionVersion(t Transaction, version *felt.Felt) error {
 	return fmt.Errorf("invalid Transaction (type: %v) verion: %v", reflect.TypeOf(t), version.Text(felt.Base10))
 }
 
-func invokeTransactionHash(i *InvokeTransaction, n utils.Network) (*felt.Felt, error) {
+func invokeTransactionHash(i *InvokeTransaction, n utils.Network, legacy bool) (*felt.Felt, error) {
 	switch {
 	case i.Version.IsZero():
-		// Due to inconsistencies in version 0 hash calculation we don't verify the hash
-		return i.TransactionHash, nil
+		callDataHash := crypto.PedersenArray(i.CallData...)
+		return matchingHash(i.TransactionHash, legacy,
+			func() *felt.Felt {
+				return crypto.PedersenArray(
+					invokeFelt,
+					i.Version,
+					i.ContractAddress,
+					i.EntryPointSelector,
+					callDataHash,
+					feltOrZero(i.MaxFee),
+					n.ChainID(),
+				)
+			},
+			// before version and max_fee were introduced
+			func() *felt.Felt {
+				return crypto.PedersenArray(
+					invokeFelt,
+					i.ContractAddress,
+					i.EntryPointSelector,
+					callDataHash,
+					n.ChainID(),
+				)
+			},
+		), nil
 	case i.Version.IsOne():
 		return crypto.PedersenArray(
 			invokeFelt,
@@ -249,16 +504,52 @@ func invokeTransactionHash(i *InvokeTransaction, n utils.Network) (*felt.Felt, e
 			n.ChainID(),
 			i.Nonce,
 		), nil
+	case i.Version.Equal(version3):
+		return crypto.PoseidonArray(
+			invokeFelt,
+			i.Version,
+			i.SenderAddress,
+			tipAndResourcesHash(i.Tip, i.ResourceBounds),
+			crypto.PoseidonArray(i.PaymasterData...),
+			n.ChainID(),
+			i.Nonce,
+			dataAvailabilityModes(i.NonceDAMode, i.FeeDAMode),
+			crypto.PoseidonArray(i.AccountDeploymentData...),
+			crypto.PoseidonArray(i.CallData...),
+		), nil
 	default:
 		return nil, errInvalidTransactionVersion(i, i.Version)
 	}
 }
 
-func declareTransactionHash(d *DeclareTransaction, n utils.Network) (*felt.Felt, error) {
+func declareTransactionHash(d *DeclareTransaction, n utils.Network, legacy bool) (*felt.Felt, error) {
 	switch {
 	case d.Version.IsZero():
-		// Due to inconsistencies in version 0 hash calculation we don't verify the hash
-		return d.TransactionHash, nil
+		return matchingHash(d.TransactionHash, legacy,
+			func() *felt.Felt {
+				return crypto.PedersenArray(
+					declareFelt,
+					d.Version,
+					d.SenderAddress,
+					&felt.Zero,
+					crypto.PedersenArray(),
+					feltOrZero(d.MaxFee),
+					n.ChainID(),
+					d.ClassHash,
+				)
+			},
+			// before version and max_fee were introduced
+			func() *felt.Felt {
+				return crypto.PedersenArray(
+					declareFelt,
+					d.SenderAddress,
+					&felt.Zero,
+					crypto.PedersenArray(),
+					n.ChainID(),
+					d.ClassHash,
+				)
+			},
+		), nil
 	case d.Version.IsOne():
 		return crypto.PedersenArray(
 			declareFelt,
@@ -270,7 +561,7 @@ func declareTransactionHash(d *DeclareTransaction, n utils.Network) (*felt.Felt,
 			n.ChainID(),
 			d.Nonce,
 		), nil
-	case d.Version.Equal(new(felt.Felt).SetUint64(2)):
+	case d.Version.Equal(version2):
 		return crypto.PedersenArray(
 			declareFelt,
 			d.Version,
@@ -282,29 +573,98 @@ func declareTransactionHash(d *DeclareTransaction, n utils.Network) (*felt.Felt,
 			d.Nonce,
 			d.CompiledClassHash,
 		), nil
-
+	case d.Version.Equal(version3):
+		return crypto.PoseidonArray(
+			declareFelt,
+			d.Version,
+			d.SenderAddress,
+			tipAndResourcesHash(d.Tip, d.ResourceBounds),
+			crypto.PoseidonArray(d.PaymasterData...),
+			n.ChainID(),
+			d.Nonce,
+			dataAvailabilityModes(d.NonceDAMode, d.FeeDAMode),
+			crypto.PoseidonArray(d.AccountDeploymentData...),
+			d.ClassHash,
+			d.CompiledClassHash,
+		), nil
 	default:
 		return nil, errInvalidTransactionVersion(d, d.Version)
 	}
 }
 
-func l1HandlerTransactionHash(l *L1HandlerTransaction, n utils.Network) (*felt.Felt, error) {
+func deployTransactionHash(d *DeployTransaction, n utils.Network, legacy bool) (*felt.Felt, error) {
+	if !d.Version.IsZero() && !d.Version.IsOne() {
+		return nil, errInvalidTransactionVersion(d, d.Version)
+	}
+
+	callDataHash := crypto.PedersenArray(d.ConstructorCallData...)
+	return matchingHash(d.TransactionHash, legacy,
+		func() *felt.Felt {
+			return crypto.PedersenArray(
+				deployFelt,
+				d.Version,
+				d.ContractAddress,
+				constructorSelector,
+				callDataHash,
+				&felt.Zero,
+				n.ChainID(),
+			)
+		},
+		// before version and max_fee were introduced
+		func() *felt.Felt {
+			return crypto.PedersenArray(
+				deployFelt,
+				d.ContractAddress,
+				constructorSelector,
+				callDataHash,
+				n.ChainID(),
+			)
+		},
+	), nil
+}
+
+func l1HandlerTransactionHash(l *L1HandlerTransaction, n utils.Network, legacy bool) (*felt.Felt, error) {
 	switch {
 	case l.Version.IsZero():
-		// There are some l1 handler transaction which do not return a nonce and for some random
-		// transaction the following hash fails.
+		callDataHash := crypto.PedersenArray(l.CallData...)
 		if l.Nonce == nil {
-			return l.TransactionHash, nil
+			// L1 handler transactions had no nonce and were hashed as invoke transactions before
+			// they got a hash of their own
+			if !legacy {
+				return nil, errors.New("l1 handler transaction has no nonce")
+			}
+			return crypto.PedersenArray(
+				invokeFelt,
+				l.ContractAddress,
+				l.EntryPointSelector,
+				callDataHash,
+				n.ChainID(),
+			), nil
 		}
-		return crypto.PedersenArray(
-			l1HandlerFelt,
-			l.Version,
-			l.ContractAddress,
-			l.EntryPointSelector,
-			crypto.PedersenArray(l.CallData...),
-			&felt.Zero,
-			n.ChainID(),
-			l.Nonce,
+		return matchingHash(l.TransactionHash, legacy,
+			func() *felt.Felt {
+				return crypto.PedersenArray(
+					l1HandlerFelt,
+					l.Version,
+					l.ContractAddress,
+					l.EntryPointSelector,
+					callDataHash,
+					&felt.Zero,
+					n.ChainID(),
+					l.Nonce,
+				)
+			},
+			// before version and max_fee were introduced
+			func() *felt.Felt {
+				return crypto.PedersenArray(
+					l1HandlerFelt,
+					l.ContractAddress,
+					l.EntryPointSelector,
+					callDataHash,
+					n.ChainID(),
+					l.Nonce,
+				)
+			},
 		), nil
 	default:
 		return nil, errInvalidTransactionVersion(l, l.Version)
@@ -312,10 +672,11 @@ func l1HandlerTransactionHash(l *L1HandlerTransaction, n utils.Network) (*felt.F
 }
 
 func deployAccountTransactionHash(d *DeployAccountTransaction, n utils.Network) (*felt.Felt, error) {
-	callData := []*felt.Felt{d.ClassHash, d.ContractAddressSalt}
-	callData = append(callData, d.ConstructorCallData...)
 	// There is no version 0 for deploy account
-	if d.Version.IsOne() {
+	switch {
+	case d.Version.IsOne():
+		callData := []*felt.Felt{d.ClassHash, d.ContractAddressSalt}
+		callData = append(callData, d.ConstructorCallData...)
 		return crypto.PedersenArray(
 			deployAccountFelt,
 			d.Version,
@@ -326,8 +687,23 @@ func deployAccountTransactionHash(d *DeployAccountTransaction, n utils.Network)
 			n.ChainID(),
 			d.Nonce,
 		), nil
+	case d.Version.Equal(version3):
+		return crypto.PoseidonArray(
+			deployAccountFelt,
+			d.Version,
+			d.ContractAddress,
+			tipAndResourcesHash(d.Tip, d.ResourceBounds),
+			crypto.PoseidonArray(d.PaymasterData...),
+			n.ChainID(),
+			d.Nonce,
+			dataAvailabilityModes(d.NonceDAMode, d.FeeDAMode),
+			crypto.PoseidonArray(d.ConstructorCallData...),
+			d.ClassHash,
+			d.ContractAddressSalt,
+		), nil
+	default:
+		return nil, errInvalidTransactionVersion(d, d.Version)
 	}
-	return nil, errInvalidTransactionVersion(d, d.Version)
 }
 
 type CantVerifyTransactionHashError struct {
@@ -352,10 +728,37 @@ func (e CantVerifyTransactionHashError) Error() string {
 	return errStr
 }
 
-func verifyTransactions(txs []Transaction, n utils.Network) error {
+// ContractAddressMismatchError is returned when the address of the contract deployed by a
+// transaction does not match the address calculated from its class hash, salt and constructor
+// calldata. Unlike [CantVerifyTransactionHashError], it means that the block is invalid.
+//
+// The mismatches of all the transactions of a block are chained in transaction order and can be
+// walked with [errors.Unwrap].
+type ContractAddressMismatchError struct {
+	// Index is the position of the transaction in its block.
+	Index             int
+	TransactionHash   *felt.Felt
+	Address           *felt.Felt
+	CalculatedAddress *felt.Felt
+	next              *ContractAddressMismatchError
+}
+
+func (e *ContractAddressMismatchError) Unwrap() error {
+	if e.next != nil {
+		return e.next
+	}
+	return nil
+}
+
+func (e *ContractAddressMismatchError) Error() string {
+	return fmt.Sprintf("contract address %s deployed by transaction %s at index %d does not match calculated address %s",
+		e.Address, e.TransactionHash, e.Index, e.CalculatedAddress)
+}
+
+func verifyTransactions(txs []Transaction, n utils.Network, legacy bool) error {
 	var head *CantVerifyTransactionHashError
 	for _, tx := range txs {
-		if err := verifyTransactionHash(tx, n); err != nil {
+		if err := verifyTransactionHash(tx, n, legacy); err != nil {
 			err.next = head
 			head = err
 		}
@@ -366,8 +769,8 @@ func verifyTransactions(txs []Transaction, n utils.Network) error {
 	return nil
 }
 
-func verifyTransactionHash(t Transaction, n utils.Network) *CantVerifyTransactionHashError {
-	calculatedTxHash, err := transactionHash(t, n)
+func verifyTransactionHash(t Transaction, n utils.Network, legacy bool) *CantVerifyTransactionHashError {
+	calculatedTxHash, err := transactionHash(t, n, legacy)
 	if err != nil {
 		return &CantVerifyTransactionHashError{t: t, hashFailure: err}
 	}
@@ -377,16 +780,55 @@ func verifyTransactionHash(t Transaction, n utils.Network) *CantVerifyTransactio
 	return nil
 }
 
+// verifyContractAddresses checks that the addresses of the contracts deployed by the
+// transactions match the addresses calculated from their class hash, salt and constructor
+// calldata. All the mismatches are returned as a chain of [ContractAddressMismatchError]s.
+func verifyContractAddresses(txs []Transaction) error {
+	var head *ContractAddressMismatchError
+	for i := len(txs) - 1; i >= 0; i-- {
+		var deploy *DeployTransaction
+		switch tx := txs[i].(type) {
+		case *DeployTransaction:
+			deploy = tx
+		case *DeployAccountTransaction:
+			deploy = &tx.DeployTransaction
+		default:
+			continue
+		}
+
+		if deploy.ClassHash == nil {
+			return fmt.Errorf("transaction %s at index %d deploys a contract without a class hash", txs[i].Hash(), i)
+		}
+		// Contracts deployed by these transactions have no deployer, so the caller address is zero.
+		calculatedAddress := ContractAddress(&felt.Zero, deploy.ClassHash, deploy.ContractAddressSalt,
+			deploy.ConstructorCallData)
+		if !calculatedAddress.Equal(deploy.ContractAddress) {
+			head = &ContractAddressMismatchError{
+				Index:             i,
+				TransactionHash:   txs[i].Hash(),
+				Address:           deploy.ContractAddress,
+				CalculatedAddress: calculatedAddress,
+				next:              head,
+			}
+		}
+	}
+	if head != nil {
+		return head
+	}
+	return nil
+}
+
 const commitmentTrieHeight uint = 64
 
 // transactionCommitment is the root of a height 64 binary Merkle Patricia tree of the
-// transaction hashes and signatures in a block.
-func transactionCommitment(transactions []Transaction) (*felt.Felt, error) {
+// transaction hashes and signatures in a block. Only the signatures of invoke transactions are
+// included unless allSignatures is set.
+func transactionCommitment(transactions []Transaction, allSignatures bool) (*felt.Felt, error) {
 	var commitment *felt.Felt
-	return commitment, trie.RunOnTempTrie(commitmentTrieHeight, func(trie *trie.Trie) error {
+	return commitment, transactionCommitmentTrieConfig.RunOnTemp(func(trie *trie.Trie) error {
 		for i, transaction := range transactions {
 			signatureHash := crypto.PedersenArray()
-			if _, ok := transaction.(*InvokeTransaction); ok {
+			if _, ok := transaction.(*InvokeTransaction); ok || allSignatures {
 				signatureHash = crypto.PedersenArray(transaction.Signature()...)
 			}
 
@@ -407,7 +849,7 @@ func transactionCommitment(transactions []Transaction) (*felt.Felt, error) {
 // eventCommitment computes the event commitment for a block.
 func eventCommitment(receipts []*TransactionReceipt) (*felt.Felt, error) {
 	var commitment *felt.Felt
-	return commitment, trie.RunOnTempTrie(commitmentTrieHeight, func(trie *trie.Trie) error {
+	return commitment, eventCommitmentTrieConfig.RunOnTemp(func(trie *trie.Trie) error {
 		count := uint64(0)
 		for _, receipt := range receipts {
 			for _, event := range receipt.Events {
@@ -431,3 +873,123 @@ func eventCommitment(receipts []*TransactionReceipt) (*felt.Felt, error) {
 		return nil
 	})
 }
+
+// transactionCommitmentPoseidon is the transaction commitment of blocks with Poseidon block
+// hashes. The leaves are the Poseidon hashes of the transaction hashes and signatures.
+func transactionCommitmentPoseidon(transactions []Transaction) (*felt.Felt, error) {
+	var commitment *felt.Felt
+	return commitment, transactionCommitmentPoseidonTrieConfig.RunOnTemp(func(trie *trie.Trie) error {
+		for i, transaction := range transactions {
+			leaf := []*felt.Felt{transaction.Hash()}
+			if signature := transaction.Signature(); len(signature) > 0 {
+				leaf = append(leaf, signature...)
+			} else {
+				leaf = append(leaf, &felt.Zero)
+			}
+
+			if _, err := trie.Put(new(felt.Felt).SetUint64(uint64(i)), crypto.PoseidonArray(leaf...)); err != nil {
+				return err
+			}
+		}
+		root, err := trie.Root()
+		if err != nil {
+			return err
+		}
+		commitment = root
+		return nil
+	})
+}
+
+// eventCommitmentPoseidon is the event commitment of blocks with Poseidon block hashes. Unlike
+// [eventCommitment], events are also bound to the transaction that emitted them.
+func eventCommitmentPoseidon(receipts []*TransactionReceipt) (*felt.Felt, error) {
+	var commitment *felt.Felt
+	return commitment, eventCommitmentPoseidonTrieConfig.RunOnTemp(func(trie *trie.Trie) error {
+		count := uint64(0)
+		for _, receipt := range receipts {
+			for _, event := range receipt.Events {
+				elems := []*felt.Felt{event.From, receipt.TransactionHash, new(felt.Felt).SetUint64(uint64(len(event.Keys)))}
+				elems = append(elems, event.Keys...)
+				elems = append(elems, new(felt.Felt).SetUint64(uint64(len(event.Data))))
+				elems = append(elems, event.Data...)
+
+				if _, err := trie.Put(new(felt.Felt).SetUint64(count), crypto.PoseidonArray(elems...)); err != nil {
+					return err
+				}
+				count++
+			}
+		}
+		root, err := trie.Root()
+		if err != nil {
+			return err
+		}
+		commitment = root
+		return nil
+	})
+}
+
+// receiptCommitment is the root of a height 64 binary Merkle Patricia tree of the receipt
+// hashes of a block, see [receiptHash].
+func receiptCommitment(receipts []*TransactionReceipt) (*felt.Felt, error) {
+	var commitment *felt.Felt
+	return commitment, receiptCommitmentTrieConfig.RunOnTemp(func(trie *trie.Trie) error {
+		for i, receipt := range receipts {
+			hash, err := receiptHash(receipt)
+			if err != nil {
+				return err
+			}
+			if _, err = trie.Put(new(felt.Felt).SetUint64(uint64(i)), hash); err != nil {
+				return err
+			}
+		}
+		root, err := trie.Root()
+		if err != nil {
+			return err
+		}
+		commitment = root
+		return nil
+	})
+}
+
+// receiptHash commits to the fee, the messages sent to L1, the revert reason and the gas consumed
+// by a transaction.
+func receiptHash(r *TransactionReceipt) (*felt.Felt, error) {
+	revertReasonHash := &felt.Zero
+	if r.RevertReason != "" {
+		var err error
+		if revertReasonHash, err = crypto.StarknetKeccak([]byte(r.RevertReason)); err != nil {
+			return nil, err
+		}
+	}
+
+	var l1Gas, l1DataGas uint64
+	if r.ExecutionResources != nil && r.ExecutionResources.TotalGasConsumed != nil {
+		l1Gas = r.ExecutionResources.TotalGasConsumed.L1Gas
+		l1DataGas = r.ExecutionResources.TotalGasConsumed.L1DataGas
+	}
+
+	return crypto.PoseidonArray(
+		r.TransactionHash,
+		feltOrZero(r.Fee),
+		messagesSentHash(r.L2ToL1Message),
+		revertReasonHash,
+		crypto.PoseidonArray(
+			&felt.Zero, // L2 gas consumed
+			new(felt.Felt).SetUint64(l1Gas),
+			new(felt.Felt).SetUint64(l1DataGas),
+		),
+	), nil
+}
+
+func messagesSentHash(messages []*L2ToL1Message) *felt.Felt {
+	elems := []*felt.Felt{new(felt.Felt).SetUint64(uint64(len(messages)))}
+	for _, message := range messages {
+		elems = append(elems,
+			message.From,
+			new(felt.Felt).SetBytes(message.To.Bytes()),
+			new(felt.Felt).SetUint64(uint64(len(message.Payload))),
+		)
+		elems = append(elems, message.Payload...)
+	}
+	return crypto.PoseidonArray(elems...)
+}
@@ -1,6 +1,9 @@
 package core
 
 import (
+	"bytes"
+
+	"github.com/NethermindEth/juno/core/felt"
 	"github.com/NethermindEth/juno/core/trie"
 	"github.com/NethermindEth/juno/db"
 	"github.com/NethermindEth/juno/encoder"
@@ -70,3 +73,49 @@ func (t *TransactionStorage) Delete(key *bitset.BitSet) error {
 	}
 	return t.txn.Delete(dbKey)
 }
+
+// Leaves calls fn with the key and value of every leaf of a trie built with the given config that
+// is stored under the configured prefix. Keys are decoded with the key decoding of the config.
+// Leaves are found by scanning the database rather than by walking the trie, so nodes that are no
+// longer reachable from the root are visited as well.
+func (t *TransactionStorage) Leaves(config trie.Config, fn func(key, value *felt.Felt) error) error {
+	iterator, err := t.txn.NewIterator()
+	if err != nil {
+		return err
+	}
+
+	for iterator.Seek(t.prefix); iterator.Valid(); iterator.Next() {
+		dbKey := iterator.Key()
+		if !bytes.HasPrefix(dbKey, t.prefix) {
+			break
+		}
+		// global tries keep their root key under the bare prefix
+		if len(dbKey) == len(t.prefix) {
+			continue
+		}
+
+		key := new(bitset.BitSet)
+		if err = key.UnmarshalBinary(dbKey[len(t.prefix):]); err != nil {
+			return db.CloseAndWrapOnError(iterator.Close, err)
+		}
+		if key.Len() != config.Height {
+			continue
+		}
+
+		val, err := iterator.Value()
+		if err != nil {
+			return db.CloseAndWrapOnError(iterator.Close, err)
+		}
+
+		node := new(trie.Node)
+		if err = encoder.Unmarshal(val, node); err != nil {
+			return db.CloseAndWrapOnError(iterator.Close, err)
+		}
+
+		if err = fn(config.DecodeKey(key), node.Value); err != nil {
+			return db.CloseAndWrapOnError(iterator.Close, err)
+		}
+	}
+
+	return iterator.Close()
+}
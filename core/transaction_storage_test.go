@@ -77,3 +77,45 @@ func TestTransactionStorage(t *testing.T) {
 		}), db.ErrKeyNotFound.Error())
 	})
 }
+
+func TestTransactionStorageLeaves(t *testing.T) {
+	testDB := pebble.NewMemTest()
+	prefix := []byte{37, 44}
+	one := new(felt.Felt).SetUint64(1)
+	// keys are stored off by one, so leaves must be decoded with the config
+	config := trie.Config{
+		Name:   "off-by-one",
+		Hash:   func(a, b *felt.Felt) *felt.Felt { return new(felt.Felt).Add(a, b) },
+		Height: 8,
+		KeyEncoding: func(key *felt.Felt, height uint) *bitset.BitSet {
+			return trie.FeltKeyEncoding(new(felt.Felt).Add(key, one), height)
+		},
+		KeyDecoding: func(path *bitset.BitSet) *felt.Felt {
+			return new(felt.Felt).Sub(trie.FeltKeyDecoding(path), one)
+		},
+	}
+
+	leaves := make(map[felt.Felt]felt.Felt)
+	for _, key := range []uint64{3, 7, 200} {
+		leaves[*new(felt.Felt).SetUint64(key)] = *new(felt.Felt).SetUint64(10 * key)
+	}
+	require.NoError(t, testDB.Update(func(txn db.Transaction) error {
+		tempTrie, err := config.New(core.NewTransactionStorage(txn, prefix), nil)
+		require.NoError(t, err)
+		for key, value := range leaves {
+			key, value := key, value
+			_, err = tempTrie.Put(&key, &value)
+			require.NoError(t, err)
+		}
+		return nil
+	}))
+
+	got := make(map[felt.Felt]felt.Felt)
+	require.NoError(t, testDB.View(func(txn db.Transaction) error {
+		return core.NewTransactionStorage(txn, prefix).Leaves(config, func(key, value *felt.Felt) error {
+			got[*key] = *value
+			return nil
+		})
+	}))
+	assert.Equal(t, leaves, got)
+}
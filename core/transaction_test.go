@@ -1,12 +1,18 @@
 package core_test
 
 import (
+	"context"
+	"errors"
 	"reflect"
 	"testing"
 
+	"github.com/NethermindEth/juno/clients/feeder"
 	"github.com/NethermindEth/juno/core"
 	"github.com/NethermindEth/juno/core/felt"
 	"github.com/NethermindEth/juno/encoder"
+	adaptfeeder "github.com/NethermindEth/juno/starknetdata/feeder"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -134,3 +140,106 @@ func checkTransactionSymmetry(t *testing.T, input core.Transaction) {
 		assert.Fail(t, "not a transaction")
 	}
 }
+
+func TestLegacyTransactionHashes(t *testing.T) {
+	tests := map[string]struct {
+		network utils.Network
+		block   uint64
+	}{
+		"deploy and invoke version 0":                     {network: utils.MAINNET, block: 0},
+		"deploy without version and max fee":              {network: utils.MAINNET, block: 833},
+		"declare version 0":                               {network: utils.MAINNET, block: 2889},
+		"l1 handler without version and max fee":          {network: utils.MAINNET, block: 1059},
+		"l1 handler without nonce":                        {network: utils.MAINNET, block: 192},
+		"deploy version 1":                                {network: utils.GOERLI, block: 485004},
+		"declare version 0 and deploy on another network": {network: utils.GOERLI, block: 231579},
+		"deploy and invoke version 0 on integration":      {network: utils.INTEGRATION, block: 1},
+		"invoke version 1 on integration":                 {network: utils.INTEGRATION, block: 283364},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			client, closeFn := feeder.NewTestClient(test.network)
+			t.Cleanup(closeFn)
+			gw := adaptfeeder.New(client)
+
+			block, err := gw.BlockByNumber(context.Background(), test.block)
+			require.NoError(t, err)
+			assert.NoError(t, core.VerifyBlockHash(block, test.network))
+		})
+	}
+
+	t.Run("error if deploy transaction hash is invalid", func(t *testing.T) {
+		client, closeFn := feeder.NewTestClient(utils.MAINNET)
+		t.Cleanup(closeFn)
+		gw := adaptfeeder.New(client)
+
+		block, err := gw.BlockByNumber(context.Background(), 0)
+		require.NoError(t, err)
+
+		deployTx, ok := block.Transactions[0].(*core.DeployTransaction)
+		require.True(t, ok)
+		deployTx.ConstructorCallData = append(deployTx.ConstructorCallData, new(felt.Felt).SetUint64(1))
+		// keep the contract address valid
+		deployTx.ContractAddress = core.ContractAddress(&felt.Zero, deployTx.ClassHash, deployTx.ContractAddressSalt,
+			deployTx.ConstructorCallData)
+
+		err = core.VerifyBlockHash(block, utils.MAINNET)
+		assert.True(t, errors.As(err, new(core.CantVerifyTransactionHashError)))
+	})
+
+	// the block hashes of these blocks do not commit to the protocol version
+	t.Run("error if legacy hashes are used by a block with a protocol version", func(t *testing.T) {
+		client, closeFn := feeder.NewTestClient(utils.MAINNET)
+		t.Cleanup(closeFn)
+		gw := adaptfeeder.New(client)
+
+		for _, number := range []uint64{0, 192} {
+			block, err := gw.BlockByNumber(context.Background(), number)
+			require.NoError(t, err)
+			block.ProtocolVersion = "0.9.1"
+
+			err = core.VerifyBlockHash(block, utils.MAINNET)
+			assert.True(t, errors.As(err, new(core.CantVerifyTransactionHashError)))
+		}
+	})
+}
+
+func TestL1ToL2MessageHash(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+
+	block, err := gw.BlockByNumber(context.Background(), 1059)
+	require.NoError(t, err)
+
+	l1Handler, ok := block.Transactions[14].(*core.L1HandlerTransaction)
+	require.True(t, ok)
+
+	// the message consumed by the transaction is reported in its receipt
+	msg := l1Handler.Message()
+	assert.Equal(t, block.Receipts[14].L1ToL2Message, msg)
+	assert.Equal(t, common.HexToHash("0x6563d03b2a3a40c8deabb304dc06dc5ee953f5c7adb757e3a2960abc07f449d4"), msg.Hash())
+
+	t.Run("transaction without nonce", func(t *testing.T) {
+		assert.Nil(t, (&core.L1HandlerTransaction{CallData: l1Handler.CallData}).Message())
+	})
+}
+
+func TestL2ToL1MessageHash(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+
+	block, err := gw.BlockByNumber(context.Background(), 0)
+	require.NoError(t, err)
+
+	require.Len(t, block.Receipts[2].L2ToL1Message, 1)
+	msg := block.Receipts[2].L2ToL1Message[0]
+	want := common.HexToHash("0x34d6607791d4d3914a550252a18bb8e9cd2e7c3432cde9b1e37b21ed9ad4e3a0")
+	assert.Equal(t, want, msg.Hash())
+
+	msg.Payload = msg.Payload[:1]
+	assert.NotEqual(t, want, msg.Hash())
+}
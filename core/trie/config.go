@@ -0,0 +1,163 @@
+package trie
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/bits-and-blooms/bitset"
+)
+
+// HashFunc combines two felts into one. It is used to hash the children of
+// binary [Node]s and to hash a [Node]'s value with its path.
+type HashFunc func(*felt.Felt, *felt.Felt) *felt.Felt
+
+// KeyEncoding converts a leaf key into the path, of length height, that leads to
+// the leaf in a [Trie].
+type KeyEncoding func(key *felt.Felt, height uint) *bitset.BitSet
+
+// FeltKeyEncoding uses the `height` least significant bits of the key as its path.
+// This is the encoding used by all Starknet tries.
+func FeltKeyEncoding(key *felt.Felt, height uint) *bitset.BitSet {
+	kBits := key.Bits()
+	return bitset.FromWithLength(height, kBits[:])
+}
+
+// KeyDecoding converts the path that leads to a leaf back into the leaf key. It is the
+// inverse of the [KeyEncoding] of the same [Config].
+type KeyDecoding func(path *bitset.BitSet) *felt.Felt
+
+// FeltKeyDecoding is the inverse of [FeltKeyEncoding].
+func FeltKeyDecoding(path *bitset.BitSet) *felt.Felt {
+	var keyBytes [felt.Bytes]byte
+	for idx, word := range path.Bytes() {
+		startBytes := felt.Bytes - (idx+1)*8
+		binary.BigEndian.PutUint64(keyBytes[startBytes:startBytes+8], word)
+	}
+	return new(felt.Felt).SetBytes(keyBytes[:])
+}
+
+// Config describes how a [Trie] is built: which hash function is used to compute
+// its commitment, its height and how leaf keys are mapped to paths.
+type Config struct {
+	// Name identifies the Config in the registry, see [Register].
+	Name   string
+	Hash   HashFunc
+	Height uint
+	// KeyEncoding defaults to [FeltKeyEncoding] if nil.
+	KeyEncoding KeyEncoding
+	// KeyDecoding defaults to [FeltKeyDecoding] if nil. It must be set if KeyEncoding is.
+	KeyDecoding KeyDecoding
+}
+
+// Validate checks that a [Trie] can be built from the Config.
+func (c Config) Validate() error {
+	if c.Hash == nil {
+		return fmt.Errorf("trie config %q: hash function is not set", c.Name)
+	}
+	if c.Height > felt.Bits {
+		return fmt.Errorf("max trie height is %d, got: %d", felt.Bits, c.Height)
+	}
+	if c.KeyEncoding != nil && c.KeyDecoding == nil {
+		return fmt.Errorf("trie config %q: key encoding is set without a key decoding", c.Name)
+	}
+	return nil
+}
+
+// DecodeKey returns the leaf key that the given path leads to.
+func (c Config) DecodeKey(path *bitset.BitSet) *felt.Felt {
+	if c.KeyDecoding == nil {
+		return FeltKeyDecoding(path)
+	}
+	return c.KeyDecoding(path)
+}
+
+// New creates a [Trie] on the given storage that is rooted at rootKey.
+func (c Config) New(storage Storage, rootKey *bitset.BitSet) (*Trie, error) {
+	return newTrie(storage, rootKey, c)
+}
+
+// RunOnTemp creates an in-memory [Trie] and runs `do` on that Trie
+func (c Config) RunOnTemp(do func(*Trie) error) error {
+	trie, err := c.New(newMemStorage(), nil)
+	if err != nil {
+		return err
+	}
+	return do(trie)
+}
+
+// WithHeight returns a copy of the Config with the given height.
+func (c Config) WithHeight(height uint) Config {
+	c.Height = height
+	return c
+}
+
+// WithHash returns a copy of the Config with the given hash function. This is useful
+// to build the same trie with a cheap hash function in tests and benchmarks.
+func (c Config) WithHash(hash HashFunc) Config {
+	c.Hash = hash
+	return c
+}
+
+var (
+	ErrConfigNotFound       = errors.New("trie config not found")
+	ErrConfigAlreadyDefined = errors.New("trie config already defined")
+)
+
+var registry = struct {
+	sync.RWMutex
+	configs map[string]Config
+}{configs: make(map[string]Config)}
+
+// Register adds a [Config] to the registry under its name.
+func Register(config Config) error {
+	if config.Name == "" {
+		return errors.New("trie config name is empty")
+	}
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+	if _, found := registry.configs[config.Name]; found {
+		return fmt.Errorf("%w: %s", ErrConfigAlreadyDefined, config.Name)
+	}
+	registry.configs[config.Name] = config
+	return nil
+}
+
+// MustRegister is the same as [Register] but panics on error. It is meant to be used
+// during package initialisation.
+func MustRegister(config Config) Config {
+	if err := Register(config); err != nil {
+		panic(err)
+	}
+	return config
+}
+
+// ConfigByName returns the registered [Config] with the given name.
+func ConfigByName(name string) (Config, error) {
+	registry.RLock()
+	defer registry.RUnlock()
+	config, found := registry.configs[name]
+	if !found {
+		return Config{}, fmt.Errorf("%w: %s", ErrConfigNotFound, name)
+	}
+	return config, nil
+}
+
+// Configs returns the names of all registered [Config]s in lexicographical order.
+func Configs() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	names := make([]string, 0, len(registry.configs))
+	for name := range registry.configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
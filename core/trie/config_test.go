@@ -0,0 +1,137 @@
+package trie_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/crypto"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/core/trie"
+	"github.com/bits-and-blooms/bitset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// addHash is a cheap stand-in for a cryptographic hash function.
+func addHash(a, b *felt.Felt) *felt.Felt {
+	return new(felt.Felt).Add(a, b)
+}
+
+func TestConfig(t *testing.T) {
+	t.Run("missing hash function", func(t *testing.T) {
+		assert.Error(t, trie.Config{Name: "no-hash", Height: 251}.RunOnTemp(func(_ *trie.Trie) error {
+			return nil
+		}))
+	})
+
+	t.Run("invalid height", func(t *testing.T) {
+		config := trie.Config{Name: "too-high", Hash: crypto.Pedersen, Height: felt.Bits + 1}
+		assert.Error(t, config.Validate())
+	})
+
+	t.Run("default config matches RunOnTempTrie", func(t *testing.T) {
+		key, value := new(felt.Felt).SetUint64(0b1101), new(felt.Felt).SetUint64(42)
+
+		var expected *felt.Felt
+		require.NoError(t, trie.RunOnTempTrie(251, func(tempTrie *trie.Trie) error {
+			_, err := tempTrie.Put(key, value)
+			require.NoError(t, err)
+			expected, err = tempTrie.Root()
+			return err
+		}))
+
+		config := trie.Config{Name: "pedersen", Hash: crypto.Pedersen, Height: 251}
+		require.NoError(t, config.RunOnTemp(func(tempTrie *trie.Trie) error {
+			_, err := tempTrie.Put(key, value)
+			require.NoError(t, err)
+			root, err := tempTrie.Root()
+			require.NoError(t, err)
+			assert.Equal(t, expected, root)
+			return nil
+		}))
+	})
+
+	t.Run("fake hash", func(t *testing.T) {
+		config := trie.Config{Name: "pedersen", Hash: crypto.Pedersen, Height: 3}.WithHash(addHash)
+		require.NoError(t, config.RunOnTemp(func(tempTrie *trie.Trie) error {
+			// 0b000 and 0b111 diverge at the root, each child is an edge of length 2
+			_, err := tempTrie.Put(new(felt.Felt).SetUint64(0b000), new(felt.Felt).SetUint64(1))
+			require.NoError(t, err)
+			_, err = tempTrie.Put(new(felt.Felt).SetUint64(0b111), new(felt.Felt).SetUint64(2))
+			require.NoError(t, err)
+
+			// left: 1 + path(0b00) + len(2), right: 2 + path(0b11) + len(2)
+			root, err := tempTrie.Root()
+			require.NoError(t, err)
+			assert.Equal(t, new(felt.Felt).SetUint64((1+0+2)+(2+3+2)), root)
+			return nil
+		}))
+	})
+
+	t.Run("custom key encoding", func(t *testing.T) {
+		encoded := 0
+		config := trie.Config{
+			Name:   "counting",
+			Hash:   addHash,
+			Height: 8,
+			KeyEncoding: func(key *felt.Felt, height uint) *bitset.BitSet {
+				encoded++
+				return trie.FeltKeyEncoding(key, height)
+			},
+			KeyDecoding: trie.FeltKeyDecoding,
+		}
+		require.NoError(t, config.RunOnTemp(func(tempTrie *trie.Trie) error {
+			_, err := tempTrie.Put(new(felt.Felt).SetUint64(1), new(felt.Felt).SetUint64(1))
+			return err
+		}))
+		assert.Positive(t, encoded)
+	})
+
+	t.Run("key encoding without a key decoding", func(t *testing.T) {
+		config := trie.Config{Name: "no-decoding", Hash: addHash, Height: 8, KeyEncoding: trie.FeltKeyEncoding}
+		assert.Error(t, config.Validate())
+	})
+
+	t.Run("decode keys", func(t *testing.T) {
+		key := new(felt.Felt).SetUint64(0b1101)
+		config := trie.Config{Name: "pedersen", Hash: crypto.Pedersen, Height: 251}
+		assert.Equal(t, key, config.DecodeKey(trie.FeltKeyEncoding(key, config.Height)))
+
+		// keys are stored off by one
+		config.KeyEncoding = func(key *felt.Felt, height uint) *bitset.BitSet {
+			return trie.FeltKeyEncoding(new(felt.Felt).Add(key, new(felt.Felt).SetUint64(1)), height)
+		}
+		config.KeyDecoding = func(path *bitset.BitSet) *felt.Felt {
+			return new(felt.Felt).Sub(trie.FeltKeyDecoding(path), new(felt.Felt).SetUint64(1))
+		}
+		assert.Equal(t, key, config.DecodeKey(config.KeyEncoding(key, config.Height)))
+	})
+}
+
+func TestRegistry(t *testing.T) {
+	config := trie.Config{Name: "registry-test", Hash: addHash, Height: 64}
+	require.NoError(t, trie.Register(config))
+
+	t.Run("duplicate name", func(t *testing.T) {
+		err := trie.Register(config)
+		assert.True(t, errors.Is(err, trie.ErrConfigAlreadyDefined))
+	})
+
+	t.Run("empty name", func(t *testing.T) {
+		assert.Error(t, trie.Register(trie.Config{Hash: addHash, Height: 64}))
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		assert.Error(t, trie.Register(trie.Config{Name: "registry-test-no-hash", Height: 64}))
+	})
+
+	t.Run("lookup", func(t *testing.T) {
+		got, err := trie.ConfigByName("registry-test")
+		require.NoError(t, err)
+		assert.Equal(t, config.Height, got.Height)
+		assert.Contains(t, trie.Configs(), "registry-test")
+
+		_, err = trie.ConfigByName("unknown")
+		assert.True(t, errors.Is(err, trie.ErrConfigNotFound))
+	})
+}
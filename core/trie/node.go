@@ -15,7 +15,7 @@ type Node struct {
 }
 
 // Hash calculates the hash of a [Node]
-func (n *Node) Hash(path *bitset.BitSet, hashFunc hashFunc) *felt.Felt {
+func (n *Node) Hash(path *bitset.BitSet, hashFunc HashFunc) *felt.Felt {
 	if path.Len() == 0 {
 		return n.Value
 	}
@@ -18,8 +18,6 @@ type Storage interface {
 	Delete(key *bitset.BitSet) error
 }
 
-type hashFunc func(*felt.Felt, *felt.Felt) *felt.Felt
-
 // Trie is a dense Merkle Patricia Trie (i.e., all internal nodes have two children).
 //
 // This implementation allows for a "flat" storage by keying nodes on their path rather than
@@ -42,44 +40,47 @@ type Trie struct {
 	rootKey *bitset.BitSet
 	maxKey  *felt.Felt
 	storage Storage
-	hash    hashFunc
+	hash    HashFunc
+	encode  KeyEncoding
 }
 
 type NewTrieFunc func(Storage, uint, *bitset.BitSet) (*Trie, error)
 
 func NewTriePedersen(storage Storage, height uint, rootKey *bitset.BitSet) (*Trie, error) {
-	return newTrie(storage, height, rootKey, crypto.Pedersen)
+	return Config{Name: "pedersen", Hash: crypto.Pedersen, Height: height}.New(storage, rootKey)
 }
 
 func NewTriePoseidon(storage Storage, height uint, rootKey *bitset.BitSet) (*Trie, error) {
-	return newTrie(storage, height, rootKey, crypto.Poseidon)
+	return Config{Name: "poseidon", Hash: crypto.Poseidon, Height: height}.New(storage, rootKey)
 }
 
-func newTrie(storage Storage, height uint, rootKey *bitset.BitSet, hash hashFunc) (*Trie, error) {
-	if height > felt.Bits {
-		return nil, fmt.Errorf("max trie height is %d, got: %d", felt.Bits, height)
+func newTrie(storage Storage, rootKey *bitset.BitSet, config Config) (*Trie, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	encode := config.KeyEncoding
+	if encode == nil {
+		encode = FeltKeyEncoding
 	}
 
 	// maxKey is 2^height - 1
-	maxKey := new(felt.Felt).Exp(new(felt.Felt).SetUint64(2), new(big.Int).SetUint64(uint64(height)))
+	maxKey := new(felt.Felt).Exp(new(felt.Felt).SetUint64(2), new(big.Int).SetUint64(uint64(config.Height)))
 	maxKey.Sub(maxKey, new(felt.Felt).SetUint64(1))
 
 	return &Trie{
 		storage: storage,
-		height:  height,
+		height:  config.Height,
 		rootKey: rootKey,
 		maxKey:  maxKey,
-		hash:    hash,
+		hash:    config.Hash,
+		encode:  encode,
 	}, nil
 }
 
 // RunOnTempTrie creates an in-memory Trie of height `height` and runs `do` on that Trie
 func RunOnTempTrie(height uint, do func(*Trie) error) error {
-	trie, err := NewTriePedersen(newMemStorage(), height, nil)
-	if err != nil {
-		return err
-	}
-	return do(trie)
+	return Config{Name: "pedersen", Hash: crypto.Pedersen, Height: height}.RunOnTemp(do)
 }
 
 // feltToBitSet Converts a key, given in felt, to a bitset which when followed on a [Trie],
@@ -89,8 +90,7 @@ func (t *Trie) feltToBitSet(k *felt.Felt) *bitset.BitSet {
 		return nil
 	}
 
-	kBits := k.Bits()
-	return bitset.FromWithLength(t.height, kBits[:])
+	return t.encode(k, t.height)
 }
 
 // findCommonKey finds the set of common MSB bits in two key bitsets.
@@ -0,0 +1,144 @@
+package trie_test
+
+import (
+	"encoding/binary"
+	"math/big"
+	"testing"
+
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/core/trie"
+	"github.com/NethermindEth/juno/db"
+	"github.com/NethermindEth/juno/db/pebble"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// trieOp is a single Put on a trie, a zero value deletes the key.
+type trieOp struct {
+	key   *felt.Felt
+	value *felt.Felt
+}
+
+const trieOpSize = 4
+
+// decodeTrieOps interprets the fuzzer input as a sequence of Puts. Keys and values are
+// 16 bits wide so that sequences often touch the same keys and values are often zero.
+// The key is shifted by the first byte of the input to place the differing bits at
+// arbitrary positions of the trie.
+func decodeTrieOps(data []byte, height uint) []trieOp {
+	if len(data) == 0 {
+		return nil
+	}
+
+	shift := uint(data[0]) % (height - 15)
+	data = data[1:]
+
+	ops := make([]trieOp, 0, len(data)/trieOpSize)
+	for ; len(data) >= trieOpSize; data = data[trieOpSize:] {
+		key := new(felt.Felt).SetUint64(uint64(binary.BigEndian.Uint16(data)))
+		key.Mul(key, new(felt.Felt).Exp(new(felt.Felt).SetUint64(2), new(big.Int).SetUint64(uint64(shift))))
+		ops = append(ops, trieOp{
+			key:   key,
+			value: new(felt.Felt).SetUint64(uint64(data[2]) % 4 * uint64(data[3])),
+		})
+	}
+	return ops
+}
+
+func FuzzTrie(f *testing.F) {
+	f.Add([]byte{0, 0, 1, 1, 1, 0, 2, 1, 1})
+	f.Add([]byte{235, 0, 0, 1, 1, 255, 255, 1, 1, 0, 0, 0, 0})
+	// siblings which share all but the last bit, then deleting one of them
+	f.Add([]byte{0, 0, 2, 1, 1, 0, 3, 1, 1, 0, 2, 0, 0})
+	// keys on opposite sides of the root, then deleting both
+	f.Add([]byte{0, 0, 0, 1, 1, 255, 255, 1, 1, 0, 0, 0, 0, 255, 255, 0, 0})
+	// the same key written many times
+	f.Add([]byte{7, 1, 1, 1, 1, 1, 1, 2, 2, 1, 1, 3, 3, 1, 1, 0, 0})
+	// three keys forming an edge that has to be shortened and extended on delete
+	f.Add([]byte{0, 0, 8, 1, 1, 0, 12, 1, 1, 0, 9, 1, 1, 0, 12, 0, 0, 0, 9, 0, 0, 0, 8, 0, 0})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, height := range []uint{16, 251} {
+			ops := decodeTrieOps(data, height)
+			if len(ops) == 0 {
+				return
+			}
+			testTrieOps(t, height, ops)
+		}
+	})
+}
+
+// testTrieOps applies ops to an in-memory trie and to a trie backed by a database
+// transaction and checks that both behave the same and agree with a simple map. The
+// database backed trie is also reopened from its storage and read back.
+func testTrieOps(t *testing.T, height uint, ops []trieOp) {
+	latest := make(map[felt.Felt]*felt.Felt)
+
+	var memRoot *felt.Felt
+	require.NoError(t, pebble.NewMemTest().Update(func(txn db.Transaction) error {
+		storage := core.NewTransactionStorage(txn, []byte{0})
+		dbTrie, err := trie.NewTriePedersen(storage, height, nil)
+		require.NoError(t, err)
+
+		return trie.RunOnTempTrie(height, func(memTrie *trie.Trie) error {
+			for _, op := range ops {
+				_, err = memTrie.Put(op.key, op.value)
+				require.NoError(t, err)
+				_, err = dbTrie.Put(op.key, op.value)
+				require.NoError(t, err)
+
+				if op.value.IsZero() {
+					delete(latest, *op.key)
+				} else {
+					latest[*op.key] = op.value
+				}
+
+				memRoot, err = memTrie.Root()
+				require.NoError(t, err)
+				dbRoot, err := dbTrie.Root()
+				require.NoError(t, err)
+				require.Equal(t, memRoot, dbRoot, "roots differ after putting %s at %s", op.value, op.key)
+
+				memValue, memErr := memTrie.Get(op.key)
+				dbValue, dbErr := dbTrie.Get(op.key)
+				require.Equal(t, memErr == nil, dbErr == nil, "tries disagree on the presence of %s", op.key)
+				require.Equal(t, memValue, dbValue, "tries disagree on the value at %s", op.key)
+			}
+
+			reopened, err := trie.NewTriePedersen(storage, height, dbTrie.RootKey())
+			require.NoError(t, err)
+			reopenedRoot, err := reopened.Root()
+			require.NoError(t, err)
+			require.Equal(t, memRoot, reopenedRoot)
+
+			for _, op := range ops {
+				expected, found := latest[*op.key]
+				for name, tr := range map[string]*trie.Trie{"memory": memTrie, "database": dbTrie, "reopened": reopened} {
+					value, err := tr.Get(op.key)
+					if !found {
+						assert.Error(t, err, "deleted key %s is still present in the %s trie", op.key, name)
+						continue
+					}
+					require.NoError(t, err)
+					assert.Equal(t, expected, value, "unexpected value at %s in the %s trie", op.key, name)
+				}
+			}
+			return nil
+		})
+	}))
+
+	// a trie built from the surviving leaves only must have the same root
+	require.NoError(t, trie.RunOnTempTrie(height, func(fresh *trie.Trie) error {
+		for key, value := range latest {
+			key := key
+			_, err := fresh.Put(&key, value)
+			require.NoError(t, err)
+		}
+
+		root, err := fresh.Root()
+		require.NoError(t, err)
+		assert.Equal(t, memRoot, root)
+		return nil
+	}))
+}
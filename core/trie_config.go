@@ -0,0 +1,51 @@
+package core
+
+import (
+	"github.com/NethermindEth/juno/core/crypto"
+	"github.com/NethermindEth/juno/core/trie"
+)
+
+// Configurations of the tries used by Starknet. They are registered in the [trie] registry
+// so they can be looked up by name, e.g. for benchmarks.
+var (
+	contractStorageTrieConfig = trie.MustRegister(trie.Config{
+		Name:   "contract-storage",
+		Hash:   crypto.Pedersen,
+		Height: contractStorageTrieHeight,
+	})
+	stateTrieConfig = trie.MustRegister(trie.Config{
+		Name:   "state",
+		Hash:   crypto.Pedersen,
+		Height: globalTrieHeight,
+	})
+	classesTrieConfig = trie.MustRegister(trie.Config{
+		Name:   "classes",
+		Hash:   crypto.Poseidon,
+		Height: globalTrieHeight,
+	})
+	transactionCommitmentTrieConfig = trie.MustRegister(trie.Config{
+		Name:   "transaction-commitment",
+		Hash:   crypto.Pedersen,
+		Height: commitmentTrieHeight,
+	})
+	eventCommitmentTrieConfig = trie.MustRegister(trie.Config{
+		Name:   "event-commitment",
+		Hash:   crypto.Pedersen,
+		Height: commitmentTrieHeight,
+	})
+	transactionCommitmentPoseidonTrieConfig = trie.MustRegister(trie.Config{
+		Name:   "transaction-commitment-poseidon",
+		Hash:   crypto.Poseidon,
+		Height: commitmentTrieHeight,
+	})
+	eventCommitmentPoseidonTrieConfig = trie.MustRegister(trie.Config{
+		Name:   "event-commitment-poseidon",
+		Hash:   crypto.Poseidon,
+		Height: commitmentTrieHeight,
+	})
+	receiptCommitmentTrieConfig = trie.MustRegister(trie.Config{
+		Name:   "receipt-commitment",
+		Hash:   crypto.Poseidon,
+		Height: commitmentTrieHeight,
+	})
+)
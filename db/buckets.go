@@ -22,6 +22,17 @@ const (
 	ReceiptsByBlockNumberAndIndex           // maps block number and index to transaction receipt
 	StateUpdatesByBlockNumber
 	ClassesTrie
+	CompiledClass             // maps compiled class hashes to compiled classes
+	SchemaVersion             // number of migrations applied to the database
+	L1HandlerTxnHashByMsgHash // maps L1 to L2 message hashes to L1 handler transaction hashes
+	L2ToL1MessagesByHash      // indexes L2 to L1 messages by message hash
+	L2ToL1MessagesByRecipient // indexes L2 to L1 messages by L1 recipient address
+	AddressActivity           // indexes transactions by the addresses of their senders and contracts
+	AddressActivityHeight     // number of blocks indexed in AddressActivity
+	ClassDeclarations         // maps class hashes to where they were declared
+	ClassInstances            // indexes contracts by the classes they use or used
+	StateUpdatesPrunedHeight  // number of oldest blocks whose state updates were pruned
+	StateReverseDiffs         // maps block numbers to what their state updates overwrote
 )
 
 // Key flattens a prefix and series of byte arrays into a single []byte.
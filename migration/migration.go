@@ -0,0 +1,373 @@
+// Package migration upgrades databases written by older versions of Juno to the current storage
+// format.
+package migration
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db"
+	"github.com/NethermindEth/juno/encoder"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// migration migrates at most batchSize entries, starting at the key start or at the first entry
+// if start is nil, and returns the key to resume at, or nil once all entries are migrated.
+type migration func(txn db.Transaction, start []byte, batchSize uint64) ([]byte, error)
+
+// migrations are applied in order, each one in batches that are committed in separate database
+// transactions. A migration interrupted between batches is applied again from the start, so
+// migrations must be idempotent. The schema version of a database is the number of migrations
+// applied to it, so new migrations must only be appended.
+var migrations = []migration{
+	receiptFeeUnits,
+	l1HandlerMessageIndex,
+	l2ToL1MessageIndex,
+	classIndexes,
+}
+
+// SchemaVersion returns the number of migrations applied to the database.
+func SchemaVersion(target db.DB) (uint64, error) {
+	var version uint64
+	err := target.View(func(txn db.Transaction) error {
+		return txn.Get(db.SchemaVersion.Key(), func(val []byte) error {
+			version = binary.BigEndian.Uint64(val)
+			return nil
+		})
+	})
+	if errors.Is(err, db.ErrKeyNotFound) {
+		return 0, nil
+	}
+	return version, err
+}
+
+// CheckSchemaVersion returns an error if the database is not of the latest schema version.
+func CheckSchemaVersion(target db.DB) error {
+	version, err := SchemaVersion(target)
+	if err != nil {
+		return err
+	}
+	if version < uint64(len(migrations)) {
+		return fmt.Errorf("database schema version %d is out of date, run the node to migrate it to version %d",
+			version, len(migrations))
+	} else if version > uint64(len(migrations)) {
+		return newerVersionError(version)
+	}
+	return nil
+}
+
+func newerVersionError(version uint64) error {
+	return fmt.Errorf("database schema version %d is newer than the latest known version %d",
+		version, len(migrations))
+}
+
+// MigrateIfNeeded applies the migrations that were not applied to the database yet, migrating at
+// most batchSize entries in a single write transaction. Migrations decode stored transactions, so
+// the core types must be registered with the encoder first, which [blockchain.New] does.
+func MigrateIfNeeded(target db.DB, batchSize uint64) error {
+	if batchSize == 0 {
+		return errors.New("migration batch size must be positive")
+	}
+
+	version, err := SchemaVersion(target)
+	if err != nil {
+		return err
+	}
+	if version > uint64(len(migrations)) {
+		return newerVersionError(version)
+	}
+
+	for i := version; i < uint64(len(migrations)); i++ {
+		var next []byte
+		for done := false; !done; {
+			if err = target.Update(func(txn db.Transaction) error {
+				if next, err = migrations[i](txn, next, batchSize); err != nil {
+					return err
+				}
+				if done = next == nil; done {
+					return txn.Set(db.SchemaVersion.Key(), binary.BigEndian.AppendUint64(nil, i+1))
+				}
+				return nil
+			}); err != nil {
+				return fmt.Errorf("migration %d: %w", i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// forEachInBatch calls fn with at most batchSize entries of the bucket prefix, starting at the key
+// start or at the first entry if start is nil. It returns the key of the entry to resume at, or
+// nil if there are no entries left. The key and value passed to fn are only valid during the call.
+func forEachInBatch(txn db.Transaction, prefix, start []byte, batchSize uint64,
+	fn func(key, val []byte) error,
+) ([]byte, error) {
+	iterator, err := txn.NewIterator()
+	if err != nil {
+		return nil, err
+	}
+
+	if start == nil {
+		start = prefix
+	}
+	var count uint64
+	for iterator.Seek(start); iterator.Valid(); iterator.Next() {
+		key := iterator.Key()
+		if !bytes.HasPrefix(key, prefix) {
+			break
+		}
+		if count == batchSize {
+			return append([]byte(nil), key...), iterator.Close()
+		}
+
+		val, err := iterator.Value()
+		if err != nil {
+			return nil, db.CloseAndWrapOnError(iterator.Close, err)
+		}
+		if err = fn(key, val); err != nil {
+			return nil, db.CloseAndWrapOnError(iterator.Close, err)
+		}
+		count++
+	}
+	return nil, iterator.Close()
+}
+
+// receiptFeeUnits sets the fee unit of the receipts stored before receipts had one. Receipts
+// without a fee unit are decoded as paid in wei, which is wrong for version 3 transactions.
+//
+// The execution status, the revert reason and the newer builtin counters of those receipts are
+// left as decoded: they are absent from blocks of the protocol versions that were synced before
+// the fields were added, and Succeeded and zero counters are their correct values.
+func receiptFeeUnits(txn db.Transaction, start []byte, batchSize uint64) ([]byte, error) {
+	type update struct {
+		key     []byte
+		receipt *core.TransactionReceipt
+	}
+	var updates []update
+
+	prefix := db.ReceiptsByBlockNumberAndIndex.Key()
+	next, err := forEachInBatch(txn, prefix, start, batchSize, func(key, val []byte) error {
+		receipt := new(core.TransactionReceipt)
+		if err := encoder.Unmarshal(val, receipt); err != nil {
+			return err
+		}
+
+		// transactions are stored under the same block number and index as their receipts
+		var tx core.Transaction
+		if err := txn.Get(db.TransactionsByBlockNumberAndIndex.Key(key[len(prefix):]), func(val []byte) error {
+			return encoder.Unmarshal(val, &tx)
+		}); err != nil {
+			return err
+		}
+
+		if feeUnit := core.FeeUnitOf(tx); feeUnit != receipt.FeeUnit {
+			receipt.FeeUnit = feeUnit
+			updates = append(updates, update{key: append([]byte(nil), key...), receipt: receipt})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, u := range updates {
+		val, err := encoder.Marshal(u.receipt)
+		if err != nil {
+			return nil, err
+		}
+		if err = txn.Set(u.key, val); err != nil {
+			return nil, err
+		}
+	}
+	return next, nil
+}
+
+// l1HandlerMessageIndex indexes the stored L1 handler transactions by the hash of the message
+// they consume.
+func l1HandlerMessageIndex(txn db.Transaction, start []byte, batchSize uint64) ([]byte, error) {
+	index := make(map[common.Hash]*felt.Felt)
+	next, err := forEachInBatch(txn, db.TransactionsByBlockNumberAndIndex.Key(), start, batchSize,
+		func(_, val []byte) error {
+			var tx core.Transaction
+			if err := encoder.Unmarshal(val, &tx); err != nil {
+				return err
+			}
+
+			if l1Handler, ok := tx.(*core.L1HandlerTransaction); ok {
+				if msg := l1Handler.Message(); msg != nil {
+					index[msg.Hash()] = l1Handler.Hash()
+				}
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	for msgHash, txHash := range index {
+		if err = txn.Set(db.L1HandlerTxnHashByMsgHash.Key(msgHash.Bytes()), txHash.Marshal()); err != nil {
+			return nil, err
+		}
+	}
+	return next, nil
+}
+
+// l2ToL1MessageIndex indexes the L2 to L1 messages of the stored receipts by message hash and by
+// L1 recipient, each under the location of the message:
+//
+// [db.L2ToL1MessagesByHash](MessageHash, BlockNumber, TransactionIndex, MessageIndex) -> ()
+// [db.L2ToL1MessagesByRecipient](Recipient, BlockNumber, TransactionIndex, MessageIndex) -> ()
+func l2ToL1MessageIndex(txn db.Transaction, start []byte, batchSize uint64) ([]byte, error) {
+	var keys [][]byte
+	prefix := db.ReceiptsByBlockNumberAndIndex.Key()
+	next, err := forEachInBatch(txn, prefix, start, batchSize, func(key, val []byte) error {
+		receipt := new(core.TransactionReceipt)
+		if err := encoder.Unmarshal(val, receipt); err != nil {
+			return err
+		}
+
+		// receipts are stored under their block number and index
+		bnIndex := key[len(prefix):]
+		for i, msg := range receipt.L2ToL1Message {
+			location := binary.BigEndian.AppendUint64(append([]byte{}, bnIndex...), uint64(i))
+			msgHash := msg.Hash()
+			keys = append(keys, db.L2ToL1MessagesByHash.Key(msgHash.Bytes(), location),
+				db.L2ToL1MessagesByRecipient.Key(msg.To.Bytes(), location))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		if err = txn.Set(key, nil); err != nil {
+			return nil, err
+		}
+	}
+	return next, nil
+}
+
+// classIndexes indexes the classes declared and the contracts deployed, or whose class was
+// replaced, in the blocks whose state updates are stored, see [blockchain.Blockchain.Store]:
+//
+// [db.ClassDeclarations](ClassHash) -> (BlockNumber, TransactionHash)
+// [db.ClassInstances](ClassHash, ContractAddress) -> (BlockNumber)
+//
+// The blocks whose state updates were pruned are not indexed.
+func classIndexes(txn db.Transaction, start []byte, batchSize uint64) ([]byte, error) {
+	// classes and instances are indexed the first time they appear only. Blocks are migrated in
+	// ascending order, so the ones indexed by the previous batches appeared first.
+	index := make(map[string][]byte)
+	setIfAbsent := func(key, value []byte) error {
+		if _, found := index[string(key)]; found {
+			return nil
+		}
+		err := txn.Get(key, func([]byte) error { return nil })
+		if errors.Is(err, db.ErrKeyNotFound) {
+			index[string(key)] = value
+			return nil
+		}
+		return err
+	}
+
+	prefix := db.StateUpdatesByBlockNumber.Key()
+	next, err := forEachInBatch(txn, prefix, start, batchSize, func(key, val []byte) error {
+		update := new(core.StateUpdate)
+		if err := encoder.Unmarshal(val, update); err != nil {
+			return err
+		}
+
+		numBytes := append([]byte{}, key[len(prefix):]...)
+		declarers, err := classDeclarers(txn, numBytes)
+		if err != nil {
+			return err
+		}
+
+		diff := update.StateDiff
+		declared := append([]*felt.Felt{}, diff.DeclaredV0Classes...)
+		for _, class := range diff.DeclaredV1Classes {
+			declared = append(declared, class.ClassHash)
+		}
+		for _, contract := range diff.DeployedContracts {
+			declared = append(declared, contract.ClassHash)
+		}
+		for _, classHash := range declared {
+			declaration := numBytes
+			if txHash := declarers[*classHash]; txHash != nil {
+				declaration = append(append([]byte{}, numBytes...), txHash.Marshal()...)
+			}
+			if err = setIfAbsent(db.ClassDeclarations.Key(classHash.Marshal()), declaration); err != nil {
+				return err
+			}
+		}
+
+		for _, contract := range diff.DeployedContracts {
+			if err = setIfAbsent(db.ClassInstances.Key(contract.ClassHash.Marshal(), contract.Address.Marshal()),
+				numBytes); err != nil {
+				return err
+			}
+		}
+		for _, replaced := range diff.ReplacedClasses {
+			if err = setIfAbsent(db.ClassInstances.Key(replaced.ClassHash.Marshal(), replaced.Address.Marshal()),
+				numBytes); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range index {
+		if err = txn.Set([]byte(key), value); err != nil {
+			return nil, err
+		}
+	}
+	return next, nil
+}
+
+// classDeclarers maps the classes declared by the declare and deploy transactions of a block to
+// the hash of the first transaction declaring them.
+func classDeclarers(txn db.Transaction, numBytes []byte) (map[felt.Felt]*felt.Felt, error) {
+	iterator, err := txn.NewIterator()
+	if err != nil {
+		return nil, err
+	}
+
+	declarers := make(map[felt.Felt]*felt.Felt)
+	prefix := db.TransactionsByBlockNumberAndIndex.Key(numBytes)
+	for iterator.Seek(prefix); iterator.Valid(); iterator.Next() {
+		if !bytes.HasPrefix(iterator.Key(), prefix) {
+			break
+		}
+
+		val, err := iterator.Value()
+		if err != nil {
+			return nil, db.CloseAndWrapOnError(iterator.Close, err)
+		}
+		var tx core.Transaction
+		if err = encoder.Unmarshal(val, &tx); err != nil {
+			return nil, db.CloseAndWrapOnError(iterator.Close, err)
+		}
+
+		var classHash *felt.Felt
+		switch tx := tx.(type) {
+		case *core.DeclareTransaction:
+			classHash = tx.ClassHash
+		case *core.DeployTransaction:
+			classHash = tx.ClassHash
+		default:
+			continue
+		}
+		if _, found := declarers[*classHash]; !found {
+			declarers[*classHash] = tx.Hash()
+		}
+	}
+	return declarers, iterator.Close()
+}
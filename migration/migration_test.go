@@ -0,0 +1,192 @@
+package migration_test
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/NethermindEth/juno/blockchain"
+	"github.com/NethermindEth/juno/clients/feeder"
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db"
+	"github.com/NethermindEth/juno/db/pebble"
+	"github.com/NethermindEth/juno/encoder"
+	"github.com/NethermindEth/juno/migration"
+	adaptfeeder "github.com/NethermindEth/juno/starknetdata/feeder"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateIfNeeded(t *testing.T) {
+	testDB := pebble.NewMemTest()
+	t.Cleanup(func() {
+		require.NoError(t, testDB.Close())
+	})
+	// registers the core types with the encoder
+	chain := blockchain.New(testDB, utils.INTEGRATION)
+
+	// made-up version 3 transaction, whose fee is paid in FRI
+	v3Tx := &core.InvokeTransaction{
+		TransactionHash: utils.HexToFelt(t, "0x3a94edbc959af535b175019388eac611acb11cac0be843862050c79245c3631"),
+		SenderAddress:   utils.HexToFelt(t, "0x3f6f3bc663aedc5285d6013cc3ffcbc4341d86ab488b8b68d297f8258793c41"),
+		Nonce:           utils.HexToFelt(t, "0x8"),
+		Version:         utils.HexToFelt(t, "0x3"),
+	}
+	v3Receipt := &core.TransactionReceipt{
+		TransactionHash: v3Tx.TransactionHash,
+		Fee:             utils.HexToFelt(t, "0x2a"),
+		FeeUnit:         core.FRI,
+	}
+	const v3BlockNumber = 5
+
+	mainnetClient, mainnetCloseFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(mainnetCloseFn)
+	block1059, err := adaptfeeder.New(mainnetClient).BlockByNumber(context.Background(), 1059)
+	require.NoError(t, err)
+	const l1HandlerIndex = 14
+	l1Handler, ok := block1059.Transactions[l1HandlerIndex].(*core.L1HandlerTransaction)
+	require.True(t, ok)
+	block0, err := adaptfeeder.New(mainnetClient).BlockByNumber(context.Background(), 0)
+	require.NoError(t, err)
+	const l2ToL1SenderIndex = 2
+	require.NotEmpty(t, block0.Receipts[l2ToL1SenderIndex].L2ToL1Message)
+	deploy, ok := block0.Transactions[0].(*core.DeployTransaction)
+	require.True(t, ok)
+	stateUpdate0, err := adaptfeeder.New(mainnetClient).StateUpdate(context.Background(), 0)
+	require.NoError(t, err)
+	// the first contract deployed in the genesis block replaces its class in the next block
+	replacingClassHash := utils.HexToFelt(t, "0x1234")
+	stateUpdate1 := &core.StateUpdate{
+		StateDiff: &core.StateDiff{
+			ReplacedClasses: []core.ReplacedClass{{Address: deploy.ContractAddress, ClassHash: replacingClassHash}},
+		},
+	}
+
+	storeTransactionAndReceipt := func(txn db.Transaction, key []byte, tx core.Transaction,
+		receipt *core.TransactionReceipt,
+	) error {
+		txBytes, err := encoder.Marshal(tx)
+		if err != nil {
+			return err
+		}
+		if err = txn.Set(db.TransactionsByBlockNumberAndIndex.Key(key), txBytes); err != nil {
+			return err
+		}
+		receiptBytes, err := encoder.Marshal(receipt)
+		if err != nil {
+			return err
+		}
+		return txn.Set(db.ReceiptsByBlockNumberAndIndex.Key(key), receiptBytes)
+	}
+
+	// store transactions and receipts as they were stored before receipts had a fee unit and
+	// before messages were indexed
+	key := binary.BigEndian.AppendUint64(binary.BigEndian.AppendUint64(nil, v3BlockNumber), 0)
+	l1HandlerKey := binary.BigEndian.AppendUint64(binary.BigEndian.AppendUint64(nil, block1059.Number), l1HandlerIndex)
+	l2ToL1SenderKey := binary.BigEndian.AppendUint64(binary.BigEndian.AppendUint64(nil, block0.Number), l2ToL1SenderIndex)
+	deployKey := binary.BigEndian.AppendUint64(binary.BigEndian.AppendUint64(nil, block0.Number), 0)
+	oldReceipt := *v3Receipt
+	oldReceipt.FeeUnit = core.WEI
+	require.NoError(t, testDB.Update(func(txn db.Transaction) error {
+		if err := storeTransactionAndReceipt(txn, key, v3Tx, &oldReceipt); err != nil {
+			return err
+		}
+		if err := storeTransactionAndReceipt(txn, l1HandlerKey, l1Handler, block1059.Receipts[l1HandlerIndex]); err != nil {
+			return err
+		}
+		if err := storeTransactionAndReceipt(txn, l2ToL1SenderKey, block0.Transactions[l2ToL1SenderIndex],
+			block0.Receipts[l2ToL1SenderIndex]); err != nil {
+			return err
+		}
+		if err := storeTransactionAndReceipt(txn, deployKey, deploy, block0.Receipts[0]); err != nil {
+			return err
+		}
+		for number, update := range []*core.StateUpdate{stateUpdate0, stateUpdate1} {
+			updateBytes, err := encoder.Marshal(update)
+			if err != nil {
+				return err
+			}
+			numBytes := binary.BigEndian.AppendUint64(nil, uint64(number))
+			if err = txn.Set(db.StateUpdatesByBlockNumber.Key(numBytes), updateBytes); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+
+	version, err := migration.SchemaVersion(testDB)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), version)
+	assert.EqualError(t, migration.CheckSchemaVersion(testDB),
+		"database schema version 0 is out of date, run the node to migrate it to version 4")
+
+	// every entry is migrated in its own transaction
+	require.NoError(t, migration.MigrateIfNeeded(testDB, 1))
+
+	version, err = migration.SchemaVersion(testDB)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), version)
+	require.NoError(t, migration.CheckSchemaVersion(testDB))
+
+	receipt := new(core.TransactionReceipt)
+	require.NoError(t, testDB.View(func(txn db.Transaction) error {
+		return txn.Get(db.ReceiptsByBlockNumberAndIndex.Key(key), func(val []byte) error {
+			return encoder.Unmarshal(val, receipt)
+		})
+	}))
+	assert.Equal(t, v3Receipt, receipt)
+
+	msgHash := l1Handler.Message().Hash()
+	l1HandlerTxnHash, err := chain.L1HandlerTxnHash(&msgHash)
+	require.NoError(t, err)
+	assert.Equal(t, l1Handler.Hash(), l1HandlerTxnHash)
+
+	l2ToL1Msg := block0.Receipts[l2ToL1SenderIndex].L2ToL1Message[0]
+	l2ToL1MsgHash := l2ToL1Msg.Hash()
+	records, err := chain.L2ToL1MessagesByHash(&l2ToL1MsgHash)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, l2ToL1Msg, records[0].Message)
+	assert.Equal(t, block0.Transactions[l2ToL1SenderIndex].Hash(), records[0].TransactionHash)
+
+	declaration, err := chain.ClassDeclaration(deploy.ClassHash)
+	require.NoError(t, err)
+	assert.Equal(t, &blockchain.ClassDeclaration{BlockNumber: 0, TransactionHash: deploy.Hash()}, declaration)
+
+	instanceBlockNumber := func(classHash *felt.Felt) uint64 {
+		var number uint64
+		require.NoError(t, testDB.View(func(txn db.Transaction) error {
+			return txn.Get(db.ClassInstances.Key(classHash.Marshal(), deploy.ContractAddress.Marshal()),
+				func(val []byte) error {
+					number = binary.BigEndian.Uint64(val)
+					return nil
+				})
+		}))
+		return number
+	}
+	assert.Equal(t, uint64(0), instanceBlockNumber(deploy.ClassHash))
+	assert.Equal(t, uint64(1), instanceBlockNumber(replacingClassHash))
+
+	t.Run("migrations are applied once", func(t *testing.T) {
+		require.NoError(t, migration.MigrateIfNeeded(testDB, 1))
+		version, err := migration.SchemaVersion(testDB)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(4), version)
+	})
+
+	t.Run("database of a newer version", func(t *testing.T) {
+		newerDB := pebble.NewMemTest()
+		t.Cleanup(func() {
+			require.NoError(t, newerDB.Close())
+		})
+		require.NoError(t, newerDB.Update(func(txn db.Transaction) error {
+			return txn.Set(db.SchemaVersion.Key(), binary.BigEndian.AppendUint64(nil, 100))
+		}))
+
+		const newerErr = "database schema version 100 is newer than the latest known version 4"
+		assert.EqualError(t, migration.MigrateIfNeeded(newerDB, 1), newerErr)
+		assert.EqualError(t, migration.CheckSchemaVersion(newerDB), newerErr)
+	})
+}
@@ -7,8 +7,10 @@ package mocks
 import (
 	reflect "reflect"
 
+	blockchain "github.com/NethermindEth/juno/blockchain"
 	core "github.com/NethermindEth/juno/core"
 	felt "github.com/NethermindEth/juno/core/felt"
+	common "github.com/ethereum/go-ethereum/common"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -35,6 +37,21 @@ func (m *MockReader) EXPECT() *MockReaderMockRecorder {
 	return m.recorder
 }
 
+// AggregatedStateUpdate mocks base method.
+func (m *MockReader) AggregatedStateUpdate(arg0, arg1 uint64) (*core.StateUpdate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AggregatedStateUpdate", arg0, arg1)
+	ret0, _ := ret[0].(*core.StateUpdate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AggregatedStateUpdate indicates an expected call of AggregatedStateUpdate.
+func (mr *MockReaderMockRecorder) AggregatedStateUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AggregatedStateUpdate", reflect.TypeOf((*MockReader)(nil).AggregatedStateUpdate), arg0, arg1)
+}
+
 // BlockByHash mocks base method.
 func (m *MockReader) BlockByHash(arg0 *felt.Felt) (*core.Block, error) {
 	m.ctrl.T.Helper()
@@ -95,6 +112,37 @@ func (mr *MockReaderMockRecorder) BlockHeaderByNumber(arg0 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockHeaderByNumber", reflect.TypeOf((*MockReader)(nil).BlockHeaderByNumber), arg0)
 }
 
+// ClassDeclaration mocks base method.
+func (m *MockReader) ClassDeclaration(arg0 *felt.Felt) (*blockchain.ClassDeclaration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClassDeclaration", arg0)
+	ret0, _ := ret[0].(*blockchain.ClassDeclaration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClassDeclaration indicates an expected call of ClassDeclaration.
+func (mr *MockReaderMockRecorder) ClassDeclaration(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClassDeclaration", reflect.TypeOf((*MockReader)(nil).ClassDeclaration), arg0)
+}
+
+// ClassInstances mocks base method.
+func (m *MockReader) ClassInstances(arg0, arg1 *felt.Felt, arg2 uint64) ([]*blockchain.ClassInstance, *felt.Felt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClassInstances", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*blockchain.ClassInstance)
+	ret1, _ := ret[1].(*felt.Felt)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ClassInstances indicates an expected call of ClassInstances.
+func (mr *MockReaderMockRecorder) ClassInstances(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClassInstances", reflect.TypeOf((*MockReader)(nil).ClassInstances), arg0, arg1, arg2)
+}
+
 // Head mocks base method.
 func (m *MockReader) Head() (*core.Block, error) {
 	m.ctrl.T.Helper()
@@ -140,6 +188,52 @@ func (mr *MockReaderMockRecorder) Height() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Height", reflect.TypeOf((*MockReader)(nil).Height))
 }
 
+// L1HandlerTxnHash mocks base method.
+func (m *MockReader) L1HandlerTxnHash(arg0 *common.Hash) (*felt.Felt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "L1HandlerTxnHash", arg0)
+	ret0, _ := ret[0].(*felt.Felt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// L1HandlerTxnHash indicates an expected call of L1HandlerTxnHash.
+func (mr *MockReaderMockRecorder) L1HandlerTxnHash(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "L1HandlerTxnHash", reflect.TypeOf((*MockReader)(nil).L1HandlerTxnHash), arg0)
+}
+
+// L2ToL1MessagesByHash mocks base method.
+func (m *MockReader) L2ToL1MessagesByHash(arg0 *common.Hash) ([]*blockchain.L2ToL1MessageRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "L2ToL1MessagesByHash", arg0)
+	ret0, _ := ret[0].([]*blockchain.L2ToL1MessageRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// L2ToL1MessagesByHash indicates an expected call of L2ToL1MessagesByHash.
+func (mr *MockReaderMockRecorder) L2ToL1MessagesByHash(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "L2ToL1MessagesByHash", reflect.TypeOf((*MockReader)(nil).L2ToL1MessagesByHash), arg0)
+}
+
+// L2ToL1MessagesByRecipient mocks base method.
+func (m *MockReader) L2ToL1MessagesByRecipient(arg0 common.Address, arg1 *blockchain.L2ToL1MessageLocation, arg2, arg3 uint64) ([]*blockchain.L2ToL1MessageRecord, *blockchain.L2ToL1MessageLocation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "L2ToL1MessagesByRecipient", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]*blockchain.L2ToL1MessageRecord)
+	ret1, _ := ret[1].(*blockchain.L2ToL1MessageLocation)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// L2ToL1MessagesByRecipient indicates an expected call of L2ToL1MessagesByRecipient.
+func (mr *MockReaderMockRecorder) L2ToL1MessagesByRecipient(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "L2ToL1MessagesByRecipient", reflect.TypeOf((*MockReader)(nil).L2ToL1MessagesByRecipient), arg0, arg1, arg2, arg3)
+}
+
 // Receipt mocks base method.
 func (m *MockReader) Receipt(arg0 *felt.Felt) (*core.TransactionReceipt, *felt.Felt, uint64, error) {
 	m.ctrl.T.Helper()
@@ -216,3 +310,19 @@ func (mr *MockReaderMockRecorder) TransactionByHash(arg0 interface{}) *gomock.Ca
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransactionByHash", reflect.TypeOf((*MockReader)(nil).TransactionByHash), arg0)
 }
+
+// TransactionsByAddress mocks base method.
+func (m *MockReader) TransactionsByAddress(arg0 *felt.Felt, arg1 *blockchain.TransactionLocation, arg2 uint64) ([]*felt.Felt, *blockchain.TransactionLocation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransactionsByAddress", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*felt.Felt)
+	ret1, _ := ret[1].(*blockchain.TransactionLocation)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// TransactionsByAddress indicates an expected call of TransactionsByAddress.
+func (mr *MockReaderMockRecorder) TransactionsByAddress(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransactionsByAddress", reflect.TypeOf((*MockReader)(nil).TransactionsByAddress), arg0, arg1, arg2)
+}
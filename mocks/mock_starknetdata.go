@@ -51,6 +51,21 @@ func (mr *MockStarknetDataMockRecorder) BlockByNumber(arg0, arg1 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockByNumber", reflect.TypeOf((*MockStarknetData)(nil).BlockByNumber), arg0, arg1)
 }
 
+// BlockSignature mocks base method.
+func (m *MockStarknetData) BlockSignature(arg0 context.Context, arg1 uint64) (*core.BlockSignature, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockSignature", arg0, arg1)
+	ret0, _ := ret[0].(*core.BlockSignature)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlockSignature indicates an expected call of BlockSignature.
+func (mr *MockStarknetDataMockRecorder) BlockSignature(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockSignature", reflect.TypeOf((*MockStarknetData)(nil).BlockSignature), arg0, arg1)
+}
+
 // Class mocks base method.
 func (m *MockStarknetData) Class(arg0 context.Context, arg1 *felt.Felt) (core.Class, error) {
 	m.ctrl.T.Helper()
@@ -66,6 +81,36 @@ func (mr *MockStarknetDataMockRecorder) Class(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Class", reflect.TypeOf((*MockStarknetData)(nil).Class), arg0, arg1)
 }
 
+// CompiledClass mocks base method.
+func (m *MockStarknetData) CompiledClass(arg0 context.Context, arg1 *felt.Felt) (*core.CompiledClass, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompiledClass", arg0, arg1)
+	ret0, _ := ret[0].(*core.CompiledClass)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompiledClass indicates an expected call of CompiledClass.
+func (mr *MockStarknetDataMockRecorder) CompiledClass(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompiledClass", reflect.TypeOf((*MockStarknetData)(nil).CompiledClass), arg0, arg1)
+}
+
+// SequencerPublicKey mocks base method.
+func (m *MockStarknetData) SequencerPublicKey(arg0 context.Context) (*felt.Felt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SequencerPublicKey", arg0)
+	ret0, _ := ret[0].(*felt.Felt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SequencerPublicKey indicates an expected call of SequencerPublicKey.
+func (mr *MockStarknetDataMockRecorder) SequencerPublicKey(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SequencerPublicKey", reflect.TypeOf((*MockStarknetData)(nil).SequencerPublicKey), arg0)
+}
+
 // StateUpdate mocks base method.
 func (m *MockStarknetData) StateUpdate(arg0 context.Context, arg1 uint64) (*core.StateUpdate, error) {
 	m.ctrl.T.Helper()
@@ -11,7 +11,9 @@ import (
 	"github.com/NethermindEth/juno/db"
 	"github.com/NethermindEth/juno/db/pebble"
 	"github.com/NethermindEth/juno/jsonrpc"
+	"github.com/NethermindEth/juno/migration"
 	"github.com/NethermindEth/juno/pprof"
+	"github.com/NethermindEth/juno/pruner"
 	"github.com/NethermindEth/juno/rpc"
 	"github.com/NethermindEth/juno/service"
 	adaptfeeder "github.com/NethermindEth/juno/starknetdata/feeder"
@@ -22,6 +24,10 @@ import (
 
 const (
 	defaultPprofPort = uint16(9080)
+	// addressIndexBatchSize is the number of blocks indexed by address in a single write transaction.
+	addressIndexBatchSize = uint64(1000)
+	// migrationBatchSize is the number of entries migrated in a single write transaction.
+	migrationBatchSize = uint64(10_000)
 )
 
 // Config is the top-level juno configuration.
@@ -31,6 +37,28 @@ type Config struct {
 	DatabasePath string         `mapstructure:"db-path"`
 	Network      utils.Network  `mapstructure:"network"`
 	Pprof        bool           `mapstructure:"pprof"`
+	// PruneKeepBlocks is the number of most recent blocks whose state updates are kept.
+	// Zero disables pruning (archive mode).
+	PruneKeepBlocks uint64 `mapstructure:"prune-keep-blocks"`
+	// SyncFetchers and SyncVerifiers are the number of blocks fetched and verified concurrently.
+	// Zero uses the number of CPUs.
+	SyncFetchers  uint `mapstructure:"sync-fetchers"`
+	SyncVerifiers uint `mapstructure:"sync-verifiers"`
+	// SyncMaxInFlight bounds the number of blocks held in memory by the sync pipeline. Zero means no bound.
+	SyncMaxInFlight uint `mapstructure:"sync-max-in-flight"`
+	// SyncStopAt is the height after which the synchronizer stops, the RPC server keeps running.
+	// Nil syncs forever.
+	SyncStopAt *uint64 `mapstructure:"sync-stop-at"`
+	// SyncSource is a directory or tarball of feeder responses to sync from instead of the
+	// feeder gateway of the network.
+	SyncSource string `mapstructure:"sync-source"`
+	// SyncVerifySignatures makes the synchronizer check the signature of the sequencer on every block.
+	SyncVerifySignatures bool `mapstructure:"sync-verify-signatures"`
+	// SyncVerifyAccountSignatures makes the synchronizer check the signatures of transactions sent
+	// from standard accounts against the public keys the accounts store.
+	SyncVerifyAccountSignatures bool `mapstructure:"sync-verify-account-signatures"`
+	// AddressIndex makes the node index transactions by the addresses of their senders and contracts.
+	AddressIndex bool `mapstructure:"address-index"`
 }
 
 type Node struct {
@@ -111,6 +139,41 @@ func makeHTTP(port uint16, rpcHandler *rpc.Handler, log utils.SimpleLogger) *jso
 			Params:  []jsonrpc.Parameter{{Name: "block_id"}},
 			Handler: rpcHandler.StateUpdate,
 		},
+		{
+			Name:    "juno_getAggregatedStateUpdate",
+			Params:  []jsonrpc.Parameter{{Name: "from_block_id"}, {Name: "to_block_id"}},
+			Handler: rpcHandler.AggregatedStateUpdate,
+		},
+		{
+			Name:    "juno_getL1ToL2MessageByHash",
+			Params:  []jsonrpc.Parameter{{Name: "message_hash"}},
+			Handler: rpcHandler.L1ToL2MessageByHash,
+		},
+		{
+			Name:    "juno_getL2ToL1MessagesByHash",
+			Params:  []jsonrpc.Parameter{{Name: "message_hash"}},
+			Handler: rpcHandler.L2ToL1MessagesByHash,
+		},
+		{
+			Name:    "juno_getL2ToL1Messages",
+			Params:  []jsonrpc.Parameter{{Name: "filter"}},
+			Handler: rpcHandler.L2ToL1Messages,
+		},
+		{
+			Name:    "juno_getTransactionsByAddress",
+			Params:  []jsonrpc.Parameter{{Name: "address"}, {Name: "chunk_size"}, {Name: "continuation_token", Optional: true}},
+			Handler: rpcHandler.TransactionsByAddress,
+		},
+		{
+			Name:    "juno_getClassDeclaration",
+			Params:  []jsonrpc.Parameter{{Name: "class_hash"}},
+			Handler: rpcHandler.ClassDeclaration,
+		},
+		{
+			Name:    "juno_getClassInstances",
+			Params:  []jsonrpc.Parameter{{Name: "class_hash"}, {Name: "chunk_size"}, {Name: "continuation_token", Optional: true}},
+			Handler: rpcHandler.ClassInstances,
+		},
 	}, log)
 }
 
@@ -120,33 +183,59 @@ func makeHTTP(port uint16, rpcHandler *rpc.Handler, log utils.SimpleLogger) *jso
 func (n *Node) Run(ctx context.Context) {
 	n.log.Infow("Starting Juno...", "config", fmt.Sprintf("%+v", *n.cfg))
 
-	dbLog, err := utils.NewZapLogger(utils.ERROR)
+	err := n.openDB()
 	if err != nil {
-		n.log.Errorw("Error creating DB logger", "err", err)
+		n.log.Errorw("Error opening DB", "err", err)
 		return
 	}
+	defer n.closeDB()
 
-	n.db, err = pebble.New(n.cfg.DatabasePath, dbLog)
-	if err != nil {
-		n.log.Errorw("Error opening DB", "err", err)
+	if err = migration.MigrateIfNeeded(n.db, migrationBatchSize); err != nil {
+		n.log.Errorw("Error migrating DB", "err", err)
 		return
 	}
 
-	defer func() {
-		if closeErr := n.db.Close(); closeErr != nil {
-			n.log.Errorw("Error while closing the DB", "err", closeErr)
+	if n.cfg.AddressIndex {
+		if err = n.indexAddressActivity(ctx); err != nil {
+			n.log.Errorw("Error indexing transactions by address", "err", err)
+			return
 		}
-	}()
-
-	n.blockchain = blockchain.New(n.db, n.cfg.Network)
+	}
 
 	client := feeder.NewClient(n.cfg.Network.URL())
-	synchronizer := sync.New(n.blockchain, adaptfeeder.New(client), n.log)
+	if n.cfg.SyncSource != "" {
+		if client, err = feeder.NewArchiveClient(n.cfg.SyncSource); err != nil {
+			n.log.Errorw("Error opening sync source", "err", err)
+			return
+		}
+		n.log.Infow("Syncing from archive", "path", n.cfg.SyncSource)
+	}
+	synchronizer := sync.New(n.blockchain, adaptfeeder.New(client), n.log).
+		WithMaxInFlight(int(n.cfg.SyncMaxInFlight))
+	if n.cfg.SyncFetchers > 0 {
+		synchronizer.WithFetchers(int(n.cfg.SyncFetchers))
+	}
+	if n.cfg.SyncVerifiers > 0 {
+		synchronizer.WithVerifiers(int(n.cfg.SyncVerifiers))
+	}
+	if n.cfg.SyncStopAt != nil {
+		synchronizer.WithStopHeight(*n.cfg.SyncStopAt)
+	}
+	if n.cfg.SyncVerifySignatures {
+		synchronizer.WithSignatureVerification()
+	}
+	if n.cfg.SyncVerifyAccountSignatures {
+		synchronizer.WithAccountSignatureVerification()
+	}
 
 	http := makeHTTP(n.cfg.RPCPort, rpc.New(n.blockchain, n.cfg.Network), n.log)
 
 	n.services = []service.Service{synchronizer, http}
 
+	if n.cfg.PruneKeepBlocks > 0 {
+		n.services = append(n.services, pruner.New(n.blockchain, n.cfg.PruneKeepBlocks, n.log))
+	}
+
 	if n.cfg.Pprof {
 		n.services = append(n.services, pprof.New(defaultPprofPort, n.log))
 	}
@@ -170,6 +259,75 @@ func (n *Node) Run(ctx context.Context) {
 	n.log.Infow("Shutting down Juno...")
 }
 
+// VerifyState opens the DB and checks that the stored state is internally consistent
+// and matches the global state root of the head block. The DB is not migrated, so VerifyState
+// fails if its schema version is out of date.
+func (n *Node) VerifyState(ctx context.Context) error {
+	n.log.Infow("Verifying state...", "config", fmt.Sprintf("%+v", *n.cfg))
+
+	if err := n.openDB(); err != nil {
+		return err
+	}
+	defer n.closeDB()
+
+	if err := migration.CheckSchemaVersion(n.db); err != nil {
+		return err
+	}
+
+	if err := n.blockchain.VerifyState(ctx); err != nil {
+		n.log.Errorw("State verification failed", "err", err)
+		return err
+	}
+
+	n.log.Infow("State verified")
+	return nil
+}
+
+// indexAddressActivity indexes by address the stored blocks that were stored while the address
+// index was disabled, before the synchronizer stores new ones.
+func (n *Node) indexAddressActivity(ctx context.Context) error {
+	var total uint64
+	for ctx.Err() == nil {
+		indexed, err := n.blockchain.IndexAddressActivity(addressIndexBatchSize)
+		if err != nil {
+			return err
+		}
+		total += indexed
+		if indexed < addressIndexBatchSize {
+			break
+		}
+		n.log.Infow("Indexing transactions by address", "blocks", total)
+	}
+	if total > 0 {
+		n.log.Infow("Indexed transactions by address", "blocks", total)
+	}
+	return ctx.Err()
+}
+
+func (n *Node) openDB() error {
+	dbLog, err := utils.NewZapLogger(utils.ERROR)
+	if err != nil {
+		return fmt.Errorf("create DB logger: %w", err)
+	}
+
+	n.db, err = pebble.New(n.cfg.DatabasePath, dbLog)
+	if err != nil {
+		return err
+	}
+
+	n.blockchain = blockchain.New(n.db, n.cfg.Network)
+	if n.cfg.AddressIndex {
+		n.blockchain.WithAddressIndex()
+	}
+	return nil
+}
+
+func (n *Node) closeDB() {
+	if closeErr := n.db.Close(); closeErr != nil {
+		n.log.Errorw("Error while closing the DB", "err", closeErr)
+	}
+}
+
 func (n *Node) Config() Config {
 	return *n.cfg
 }
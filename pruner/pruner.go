@@ -0,0 +1,82 @@
+package pruner
+
+import (
+	"context"
+	"time"
+
+	"github.com/NethermindEth/juno/blockchain"
+	"github.com/NethermindEth/juno/service"
+	"github.com/NethermindEth/juno/utils"
+)
+
+const (
+	defaultBatchSize = 100
+	defaultThrottle  = 100 * time.Millisecond
+	idleInterval     = time.Minute
+)
+
+var _ service.Service = (*Pruner)(nil)
+
+// Pruner periodically deletes the per-block state records of blocks older than the most recent
+// keepBlocks blocks. Records are deleted in small batches, each in its own short write transaction,
+// with a pause in between so that the synchronizer is never blocked for long.
+type Pruner struct {
+	blockchain *blockchain.Blockchain
+	keepBlocks uint64
+	batchSize  uint64
+	throttle   time.Duration
+
+	log utils.SimpleLogger
+}
+
+func New(bc *blockchain.Blockchain, keepBlocks uint64, log utils.SimpleLogger) *Pruner {
+	return &Pruner{
+		blockchain: bc,
+		keepBlocks: keepBlocks,
+		batchSize:  defaultBatchSize,
+		throttle:   defaultThrottle,
+		log:        log,
+	}
+}
+
+// WithBatchSize sets the maximum number of blocks pruned in a single write transaction.
+func (p *Pruner) WithBatchSize(size uint64) *Pruner {
+	p.batchSize = size
+	return p
+}
+
+// WithThrottle sets the pause between two consecutive batches.
+func (p *Pruner) WithThrottle(d time.Duration) *Pruner {
+	p.throttle = d
+	return p
+}
+
+// Run starts the Pruner. It returns when the context is cancelled.
+func (p *Pruner) Run(ctx context.Context) error {
+	p.log.Infow("Starting pruner...", "keepBlocks", p.keepBlocks)
+
+	wait := time.Duration(0)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(wait):
+			pruned, err := p.blockchain.PruneStateUpdates(p.keepBlocks, p.batchSize)
+			if err != nil {
+				p.log.Warnw("Failed pruning state updates", "err", err)
+				wait = idleInterval
+				continue
+			}
+
+			if pruned > 0 {
+				p.log.Debugw("Pruned state updates", "count", pruned)
+			}
+
+			if pruned < p.batchSize {
+				wait = idleInterval
+			} else {
+				wait = p.throttle
+			}
+		}
+	}
+}
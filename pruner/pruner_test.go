@@ -0,0 +1,60 @@
+package pruner_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NethermindEth/juno/blockchain"
+	"github.com/NethermindEth/juno/clients/feeder"
+	"github.com/NethermindEth/juno/db/pebble"
+	"github.com/NethermindEth/juno/pruner"
+	adaptfeeder "github.com/NethermindEth/juno/starknetdata/feeder"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPruner(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+
+	bc := blockchain.New(pebble.NewMemTest(), utils.MAINNET)
+	for i := uint64(0); i < 3; i++ {
+		b, err := gw.BlockByNumber(context.Background(), i)
+		require.NoError(t, err)
+
+		su, err := gw.StateUpdate(context.Background(), i)
+		require.NoError(t, err)
+
+		require.NoError(t, bc.Store(b, su, nil))
+	}
+
+	p := pruner.New(bc, 1, utils.NewNopZapLogger()).WithBatchSize(1).WithThrottle(time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- p.Run(ctx)
+	}()
+
+	// state updates are pruned oldest first, one per pass
+	require.Eventually(t, func() bool {
+		_, err := bc.StateUpdateByNumber(1)
+		return errors.Is(err, blockchain.ErrStateUpdatePruned)
+	}, 10*time.Second, time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+
+	for i := uint64(0); i < 2; i++ {
+		_, err := bc.StateUpdateByNumber(i)
+		assert.ErrorIs(t, err, blockchain.ErrStateUpdatePruned)
+	}
+
+	_, err := bc.StateUpdateByNumber(2)
+	require.NoError(t, err)
+}
@@ -72,6 +72,31 @@ func (b *BlockID) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// https://github.com/starkware-libs/starknet-specs/blob/v0.7.0/api/starknet_api_openrpc.json#L1700
+type L1DAMode uint8
+
+const (
+	Calldata L1DAMode = iota
+	Blob
+)
+
+func (m L1DAMode) MarshalJSON() ([]byte, error) {
+	switch m {
+	case Calldata:
+		return []byte("\"CALLDATA\""), nil
+	case Blob:
+		return []byte("\"BLOB\""), nil
+	default:
+		return nil, errors.New("unknown L1DAMode")
+	}
+}
+
+// https://github.com/starkware-libs/starknet-specs/blob/v0.7.0/api/starknet_api_openrpc.json#L3618
+type ResourcePrice struct {
+	InWei *felt.Felt `json:"price_in_wei"`
+	InFri *felt.Felt `json:"price_in_fri"`
+}
+
 // https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json#L1072
 type BlockHeader struct {
 	Hash             *felt.Felt `json:"block_hash"`
@@ -80,6 +105,10 @@ type BlockHeader struct {
 	NewRoot          *felt.Felt `json:"new_root"`
 	Timestamp        uint64     `json:"timestamp"`
 	SequencerAddress *felt.Felt `json:"sequencer_address,omitempty"`
+	// The gas prices and the DA mode, see the v0.7.0 spec
+	L1GasPrice     *ResourcePrice `json:"l1_gas_price"`
+	L1DataGasPrice *ResourcePrice `json:"l1_data_gas_price,omitempty"`
+	L1DAMode       L1DAMode       `json:"l1_da_mode"`
 }
 
 // https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json#L1131
@@ -0,0 +1,26 @@
+package rpc
+
+import "github.com/NethermindEth/juno/core/felt"
+
+// ClassDeclaration is where a class was declared. The transaction hash is missing if the class was
+// declared without a transaction, which only happened in the early blocks.
+type ClassDeclaration struct {
+	TransactionHash *felt.Felt `json:"transaction_hash,omitempty"`
+	BlockHash       *felt.Felt `json:"block_hash"`
+	BlockNumber     uint64     `json:"block_number"`
+}
+
+// ClassInstance is a contract that uses, or used, a class. The block is the one in which the
+// contract started using the class, by being deployed or by replacing its class.
+type ClassInstance struct {
+	Address     *felt.Felt `json:"address"`
+	BlockNumber uint64     `json:"block_number"`
+	Replaced    bool       `json:"replaced"`
+}
+
+// ClassInstancesChunk is a chunk of the instances of a class. The continuation token is set if
+// there are more instances, pass it to get the next chunk.
+type ClassInstancesChunk struct {
+	Instances         []*ClassInstance `json:"instances"`
+	ContinuationToken string           `json:"continuation_token,omitempty"`
+}
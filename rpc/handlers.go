@@ -2,23 +2,45 @@ package rpc
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/NethermindEth/juno/blockchain"
 	"github.com/NethermindEth/juno/core"
 	"github.com/NethermindEth/juno/core/felt"
 	"github.com/NethermindEth/juno/jsonrpc"
 	"github.com/NethermindEth/juno/utils"
+	"github.com/ethereum/go-ethereum/common"
 )
 
 var (
 	ErrPendingNotSupported = errors.New("pending block is not supported yet")
 
-	ErrBlockNotFound   = &jsonrpc.Error{Code: 24, Message: "Block not found"}
-	ErrTxnHashNotFound = &jsonrpc.Error{Code: 25, Message: "Transaction hash not found"}
-	ErrNoBlock         = &jsonrpc.Error{Code: 32, Message: "There are no blocks"}
-	ErrInvalidTxIndex  = &jsonrpc.Error{Code: 27, Message: "Invalid transaction index in a block"}
+	ErrBlockNotFound     = &jsonrpc.Error{Code: 24, Message: "Block not found"}
+	ErrTxnHashNotFound   = &jsonrpc.Error{Code: 25, Message: "Transaction hash not found"}
+	ErrNoBlock           = &jsonrpc.Error{Code: 32, Message: "There are no blocks"}
+	ErrInvalidTxIndex    = &jsonrpc.Error{Code: 27, Message: "Invalid transaction index in a block"}
+	ErrClassHashNotFound = &jsonrpc.Error{Code: 28, Message: "Class hash not found"}
+
+	// Juno specific errors
+	ErrStateUpdatePruned = &jsonrpc.Error{Code: 100, Message: "State update was pruned"}
+
+	ErrInvalidBlockRange  = &jsonrpc.Error{Code: jsonrpc.InvalidParams, Message: "Invalid block range"}
+	ErrBlockRangeTooLarge = &jsonrpc.Error{
+		Code:    jsonrpc.InvalidParams,
+		Message: fmt.Sprintf("Block range spans more than %d blocks", blockchain.MaxAggregatedBlocks),
+	}
+	ErrPageSizeTooBig           = &jsonrpc.Error{Code: 31, Message: "Requested page size is too big"}
+	ErrInvalidContinuationToken = &jsonrpc.Error{Code: 33, Message: "The supplied continuation token is invalid or unknown"}
+	ErrInvalidChunkSize         = &jsonrpc.Error{Code: jsonrpc.InvalidParams, Message: "Chunk size must be positive"}
+	ErrAddressIndexDisabled     = &jsonrpc.Error{
+		Code:    jsonrpc.MethodNotFound,
+		Message: "Transactions are not indexed by address, the node must be started with --address-index",
+	}
 )
 
+// MaxChunkSize is the largest number of items returned by paginated methods.
+const MaxChunkSize = 1024
+
 type Handler struct {
 	bcReader blockchain.Reader
 	network  utils.Network
@@ -71,6 +93,19 @@ func (h *Handler) BlockWithTxHashes(id *BlockID) (*BlockWithTxHashes, *jsonrpc.E
 }
 
 func adaptBlockHeader(header *core.Header) BlockHeader {
+	l1DAMode := Calldata
+	if header.L1DAMode == core.Blob {
+		l1DAMode = Blob
+	}
+
+	var l1DataGasPrice *ResourcePrice
+	if header.L1DataGasPrice != nil {
+		l1DataGasPrice = &ResourcePrice{
+			InWei: feltOrZero(header.L1DataGasPrice.PriceInWei),
+			InFri: feltOrZero(header.L1DataGasPrice.PriceInFri),
+		}
+	}
+
 	return BlockHeader{
 		Hash:             header.Hash,
 		ParentHash:       header.ParentHash,
@@ -78,7 +113,21 @@ func adaptBlockHeader(header *core.Header) BlockHeader {
 		NewRoot:          header.GlobalStateRoot,
 		Timestamp:        header.Timestamp,
 		SequencerAddress: header.SequencerAddress,
+		// blocks before 0.13.0 have no price in fri
+		L1GasPrice: &ResourcePrice{
+			InWei: feltOrZero(header.GasPrice),
+			InFri: feltOrZero(header.GasPriceSTRK),
+		},
+		L1DataGasPrice: l1DataGasPrice,
+		L1DAMode:       l1DAMode,
+	}
+}
+
+func feltOrZero(f *felt.Felt) *felt.Felt {
+	if f == nil {
+		return &felt.Zero
 	}
+	return f
 }
 
 func (h *Handler) BlockWithTxs(id *BlockID) (*BlockWithTxs, *jsonrpc.Error) {
@@ -287,19 +336,54 @@ func (h *Handler) TransactionReceiptByHash(hash *felt.Felt) (*TransactionReceipt
 		contractAddress = nil
 	}
 
+	feeUnit := WEI
+	if receipt.FeeUnit == core.FRI {
+		feeUnit = FRI
+	}
+
 	return &TransactionReceipt{
-		Status:          StatusAcceptedL2, // todo
-		Type:            txn.Type,
-		Hash:            txn.Hash,
-		ActualFee:       receipt.Fee,
-		BlockHash:       blockHash,
-		BlockNumber:     blockNumber,
-		MessagesSent:    messages,
-		Events:          events,
-		ContractAddress: contractAddress,
+		Status:             StatusAcceptedL2, // todo
+		Type:               txn.Type,
+		Hash:               txn.Hash,
+		ActualFee:          &FeePayment{Amount: receipt.Fee, Unit: feeUnit},
+		ExecutionStatus:    adaptExecutionStatus(receipt.ExecutionStatus),
+		RevertReason:       receipt.RevertReason,
+		BlockHash:          blockHash,
+		BlockNumber:        blockNumber,
+		MessagesSent:       messages,
+		Events:             events,
+		ContractAddress:    contractAddress,
+		ExecutionResources: adaptExecutionResources(receipt.ExecutionResources),
 	}, nil
 }
 
+func adaptExecutionStatus(status core.ExecutionStatus) ExecutionStatus {
+	if status == core.Reverted {
+		return ExecutionReverted
+	}
+	return ExecutionSucceeded
+}
+
+func adaptExecutionResources(resources *core.ExecutionResources) *ExecutionResources {
+	if resources == nil {
+		return nil
+	}
+
+	builtins := resources.BuiltinInstanceCounter
+	return &ExecutionResources{
+		Steps:        resources.Steps,
+		MemoryHoles:  resources.MemoryHoles,
+		Pedersen:     builtins.Pedersen,
+		RangeCheck:   builtins.RangeCheck,
+		Bitwise:      builtins.Bitwise,
+		Ecdsa:        builtins.Ecsda,
+		EcOp:         builtins.EcOp,
+		Keccak:       builtins.Keccak,
+		Poseidon:     builtins.Poseidon,
+		SegmentArena: builtins.SegmentArena,
+	}
+}
+
 // https://github.com/starkware-libs/starknet-specs/blob/master/api/starknet_api_openrpc.json#L77
 func (h *Handler) StateUpdate(id *BlockID) (*StateUpdate, *jsonrpc.Error) {
 	var update *core.StateUpdate
@@ -318,10 +402,275 @@ func (h *Handler) StateUpdate(id *BlockID) (*StateUpdate, *jsonrpc.Error) {
 		update, err = h.bcReader.StateUpdateByNumber(id.Number)
 	}
 
+	if errors.Is(err, blockchain.ErrStateUpdatePruned) {
+		return nil, ErrStateUpdatePruned
+	} else if err != nil {
+		return nil, ErrBlockNotFound
+	}
+
+	return adaptStateUpdate(update), nil
+}
+
+// AggregatedStateUpdate returns a single state update that combines the state updates of all blocks
+// from `from` through `to` (inclusive), at most [blockchain.MaxAggregatedBlocks] of them. This is a
+// Juno specific extension to the Starknet JSON-RPC API.
+func (h *Handler) AggregatedStateUpdate(from, to *BlockID) (*StateUpdate, *jsonrpc.Error) {
+	fromHeader, err := h.blockHeaderByID(from)
+	if fromHeader == nil || err != nil {
+		return nil, ErrBlockNotFound
+	}
+
+	toHeader, err := h.blockHeaderByID(to)
+	if toHeader == nil || err != nil {
+		return nil, ErrBlockNotFound
+	}
+
+	if fromHeader.Number > toHeader.Number {
+		return nil, ErrInvalidBlockRange
+	}
+	if toHeader.Number-fromHeader.Number >= blockchain.MaxAggregatedBlocks {
+		return nil, ErrBlockRangeTooLarge
+	}
+
+	update, err := h.bcReader.AggregatedStateUpdate(fromHeader.Number, toHeader.Number)
+	if errors.Is(err, blockchain.ErrStateUpdatePruned) {
+		return nil, ErrStateUpdatePruned
+	} else if err != nil {
+		return nil, ErrBlockNotFound
+	}
+
+	return adaptStateUpdate(update), nil
+}
+
+// L1ToL2MessageByHash returns the L1 handler transaction that consumed the L1 to L2 message with
+// the given hash, see [core.L1ToL2Message.Hash]. This is a Juno specific extension to the Starknet
+// JSON-RPC API.
+func (h *Handler) L1ToL2MessageByHash(msgHash common.Hash) (*L1ToL2MessageStatus, *jsonrpc.Error) {
+	txnHash, err := h.bcReader.L1HandlerTxnHash(&msgHash)
+	if err != nil {
+		return nil, ErrTxnHashNotFound
+	}
+
+	txn, err := h.bcReader.TransactionByHash(txnHash)
+	if err != nil {
+		return nil, ErrTxnHashNotFound
+	}
+	l1Handler, ok := txn.(*core.L1HandlerTransaction)
+	if !ok {
+		return nil, ErrTxnHashNotFound
+	}
+	receipt, blockHash, blockNumber, err := h.bcReader.Receipt(txnHash)
+	if err != nil {
+		return nil, ErrTxnHashNotFound
+	}
+
+	msg := l1Handler.Message()
+	return &L1ToL2MessageStatus{
+		MessageHash: msgHash,
+		Message: &MsgFromL1{
+			From:     msg.From,
+			To:       msg.To,
+			Selector: msg.Selector,
+			Payload:  msg.Payload,
+			Nonce:    msg.Nonce,
+		},
+		TransactionHash: txnHash,
+		ExecutionStatus: adaptExecutionStatus(receipt.ExecutionStatus),
+		BlockHash:       blockHash,
+		BlockNumber:     blockNumber,
+	}, nil
+}
+
+// L2ToL1MessagesByHash returns the L2 to L1 messages with the given hash, see
+// [core.L2ToL1Message.Hash]. The same message can be sent more than once. This is a Juno specific
+// extension to the Starknet JSON-RPC API.
+func (h *Handler) L2ToL1MessagesByHash(msgHash common.Hash) ([]*EmittedMsgToL1, *jsonrpc.Error) {
+	records, err := h.bcReader.L2ToL1MessagesByHash(&msgHash)
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: jsonrpc.InternalError, Message: "Internal Error", Data: err.Error()}
+	}
+	return h.adaptL2ToL1MessageRecords(records)
+}
+
+// L2ToL1Messages returns the L2 to L1 messages sent to an L1 address in a block range, oldest
+// first and in chunks of at most [MaxChunkSize] messages. This is a Juno specific extension to the
+// Starknet JSON-RPC API.
+func (h *Handler) L2ToL1Messages(filter *MsgToL1Filter) (*MsgsToL1Chunk, *jsonrpc.Error) {
+	if filter.ChunkSize == 0 {
+		return nil, ErrInvalidChunkSize
+	}
+	if filter.ChunkSize > MaxChunkSize {
+		return nil, ErrPageSizeTooBig
+	}
+
+	start := new(blockchain.L2ToL1MessageLocation)
+	if filter.FromBlock != nil {
+		fromHeader, err := h.blockHeaderByID(filter.FromBlock)
+		if fromHeader == nil || err != nil {
+			return nil, ErrBlockNotFound
+		}
+		start.BlockNumber = fromHeader.Number
+	}
+
+	toID := filter.ToBlock
+	if toID == nil {
+		toID = &BlockID{Latest: true}
+	}
+	toHeader, err := h.blockHeaderByID(toID)
+	if toHeader == nil || err != nil {
+		return nil, ErrBlockNotFound
+	}
+	if start.BlockNumber > toHeader.Number {
+		return nil, ErrInvalidBlockRange
+	}
+
+	if filter.ContinuationToken != "" {
+		if _, err = fmt.Sscanf(filter.ContinuationToken, "%d-%d-%d", &start.BlockNumber,
+			&start.TransactionIndex, &start.MessageIndex); err != nil {
+			return nil, ErrInvalidContinuationToken
+		}
+	}
+
+	records, next, err := h.bcReader.L2ToL1MessagesByRecipient(filter.ToAddress, start, toHeader.Number,
+		filter.ChunkSize)
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: jsonrpc.InternalError, Message: "Internal Error", Data: err.Error()}
+	}
+	messages, rpcErr := h.adaptL2ToL1MessageRecords(records)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	chunk := &MsgsToL1Chunk{Messages: messages}
+	if next != nil {
+		chunk.ContinuationToken = fmt.Sprintf("%d-%d-%d", next.BlockNumber, next.TransactionIndex, next.MessageIndex)
+	}
+	return chunk, nil
+}
+
+// TransactionsByAddress returns the hashes of the transactions sent by or to the contract at the
+// given address, oldest first and in chunks of at most [MaxChunkSize] transactions. The node must
+// maintain the address index. This is a Juno specific extension to the Starknet JSON-RPC API.
+func (h *Handler) TransactionsByAddress(address felt.Felt, chunkSize uint64, continuationToken string) (
+	*TxnHashesChunk, *jsonrpc.Error,
+) {
+	if chunkSize == 0 {
+		return nil, ErrInvalidChunkSize
+	}
+	if chunkSize > MaxChunkSize {
+		return nil, ErrPageSizeTooBig
+	}
+
+	start := new(blockchain.TransactionLocation)
+	if continuationToken != "" {
+		if _, err := fmt.Sscanf(continuationToken, "%d-%d", &start.BlockNumber, &start.Index); err != nil {
+			return nil, ErrInvalidContinuationToken
+		}
+	}
+
+	hashes, next, err := h.bcReader.TransactionsByAddress(&address, start, chunkSize)
+	if errors.Is(err, blockchain.ErrAddressIndexDisabled) {
+		return nil, ErrAddressIndexDisabled
+	} else if err != nil {
+		return nil, &jsonrpc.Error{Code: jsonrpc.InternalError, Message: "Internal Error", Data: err.Error()}
+	}
+
+	chunk := &TxnHashesChunk{TransactionHashes: hashes}
+	if chunk.TransactionHashes == nil {
+		chunk.TransactionHashes = []*felt.Felt{}
+	}
+	if next != nil {
+		chunk.ContinuationToken = fmt.Sprintf("%d-%d", next.BlockNumber, next.Index)
+	}
+	return chunk, nil
+}
+
+// ClassDeclaration returns where the class with the given hash was declared. This is a Juno
+// specific extension to the Starknet JSON-RPC API.
+func (h *Handler) ClassDeclaration(classHash felt.Felt) (*ClassDeclaration, *jsonrpc.Error) {
+	declaration, err := h.bcReader.ClassDeclaration(&classHash)
+	if err != nil {
+		return nil, ErrClassHashNotFound
+	}
+	header, err := h.bcReader.BlockHeaderByNumber(declaration.BlockNumber)
 	if err != nil {
 		return nil, ErrBlockNotFound
 	}
 
+	return &ClassDeclaration{
+		TransactionHash: declaration.TransactionHash,
+		BlockHash:       header.Hash,
+		BlockNumber:     declaration.BlockNumber,
+	}, nil
+}
+
+// ClassInstances returns the contracts that use, or used, the class with the given hash, in
+// address order and in chunks of at most [MaxChunkSize] contracts. This is a Juno specific
+// extension to the Starknet JSON-RPC API.
+func (h *Handler) ClassInstances(classHash felt.Felt, chunkSize uint64, continuationToken string) (
+	*ClassInstancesChunk, *jsonrpc.Error,
+) {
+	if chunkSize == 0 {
+		return nil, ErrInvalidChunkSize
+	}
+	if chunkSize > MaxChunkSize {
+		return nil, ErrPageSizeTooBig
+	}
+
+	start := new(felt.Felt)
+	if continuationToken != "" {
+		if _, err := start.SetString(continuationToken); err != nil {
+			return nil, ErrInvalidContinuationToken
+		}
+	}
+
+	instances, next, err := h.bcReader.ClassInstances(&classHash, start, chunkSize)
+	if err != nil {
+		return nil, &jsonrpc.Error{Code: jsonrpc.InternalError, Message: "Internal Error", Data: err.Error()}
+	}
+
+	chunk := &ClassInstancesChunk{Instances: make([]*ClassInstance, len(instances))}
+	for i, instance := range instances {
+		chunk.Instances[i] = &ClassInstance{
+			Address:     instance.Address,
+			BlockNumber: instance.BlockNumber,
+			Replaced:    instance.Replaced,
+		}
+	}
+	if next != nil {
+		chunk.ContinuationToken = next.String()
+	}
+	return chunk, nil
+}
+
+func (h *Handler) adaptL2ToL1MessageRecords(records []*blockchain.L2ToL1MessageRecord) ([]*EmittedMsgToL1, *jsonrpc.Error) {
+	blockHashes := make(map[uint64]*felt.Felt)
+	messages := make([]*EmittedMsgToL1, len(records))
+	for i, record := range records {
+		blockHash, ok := blockHashes[record.BlockNumber]
+		if !ok {
+			header, err := h.bcReader.BlockHeaderByNumber(record.BlockNumber)
+			if err != nil {
+				return nil, ErrBlockNotFound
+			}
+			blockHash = header.Hash
+			blockHashes[record.BlockNumber] = blockHash
+		}
+
+		messages[i] = &EmittedMsgToL1{
+			MessageHash:     record.Hash,
+			From:            record.Message.From,
+			To:              record.Message.To,
+			Payload:         record.Message.Payload,
+			TransactionHash: record.TransactionHash,
+			BlockHash:       blockHash,
+			BlockNumber:     record.BlockNumber,
+		}
+	}
+	return messages, nil
+}
+
+func adaptStateUpdate(update *core.StateUpdate) *StateUpdate {
 	nonces := make([]Nonce, 0, len(update.StateDiff.Nonces))
 	for addr, nonce := range update.StateDiff.Nonces {
 		nonces = append(nonces, Nonce{ContractAddress: new(felt.Felt).Set(&addr), Nonce: nonce})
@@ -374,5 +723,5 @@ func (h *Handler) StateUpdate(id *BlockID) (*StateUpdate, *jsonrpc.Error) {
 			StorageDiffs:              storageDiffs,
 			DeployedContracts:         deployedContracts,
 		},
-	}, nil
+	}
 }
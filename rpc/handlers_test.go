@@ -7,13 +7,16 @@ import (
 	"math/rand"
 	"testing"
 
+	"github.com/NethermindEth/juno/blockchain"
 	"github.com/NethermindEth/juno/clients/feeder"
 	"github.com/NethermindEth/juno/core"
 	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db"
 	"github.com/NethermindEth/juno/mocks"
 	"github.com/NethermindEth/juno/rpc"
 	adaptfeeder "github.com/NethermindEth/juno/starknetdata/feeder"
 	"github.com/NethermindEth/juno/utils"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -184,6 +187,9 @@ func TestBlockWithTxHashes(t *testing.T) {
 		assert.Equal(t, latestBlock.ParentHash, b.ParentHash)
 		assert.Equal(t, latestBlock.SequencerAddress, b.SequencerAddress)
 		assert.Equal(t, latestBlock.Timestamp, b.Timestamp)
+		assert.Equal(t, &rpc.ResourcePrice{InWei: utils.HexToFelt(t, "0x3b5d"), InFri: &felt.Zero}, b.L1GasPrice)
+		assert.Nil(t, b.L1DataGasPrice)
+		assert.Equal(t, rpc.Calldata, b.L1DAMode)
 		assert.Equal(t, len(latestBlock.Transactions), len(b.TxnHashes))
 		for i := 0; i < len(latestBlock.Transactions); i++ {
 			assert.Equal(t, latestBlock.Transactions[i].Hash(), b.TxnHashes[i])
@@ -240,6 +246,41 @@ func TestBlockWithTxHashes(t *testing.T) {
 
 		checkLatestBlock(t, block)
 	})
+
+	t.Run("gas prices and DA mode", func(t *testing.T) {
+		// made-up block of protocol version 0.13.2
+		block5 := &core.Block{
+			Header: &core.Header{
+				Hash:             utils.HexToFelt(t, "0x37a6a6dda2e8ed6bd65e316101b6b6458419778a52aadb05a465a16883ce458"),
+				ParentHash:       utils.HexToFelt(t, "0x123"),
+				Number:           5,
+				GlobalStateRoot:  utils.HexToFelt(t, "0x456"),
+				SequencerAddress: utils.HexToFelt(t, "0x1176a1bd84444c89232ec27754698e5d2e7e1a7f1539f12027f28b23ec9f3d8"),
+				ProtocolVersion:  "0.13.2",
+				GasPrice:         utils.HexToFelt(t, "0x3b9aca00"),
+				GasPriceSTRK:     utils.HexToFelt(t, "0x2540be400"),
+				L1DataGasPrice: &core.GasPrice{
+					PriceInWei: utils.HexToFelt(t, "0x1"),
+					PriceInFri: utils.HexToFelt(t, "0x2"),
+				},
+				L1DAMode: core.Blob,
+			},
+		}
+		mockReader.EXPECT().BlockByNumber(uint64(5)).Return(block5, nil)
+
+		block, rpcErr := handler.BlockWithTxHashes(&rpc.BlockID{Number: 5})
+		require.Nil(t, rpcErr)
+
+		blockJSON, err := json.Marshal(block)
+		require.NoError(t, err)
+		blockMap := make(map[string]any)
+		require.NoError(t, json.Unmarshal(blockJSON, &blockMap))
+
+		assert.Equal(t, map[string]any{"price_in_wei": "0x3b9aca00", "price_in_fri": "0x2540be400"},
+			blockMap["l1_gas_price"])
+		assert.Equal(t, map[string]any{"price_in_wei": "0x1", "price_in_fri": "0x2"}, blockMap["l1_data_gas_price"])
+		assert.Equal(t, "BLOB", blockMap["l1_da_mode"])
+	})
 }
 
 func TestBlockWithTxs(t *testing.T) {
@@ -701,13 +742,15 @@ func TestTransactionReceiptByHash(t *testing.T) {
 			expected: `{
 					"type": "DEPLOY",
 					"transaction_hash": "0xe0a2e45a80bb827967e096bcf58874f6c01c191e0a0530624cba66a508ae75",
-					"actual_fee": "0x0",
+					"actual_fee": {"amount": "0x0", "unit": "WEI"},
+					"execution_status": "SUCCEEDED",
 					"status": "ACCEPTED_ON_L2",
 					"block_hash": "0x47c3637b57c2b079b93c61539950c17e868a28f46cdef28f88521067f21e943",
 					"block_number": 0,
 					"messages_sent": [],
 					"events": [],
-					"contract_address": "0x20cfa74ee3564b4cd5435cdace0f9c4d43b939620e4a0bb5076105df0a626c6"
+					"contract_address": "0x20cfa74ee3564b4cd5435cdace0f9c4d43b939620e4a0bb5076105df0a626c6",
+					"execution_resources": {"steps": 29}
 				}`,
 		},
 		"without contract addr": {
@@ -715,7 +758,8 @@ func TestTransactionReceiptByHash(t *testing.T) {
 			expected: `{
 					"type": "INVOKE",
 					"transaction_hash": "0xce54bbc5647e1c1ea4276c01a708523f740db0ff5474c77734f73beec2624",
-					"actual_fee": "0x0",
+					"actual_fee": {"amount": "0x0", "unit": "WEI"},
+					"execution_status": "SUCCEEDED",
 					"status": "ACCEPTED_ON_L2",
 					"block_hash": "0x47c3637b57c2b079b93c61539950c17e868a28f46cdef28f88521067f21e943",
 					"block_number": 0,
@@ -728,7 +772,8 @@ func TestTransactionReceiptByHash(t *testing.T) {
 							]
 						}
 					],
-					"events": []
+					"events": [],
+					"execution_resources": {"steps": 31}
 				}`,
 		},
 	}
@@ -752,6 +797,70 @@ func TestTransactionReceiptByHash(t *testing.T) {
 			assert.Equal(t, expectedMap, receiptMap)
 		})
 	}
+
+	t.Run("reverted version 3 transaction", func(t *testing.T) {
+		// made-up transaction of protocol version 0.13.2
+		tx := &core.InvokeTransaction{
+			TransactionHash:      utils.HexToFelt(t, "0x3a94edbc959af535b175019388eac611acb11cac0be843862050c79245c3631"),
+			SenderAddress:        utils.HexToFelt(t, "0x3f6f3bc663aedc5285d6013cc3ffcbc4341d86ab488b8b68d297f8258793c41"),
+			Nonce:                utils.HexToFelt(t, "0x8"),
+			CallData:             []*felt.Felt{utils.HexToFelt(t, "0x1"), utils.HexToFelt(t, "0x2")},
+			TransactionSignature: []*felt.Felt{utils.HexToFelt(t, "0x11"), utils.HexToFelt(t, "0x22")},
+			Version:              utils.HexToFelt(t, "0x3"),
+		}
+		receipt := &core.TransactionReceipt{
+			TransactionHash: tx.TransactionHash,
+			Fee:             utils.HexToFelt(t, "0x2a"),
+			FeeUnit:         core.FRI,
+			Events: []*core.Event{{
+				From: utils.HexToFelt(t, "0x49d36570d4e46f48e99674bd3fcc84644ddd6b96f7c741b1562b82f9e004dc7"),
+				Keys: []*felt.Felt{utils.HexToFelt(t, "0x99")},
+				Data: []*felt.Felt{utils.HexToFelt(t, "0x1"), utils.HexToFelt(t, "0x2")},
+			}},
+			ExecutionResources: &core.ExecutionResources{
+				BuiltinInstanceCounter: core.BuiltinInstanceCounter{Pedersen: 10, RangeCheck: 120},
+				Steps:                  5123,
+			},
+			ExecutionStatus: core.Reverted,
+			RevertReason:    "Error in the called contract",
+		}
+		blockHash := utils.HexToFelt(t, "0x37a6a6dda2e8ed6bd65e316101b6b6458419778a52aadb05a465a16883ce458")
+
+		txHash := tx.Hash()
+		mockReader.EXPECT().TransactionByHash(txHash).Return(tx, nil)
+		mockReader.EXPECT().Receipt(txHash).Return(receipt, blockHash, uint64(5), nil)
+
+		expected := `{
+			"type": "INVOKE",
+			"transaction_hash": "0x3a94edbc959af535b175019388eac611acb11cac0be843862050c79245c3631",
+			"actual_fee": {"amount": "0x2a", "unit": "FRI"},
+			"execution_status": "REVERTED",
+			"revert_reason": "Error in the called contract",
+			"status": "ACCEPTED_ON_L2",
+			"block_hash": "0x37a6a6dda2e8ed6bd65e316101b6b6458419778a52aadb05a465a16883ce458",
+			"block_number": 5,
+			"messages_sent": [],
+			"events": [
+				{
+					"from_address": "0x49d36570d4e46f48e99674bd3fcc84644ddd6b96f7c741b1562b82f9e004dc7",
+					"keys": ["0x99"],
+					"data": ["0x1", "0x2"]
+				}
+			],
+			"execution_resources": {
+				"steps": 5123,
+				"pedersen_builtin_applications": 10,
+				"range_check_builtin_applications": 120
+			}
+		}`
+
+		rpcReceipt, rpcErr := handler.TransactionReceiptByHash(txHash)
+		require.Nil(t, rpcErr)
+
+		receiptJSON, err := json.Marshal(rpcReceipt)
+		require.NoError(t, err)
+		assert.JSONEq(t, expected, string(receiptJSON))
+	})
 }
 
 func TestStateUpdate(t *testing.T) {
@@ -785,6 +894,14 @@ func TestStateUpdate(t *testing.T) {
 		assert.Equal(t, rpc.ErrBlockNotFound, rpcErr)
 	})
 
+	t.Run("pruned state update", func(t *testing.T) {
+		mockReader.EXPECT().StateUpdateByNumber(uint64(1)).Return(nil, blockchain.ErrStateUpdatePruned)
+
+		update, rpcErr := handler.StateUpdate(&rpc.BlockID{Number: 1})
+		assert.Nil(t, update)
+		assert.Equal(t, rpc.ErrStateUpdatePruned, rpcErr)
+	})
+
 	client, closer := feeder.NewTestClient(utils.MAINNET)
 	t.Cleanup(closer)
 	mainnetGw := adaptfeeder.New(client)
@@ -889,3 +1006,388 @@ func TestStateUpdate(t *testing.T) {
 		}
 	})
 }
+
+func TestAggregatedStateUpdate(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	mockReader := mocks.NewMockReader(mockCtrl)
+	handler := rpc.New(mockReader, utils.MAINNET)
+
+	client, closer := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closer)
+	gw := adaptfeeder.New(client)
+
+	block1, err := gw.BlockByNumber(context.Background(), 1)
+	require.NoError(t, err)
+	block2, err := gw.BlockByNumber(context.Background(), 2)
+	require.NoError(t, err)
+
+	t.Run("non-existent block", func(t *testing.T) {
+		mockReader.EXPECT().BlockHeaderByNumber(uint64(1)).Return(block1.Header, nil)
+		mockReader.EXPECT().HeadsHeader().Return(nil, errors.New("empty blockchain"))
+
+		update, rpcErr := handler.AggregatedStateUpdate(&rpc.BlockID{Number: 1}, &rpc.BlockID{Latest: true})
+		assert.Nil(t, update)
+		assert.Equal(t, rpc.ErrBlockNotFound, rpcErr)
+	})
+
+	t.Run("invalid range", func(t *testing.T) {
+		mockReader.EXPECT().BlockHeaderByNumber(uint64(2)).Return(block2.Header, nil)
+		mockReader.EXPECT().BlockHeaderByHash(block1.Hash).Return(block1.Header, nil)
+
+		update, rpcErr := handler.AggregatedStateUpdate(&rpc.BlockID{Number: 2}, &rpc.BlockID{Hash: block1.Hash})
+		assert.Nil(t, update)
+		assert.Equal(t, rpc.ErrInvalidBlockRange, rpcErr)
+	})
+
+	t.Run("too large range", func(t *testing.T) {
+		mockReader.EXPECT().BlockHeaderByNumber(uint64(1)).Return(block1.Header, nil)
+		mockReader.EXPECT().BlockHeaderByNumber(uint64(1+blockchain.MaxAggregatedBlocks)).
+			Return(&core.Header{Number: 1 + blockchain.MaxAggregatedBlocks}, nil)
+
+		update, rpcErr := handler.AggregatedStateUpdate(&rpc.BlockID{Number: 1}, &rpc.BlockID{Number: 1 + blockchain.MaxAggregatedBlocks})
+		assert.Nil(t, update)
+		assert.Equal(t, rpc.ErrBlockRangeTooLarge, rpcErr)
+	})
+
+	t.Run("aggregated update", func(t *testing.T) {
+		update2, err := gw.StateUpdate(context.Background(), 2)
+		require.NoError(t, err)
+
+		mockReader.EXPECT().BlockHeaderByNumber(uint64(1)).Return(block1.Header, nil)
+		mockReader.EXPECT().HeadsHeader().Return(block2.Header, nil)
+		mockReader.EXPECT().AggregatedStateUpdate(uint64(1), uint64(2)).Return(update2, nil)
+
+		update, rpcErr := handler.AggregatedStateUpdate(&rpc.BlockID{Number: 1}, &rpc.BlockID{Latest: true})
+		require.Nil(t, rpcErr)
+		assert.Equal(t, update2.BlockHash, update.BlockHash)
+		assert.Equal(t, update2.OldRoot, update.OldRoot)
+		assert.Equal(t, update2.NewRoot, update.NewRoot)
+		assert.Equal(t, len(update2.StateDiff.StorageDiffs), len(update.StateDiff.StorageDiffs))
+		assert.Equal(t, len(update2.StateDiff.DeployedContracts), len(update.StateDiff.DeployedContracts))
+	})
+}
+
+func TestL1ToL2MessageByHash(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	mockReader := mocks.NewMockReader(mockCtrl)
+	handler := rpc.New(mockReader, utils.MAINNET)
+
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	block, err := adaptfeeder.New(client).BlockByNumber(context.Background(), 1059)
+	require.NoError(t, err)
+
+	l1Handler, ok := block.Transactions[14].(*core.L1HandlerTransaction)
+	require.True(t, ok)
+	msgHash := l1Handler.Message().Hash()
+
+	t.Run("message not found", func(t *testing.T) {
+		unknownHash := common.HexToHash("0x1")
+		mockReader.EXPECT().L1HandlerTxnHash(&unknownHash).Return(nil, db.ErrKeyNotFound)
+
+		status, rpcErr := handler.L1ToL2MessageByHash(unknownHash)
+		assert.Nil(t, status)
+		assert.Equal(t, rpc.ErrTxnHashNotFound, rpcErr)
+	})
+
+	t.Run("message consumed by an L1 handler transaction", func(t *testing.T) {
+		mockReader.EXPECT().L1HandlerTxnHash(&msgHash).Return(l1Handler.Hash(), nil)
+		mockReader.EXPECT().TransactionByHash(l1Handler.Hash()).Return(l1Handler, nil)
+		mockReader.EXPECT().Receipt(l1Handler.Hash()).Return(block.Receipts[14], block.Hash, block.Number, nil)
+
+		status, rpcErr := handler.L1ToL2MessageByHash(msgHash)
+		require.Nil(t, rpcErr)
+
+		statusJSON, err := json.Marshal(status)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"message_hash": "0x6563d03b2a3a40c8deabb304dc06dc5ee953f5c7adb757e3a2960abc07f449d4",
+			"message": {
+				"from_address": "0x0142273bcbfca76512b2a05aed21f134c4495208",
+				"to_address": "0xda8054260ec00606197a4103eb2ef08d6c8af0b6a808b610152d1ce498f8c3",
+				"entry_point_selector": "0xc73f681176fc7b3f9693986fd7b14581e8d540519e27400e88b8713932be01",
+				"payload": [
+					"0x160c35f9f962e1bc997f9133d9fb231afd5799f7d63dcbcd506af4866b3874",
+					"0x16345785d8a0000",
+					"0x0",
+					"0x3"
+				],
+				"nonce": "0x2"
+			},
+			"transaction_hash": "0x537eacfd3c49166eec905daff61ff7feef9c133a049ea2135cb94eec840a4a8",
+			"execution_status": "SUCCEEDED",
+			"block_hash": "`+block.Hash.String()+`",
+			"block_number": 1059
+		}`, string(statusJSON))
+	})
+}
+
+func TestL2ToL1Messages(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	mockReader := mocks.NewMockReader(mockCtrl)
+	handler := rpc.New(mockReader, utils.MAINNET)
+
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	block, err := adaptfeeder.New(client).BlockByNumber(context.Background(), 0)
+	require.NoError(t, err)
+
+	msg := block.Receipts[2].L2ToL1Message[0]
+	msgHash := msg.Hash()
+	record := &blockchain.L2ToL1MessageRecord{
+		L2ToL1MessageLocation: blockchain.L2ToL1MessageLocation{TransactionIndex: 2},
+		Hash:                  msgHash,
+		TransactionHash:       block.Transactions[2].Hash(),
+		Message:               msg,
+	}
+	emitted := &rpc.EmittedMsgToL1{
+		MessageHash:     msgHash,
+		From:            msg.From,
+		To:              msg.To,
+		Payload:         msg.Payload,
+		TransactionHash: block.Transactions[2].Hash(),
+		BlockHash:       block.Hash,
+		BlockNumber:     0,
+	}
+
+	t.Run("by hash", func(t *testing.T) {
+		mockReader.EXPECT().L2ToL1MessagesByHash(&msgHash).Return([]*blockchain.L2ToL1MessageRecord{record}, nil)
+		mockReader.EXPECT().BlockHeaderByNumber(uint64(0)).Return(block.Header, nil)
+
+		messages, rpcErr := handler.L2ToL1MessagesByHash(msgHash)
+		require.Nil(t, rpcErr)
+		assert.Equal(t, []*rpc.EmittedMsgToL1{emitted}, messages)
+	})
+
+	t.Run("invalid chunk size", func(t *testing.T) {
+		chunk, rpcErr := handler.L2ToL1Messages(&rpc.MsgToL1Filter{ToAddress: msg.To})
+		assert.Nil(t, chunk)
+		assert.Equal(t, rpc.ErrInvalidChunkSize, rpcErr)
+
+		chunk, rpcErr = handler.L2ToL1Messages(&rpc.MsgToL1Filter{ToAddress: msg.To, ChunkSize: rpc.MaxChunkSize + 1})
+		assert.Nil(t, chunk)
+		assert.Equal(t, rpc.ErrPageSizeTooBig, rpcErr)
+	})
+
+	t.Run("invalid block range", func(t *testing.T) {
+		mockReader.EXPECT().BlockHeaderByNumber(uint64(1)).Return(&core.Header{Number: 1}, nil)
+		mockReader.EXPECT().HeadsHeader().Return(block.Header, nil)
+
+		chunk, rpcErr := handler.L2ToL1Messages(&rpc.MsgToL1Filter{
+			ToAddress: msg.To,
+			FromBlock: &rpc.BlockID{Number: 1},
+			ChunkSize: 1,
+		})
+		assert.Nil(t, chunk)
+		assert.Equal(t, rpc.ErrInvalidBlockRange, rpcErr)
+	})
+
+	t.Run("invalid continuation token", func(t *testing.T) {
+		mockReader.EXPECT().HeadsHeader().Return(block.Header, nil)
+
+		chunk, rpcErr := handler.L2ToL1Messages(&rpc.MsgToL1Filter{
+			ToAddress:         msg.To,
+			ChunkSize:         1,
+			ContinuationToken: "not a token",
+		})
+		assert.Nil(t, chunk)
+		assert.Equal(t, rpc.ErrInvalidContinuationToken, rpcErr)
+	})
+
+	t.Run("chunks", func(t *testing.T) {
+		next := &blockchain.L2ToL1MessageLocation{TransactionIndex: 3, MessageIndex: 1}
+		mockReader.EXPECT().HeadsHeader().Return(block.Header, nil)
+		mockReader.EXPECT().L2ToL1MessagesByRecipient(msg.To, &blockchain.L2ToL1MessageLocation{}, uint64(0), uint64(1)).
+			Return([]*blockchain.L2ToL1MessageRecord{record}, next, nil)
+		mockReader.EXPECT().BlockHeaderByNumber(uint64(0)).Return(block.Header, nil)
+
+		chunk, rpcErr := handler.L2ToL1Messages(&rpc.MsgToL1Filter{ToAddress: msg.To, ChunkSize: 1})
+		require.Nil(t, rpcErr)
+		assert.Equal(t, &rpc.MsgsToL1Chunk{
+			Messages:          []*rpc.EmittedMsgToL1{emitted},
+			ContinuationToken: "0-3-1",
+		}, chunk)
+
+		mockReader.EXPECT().HeadsHeader().Return(block.Header, nil)
+		mockReader.EXPECT().L2ToL1MessagesByRecipient(msg.To, next, uint64(0), uint64(1)).
+			Return(nil, nil, nil)
+
+		chunk, rpcErr = handler.L2ToL1Messages(&rpc.MsgToL1Filter{
+			ToAddress:         msg.To,
+			ChunkSize:         1,
+			ContinuationToken: chunk.ContinuationToken,
+		})
+		require.Nil(t, rpcErr)
+		assert.Empty(t, chunk.Messages)
+		assert.Empty(t, chunk.ContinuationToken)
+	})
+}
+
+func TestTransactionsByAddress(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	mockReader := mocks.NewMockReader(mockCtrl)
+	handler := rpc.New(mockReader, utils.MAINNET)
+
+	address := utils.HexToFelt(t, "0x20cfa74ee3564b4cd5435cdace0f9c4d43b939620e4a0bb5076105df0a626c6")
+	hashes := []*felt.Felt{
+		utils.HexToFelt(t, "0xe0a2e45a80bb827967e096bcf58874f6c01c191e0a0530624cba66a508ae75"),
+		utils.HexToFelt(t, "0x12c96ae3c050771689eb261c9bf78fac2580708c7f1f3d69a9647d8be59f1e1"),
+	}
+
+	t.Run("invalid chunk size", func(t *testing.T) {
+		chunk, rpcErr := handler.TransactionsByAddress(*address, 0, "")
+		assert.Nil(t, chunk)
+		assert.Equal(t, rpc.ErrInvalidChunkSize, rpcErr)
+
+		chunk, rpcErr = handler.TransactionsByAddress(*address, rpc.MaxChunkSize+1, "")
+		assert.Nil(t, chunk)
+		assert.Equal(t, rpc.ErrPageSizeTooBig, rpcErr)
+	})
+
+	t.Run("invalid continuation token", func(t *testing.T) {
+		chunk, rpcErr := handler.TransactionsByAddress(*address, 2, "not a token")
+		assert.Nil(t, chunk)
+		assert.Equal(t, rpc.ErrInvalidContinuationToken, rpcErr)
+	})
+
+	t.Run("index disabled", func(t *testing.T) {
+		mockReader.EXPECT().TransactionsByAddress(address, &blockchain.TransactionLocation{}, uint64(2)).
+			Return(nil, nil, blockchain.ErrAddressIndexDisabled)
+
+		chunk, rpcErr := handler.TransactionsByAddress(*address, 2, "")
+		assert.Nil(t, chunk)
+		assert.Equal(t, rpc.ErrAddressIndexDisabled, rpcErr)
+	})
+
+	t.Run("chunks", func(t *testing.T) {
+		next := &blockchain.TransactionLocation{BlockNumber: 5, Index: 3}
+		mockReader.EXPECT().TransactionsByAddress(address, &blockchain.TransactionLocation{}, uint64(2)).
+			Return(hashes, next, nil)
+
+		chunk, rpcErr := handler.TransactionsByAddress(*address, 2, "")
+		require.Nil(t, rpcErr)
+		assert.Equal(t, &rpc.TxnHashesChunk{TransactionHashes: hashes, ContinuationToken: "5-3"}, chunk)
+
+		mockReader.EXPECT().TransactionsByAddress(address, next, uint64(2)).Return(nil, nil, nil)
+
+		chunk, rpcErr = handler.TransactionsByAddress(*address, 2, chunk.ContinuationToken)
+		require.Nil(t, rpcErr)
+
+		chunkJSON, err := json.Marshal(chunk)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"transaction_hashes": []}`, string(chunkJSON))
+	})
+}
+
+func TestClassDeclaration(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	mockReader := mocks.NewMockReader(mockCtrl)
+	handler := rpc.New(mockReader, utils.MAINNET)
+
+	classHash := utils.HexToFelt(t, "0x10455c752b86932ce552f2b0fe81a880746649b9aee7e0d842bf3f52378f9f8")
+	txHash := utils.HexToFelt(t, "0xe0a2e45a80bb827967e096bcf58874f6c01c191e0a0530624cba66a508ae75")
+	blockHash := utils.HexToFelt(t, "0x47c3637b57c2b079b93c61539950c17e868a28f46cdef28f88521067f21e943")
+
+	t.Run("class not found", func(t *testing.T) {
+		mockReader.EXPECT().ClassDeclaration(classHash).Return(nil, db.ErrKeyNotFound)
+
+		declaration, rpcErr := handler.ClassDeclaration(*classHash)
+		assert.Nil(t, declaration)
+		assert.Equal(t, rpc.ErrClassHashNotFound, rpcErr)
+	})
+
+	t.Run("declared class", func(t *testing.T) {
+		mockReader.EXPECT().ClassDeclaration(classHash).
+			Return(&blockchain.ClassDeclaration{BlockNumber: 0, TransactionHash: txHash}, nil)
+		mockReader.EXPECT().BlockHeaderByNumber(uint64(0)).Return(&core.Header{Hash: blockHash}, nil)
+
+		declaration, rpcErr := handler.ClassDeclaration(*classHash)
+		require.Nil(t, rpcErr)
+		assert.Equal(t, &rpc.ClassDeclaration{TransactionHash: txHash, BlockHash: blockHash, BlockNumber: 0}, declaration)
+	})
+
+	t.Run("class declared without a transaction", func(t *testing.T) {
+		mockReader.EXPECT().ClassDeclaration(classHash).Return(&blockchain.ClassDeclaration{BlockNumber: 0}, nil)
+		mockReader.EXPECT().BlockHeaderByNumber(uint64(0)).Return(&core.Header{Hash: blockHash}, nil)
+
+		declaration, rpcErr := handler.ClassDeclaration(*classHash)
+		require.Nil(t, rpcErr)
+
+		declarationJSON, err := json.Marshal(declaration)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"block_hash": "`+blockHash.String()+`", "block_number": 0}`, string(declarationJSON))
+	})
+}
+
+func TestClassInstances(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	mockReader := mocks.NewMockReader(mockCtrl)
+	handler := rpc.New(mockReader, utils.MAINNET)
+
+	classHash := utils.HexToFelt(t, "0x10455c752b86932ce552f2b0fe81a880746649b9aee7e0d842bf3f52378f9f8")
+	instances := []*blockchain.ClassInstance{
+		{Address: utils.HexToFelt(t, "0x20cfa74ee3564b4cd5435cdace0f9c4d43b939620e4a0bb5076105df0a626c6"), BlockNumber: 0},
+		{Address: utils.HexToFelt(t, "0x31c887d82502ceb218c06ebb46198da3f7b92864a8223746bc836dda3e34b52"), BlockNumber: 2, Replaced: true},
+	}
+	next := utils.HexToFelt(t, "0x6538fdd3aa353af8a87f5fe77d1f533ea82815076e30a86d65b72d3eb4f0b80")
+
+	t.Run("invalid chunk size", func(t *testing.T) {
+		chunk, rpcErr := handler.ClassInstances(*classHash, 0, "")
+		assert.Nil(t, chunk)
+		assert.Equal(t, rpc.ErrInvalidChunkSize, rpcErr)
+
+		chunk, rpcErr = handler.ClassInstances(*classHash, rpc.MaxChunkSize+1, "")
+		assert.Nil(t, chunk)
+		assert.Equal(t, rpc.ErrPageSizeTooBig, rpcErr)
+	})
+
+	t.Run("invalid continuation token", func(t *testing.T) {
+		chunk, rpcErr := handler.ClassInstances(*classHash, 2, "not a token")
+		assert.Nil(t, chunk)
+		assert.Equal(t, rpc.ErrInvalidContinuationToken, rpcErr)
+	})
+
+	t.Run("chunks", func(t *testing.T) {
+		mockReader.EXPECT().ClassInstances(classHash, new(felt.Felt), uint64(2)).Return(instances, next, nil)
+
+		chunk, rpcErr := handler.ClassInstances(*classHash, 2, "")
+		require.Nil(t, rpcErr)
+
+		chunkJSON, err := json.Marshal(chunk)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"instances": [
+				{
+					"address": "0x20cfa74ee3564b4cd5435cdace0f9c4d43b939620e4a0bb5076105df0a626c6",
+					"block_number": 0,
+					"replaced": false
+				},
+				{
+					"address": "0x31c887d82502ceb218c06ebb46198da3f7b92864a8223746bc836dda3e34b52",
+					"block_number": 2,
+					"replaced": true
+				}
+			],
+			"continuation_token": "0x6538fdd3aa353af8a87f5fe77d1f533ea82815076e30a86d65b72d3eb4f0b80"
+		}`, string(chunkJSON))
+
+		mockReader.EXPECT().ClassInstances(classHash, next, uint64(2)).Return(nil, nil, nil)
+
+		chunk, rpcErr = handler.ClassInstances(*classHash, 2, chunk.ContinuationToken)
+		require.Nil(t, rpcErr)
+		assert.Empty(t, chunk.Instances)
+		assert.Empty(t, chunk.ContinuationToken)
+	})
+}
@@ -57,21 +57,134 @@ type MsgToL1 struct {
 	Payload []*felt.Felt   `json:"payload"`
 }
 
+// https://github.com/starkware-libs/starknet-specs/blob/v0.6.0/api/starknet_api_openrpc.json#L3365
+type MsgFromL1 struct {
+	From     common.Address `json:"from_address"`
+	To       *felt.Felt     `json:"to_address"`
+	Selector *felt.Felt     `json:"entry_point_selector"`
+	Payload  []*felt.Felt   `json:"payload"`
+	Nonce    *felt.Felt     `json:"nonce"`
+}
+
+// L1ToL2MessageStatus links an L1 to L2 message to the L1 handler transaction that consumed it.
+type L1ToL2MessageStatus struct {
+	MessageHash     common.Hash     `json:"message_hash"`
+	Message         *MsgFromL1      `json:"message"`
+	TransactionHash *felt.Felt      `json:"transaction_hash"`
+	ExecutionStatus ExecutionStatus `json:"execution_status"`
+	BlockHash       *felt.Felt      `json:"block_hash"`
+	BlockNumber     uint64          `json:"block_number"`
+}
+
+// EmittedMsgToL1 is an L2 to L1 message together with the transaction that sent it.
+type EmittedMsgToL1 struct {
+	MessageHash     common.Hash    `json:"message_hash"`
+	From            *felt.Felt     `json:"from_address"`
+	To              common.Address `json:"to_address"`
+	Payload         []*felt.Felt   `json:"payload"`
+	TransactionHash *felt.Felt     `json:"transaction_hash"`
+	BlockHash       *felt.Felt     `json:"block_hash"`
+	BlockNumber     uint64         `json:"block_number"`
+}
+
+// MsgToL1Filter selects the L2 to L1 messages sent to an L1 address in a block range, in chunks.
+// A missing from_block is the genesis block and a missing to_block is the latest block.
+type MsgToL1Filter struct {
+	ToAddress         common.Address `json:"to_address"`
+	FromBlock         *BlockID       `json:"from_block"`
+	ToBlock           *BlockID       `json:"to_block"`
+	ChunkSize         uint64         `json:"chunk_size"`
+	ContinuationToken string         `json:"continuation_token"`
+}
+
+// MsgsToL1Chunk is a chunk of the messages selected by a [MsgToL1Filter]. The continuation token
+// is set if there are more messages, pass it in the filter to get the next chunk.
+type MsgsToL1Chunk struct {
+	Messages          []*EmittedMsgToL1 `json:"messages"`
+	ContinuationToken string            `json:"continuation_token,omitempty"`
+}
+
+// TxnHashesChunk is a chunk of the hashes of the transactions of an address. The continuation
+// token is set if there are more transactions, pass it to get the next chunk.
+type TxnHashesChunk struct {
+	TransactionHashes []*felt.Felt `json:"transaction_hashes"`
+	ContinuationToken string       `json:"continuation_token,omitempty"`
+}
+
 type Event struct {
 	From *felt.Felt   `json:"from_address"`
 	Keys []*felt.Felt `json:"keys"`
 	Data []*felt.Felt `json:"data"`
 }
 
-// https://github.com/starkware-libs/starknet-specs/blob/master/api/starknet_api_openrpc.json#L1871
+type ExecutionStatus uint8
+
+const (
+	ExecutionSucceeded ExecutionStatus = iota
+	ExecutionReverted
+)
+
+func (es ExecutionStatus) MarshalJSON() ([]byte, error) {
+	switch es {
+	case ExecutionSucceeded:
+		return []byte("\"SUCCEEDED\""), nil
+	case ExecutionReverted:
+		return []byte("\"REVERTED\""), nil
+	default:
+		return nil, errors.New("unknown ExecutionStatus")
+	}
+}
+
+type FeeUnit uint8
+
+const (
+	WEI FeeUnit = iota
+	FRI
+)
+
+func (u FeeUnit) MarshalJSON() ([]byte, error) {
+	switch u {
+	case WEI:
+		return []byte("\"WEI\""), nil
+	case FRI:
+		return []byte("\"FRI\""), nil
+	default:
+		return nil, errors.New("unknown FeeUnit")
+	}
+}
+
+// https://github.com/starkware-libs/starknet-specs/blob/v0.6.0/api/starknet_api_openrpc.json#L3485
+type FeePayment struct {
+	Amount *felt.Felt `json:"amount"`
+	Unit   FeeUnit    `json:"unit"`
+}
+
+// https://github.com/starkware-libs/starknet-specs/blob/v0.6.0/api/starknet_api_openrpc.json#L3540
+type ExecutionResources struct {
+	Steps        uint64 `json:"steps"`
+	MemoryHoles  uint64 `json:"memory_holes,omitempty"`
+	Pedersen     uint64 `json:"pedersen_builtin_applications,omitempty"`
+	RangeCheck   uint64 `json:"range_check_builtin_applications,omitempty"`
+	Bitwise      uint64 `json:"bitwise_builtin_applications,omitempty"`
+	Ecdsa        uint64 `json:"ecdsa_builtin_applications,omitempty"`
+	EcOp         uint64 `json:"ec_op_builtin_applications,omitempty"`
+	Keccak       uint64 `json:"keccak_builtin_applications,omitempty"`
+	Poseidon     uint64 `json:"poseidon_builtin_applications,omitempty"`
+	SegmentArena uint64 `json:"segment_arena_builtin,omitempty"`
+}
+
+// https://github.com/starkware-libs/starknet-specs/blob/v0.6.0/api/starknet_api_openrpc.json#L2212
 type TransactionReceipt struct {
-	Type            TransactionType `json:"type"`
-	Hash            *felt.Felt      `json:"transaction_hash"`
-	ActualFee       *felt.Felt      `json:"actual_fee"`
-	Status          Status          `json:"status"`
-	BlockHash       *felt.Felt      `json:"block_hash"`
-	BlockNumber     uint64          `json:"block_number"`
-	MessagesSent    []*MsgToL1      `json:"messages_sent"`
-	Events          []*Event        `json:"events"`
-	ContractAddress *felt.Felt      `json:"contract_address,omitempty"`
+	Type               TransactionType     `json:"type"`
+	Hash               *felt.Felt          `json:"transaction_hash"`
+	ActualFee          *FeePayment         `json:"actual_fee"`
+	ExecutionStatus    ExecutionStatus     `json:"execution_status"`
+	Status             Status              `json:"status"`
+	BlockHash          *felt.Felt          `json:"block_hash"`
+	BlockNumber        uint64              `json:"block_number"`
+	MessagesSent       []*MsgToL1          `json:"messages_sent"`
+	Events             []*Event            `json:"events"`
+	ContractAddress    *felt.Felt          `json:"contract_address,omitempty"`
+	RevertReason       string              `json:"revert_reason,omitempty"`
+	ExecutionResources *ExecutionResources `json:"execution_resources,omitempty"`
 }
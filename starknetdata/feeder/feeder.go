@@ -2,7 +2,10 @@ package feeder
 
 import (
 	"context"
+	"encoding/binary"
 	"errors"
+	"fmt"
+	"math/big"
 
 	"github.com/NethermindEth/juno/clients/feeder"
 	"github.com/NethermindEth/juno/core"
@@ -24,15 +27,36 @@ func New(client *feeder.Client) *Feeder {
 	}
 }
 
+// adaptError translates the errors of the feeder client into the errors of [starknetdata].
+// Other errors, such as network failures, are returned as they are.
+func adaptError(err error) error {
+	var feederErr *feeder.Error
+	if errors.As(err, &feederErr) && feederErr.Code == feeder.BlockNotFound {
+		return fmt.Errorf("%w: %v", starknetdata.ErrBlockNotFound, err)
+	}
+	if errors.As(err, new(feeder.DecodeError)) {
+		return invalidData(err)
+	}
+	return err
+}
+
+func invalidData(err error) error {
+	return fmt.Errorf("%w: %v", starknetdata.ErrInvalidData, err)
+}
+
 // BlockByNumber gets the block for a given block number from the feeder,
 // then adapts it to the core.Block type.
 func (f *Feeder) BlockByNumber(ctx context.Context, blockNumber uint64) (*core.Block, error) {
 	response, err := f.client.Block(ctx, blockNumber)
 	if err != nil {
-		return nil, err
+		return nil, adaptError(err)
 	}
 
-	return adaptBlock(response)
+	block, err := adaptBlock(response)
+	if err != nil {
+		return nil, invalidData(err)
+	}
+	return block, nil
 }
 
 func adaptBlock(response *feeder.Block) (*core.Block, error) {
@@ -49,33 +73,92 @@ func adaptBlock(response *feeder.Block) (*core.Block, error) {
 		if err != nil {
 			return nil, err
 		}
-		receipts[i] = adaptTransactionReceipt(response.Receipts[i])
+		receipts[i], err = adaptTransactionReceipt(response.Receipts[i])
+		if err != nil {
+			return nil, err
+		}
+		// the feeder doesn't report the fee unit, it follows from the transaction version
+		receipts[i].FeeUnit = core.FeeUnitOf(txns[i])
 		eventCount += uint64(len(response.Receipts[i].Events))
 	}
 
+	l1DAMode, err := adaptL1DAMode(response.L1DAMode)
+	if err != nil {
+		return nil, err
+	}
+
 	return &core.Block{
 		Header: &core.Header{
-			Hash:             response.Hash,
-			ParentHash:       response.ParentHash,
-			Number:           response.Number,
-			GlobalStateRoot:  response.StateRoot,
-			Timestamp:        response.Timestamp,
-			ProtocolVersion:  response.Version,
-			ExtraData:        nil,
-			SequencerAddress: response.SequencerAddress,
-			TransactionCount: uint64(len(response.Transactions)),
-			EventCount:       eventCount,
+			Hash:                response.Hash,
+			ParentHash:          response.ParentHash,
+			Number:              response.Number,
+			GlobalStateRoot:     response.StateRoot,
+			Timestamp:           response.Timestamp,
+			ProtocolVersion:     response.Version,
+			ExtraData:           nil,
+			SequencerAddress:    response.SequencerAddress,
+			TransactionCount:    uint64(len(response.Transactions)),
+			EventCount:          eventCount,
+			StateDiffCommitment: response.StateDiffCommitment,
+			StateDiffLength:     response.StateDiffLength,
+			GasPrice:            adaptGasPriceWei(response),
+			GasPriceSTRK:        adaptGasPriceFri(response),
+			L1DataGasPrice:      adaptGasPrice(response.L1DataGasPrice),
+			L1DAMode:            l1DAMode,
 		},
 		Transactions: txns,
 		Receipts:     receipts,
 	}, nil
 }
 
-func adaptTransactionReceipt(response *feeder.TransactionReceipt) *core.TransactionReceipt {
+// adaptGasPriceWei returns the price of L1 gas in wei, which is reported in a different field
+// depending on the version of the block.
+func adaptGasPriceWei(response *feeder.Block) *felt.Felt {
+	if response.L1GasPrice != nil && response.L1GasPrice.PriceInWei != nil {
+		return response.L1GasPrice.PriceInWei
+	}
+	if response.GasPriceETH != nil {
+		return response.GasPriceETH
+	}
+	return response.GasPrice
+}
+
+// adaptGasPriceFri returns the price of L1 gas in fri, which is reported in a different field
+// depending on the version of the block.
+func adaptGasPriceFri(response *feeder.Block) *felt.Felt {
+	if response.L1GasPrice != nil && response.L1GasPrice.PriceInFri != nil {
+		return response.L1GasPrice.PriceInFri
+	}
+	return response.GasPriceSTRK
+}
+
+func adaptGasPrice(response *feeder.GasPrice) *core.GasPrice {
 	if response == nil {
 		return nil
 	}
 
+	return &core.GasPrice{
+		PriceInWei: response.PriceInWei,
+		PriceInFri: response.PriceInFri,
+	}
+}
+
+func adaptL1DAMode(mode string) (core.L1DAMode, error) {
+	switch mode {
+	case "", "CALLDATA":
+		return core.Calldata, nil
+	case "BLOB":
+		return core.Blob, nil
+	default:
+		return 0, fmt.Errorf("unknown L1 data availability mode %q", mode)
+	}
+}
+
+func adaptTransactionReceipt(response *feeder.TransactionReceipt) (*core.TransactionReceipt, error) {
+	if response == nil {
+		return nil, nil
+	}
+
 	events := make([]*core.Event, len(response.Events))
 	for i, event := range response.Events {
 		events[i] = adaptEvent(event)
@@ -86,6 +169,11 @@ func adaptTransactionReceipt(response *feeder.TransactionReceipt) *core.Transact
 		l2ToL1Messages[i] = adaptL2ToL1Message(msg)
 	}
 
+	executionStatus, err := adaptExecutionStatus(response.ExecutionStatus)
+	if err != nil {
+		return nil, err
+	}
+
 	return &core.TransactionReceipt{
 		Fee:                response.ActualFee,
 		TransactionHash:    response.TransactionHash,
@@ -93,6 +181,19 @@ func adaptTransactionReceipt(response *feeder.TransactionReceipt) *core.Transact
 		ExecutionResources: adaptExecutionResources(response.ExecutionResources),
 		L1ToL2Message:      adaptL1ToL2Message(response.L1ToL2Message),
 		L2ToL1Message:      l2ToL1Messages,
+		ExecutionStatus:    executionStatus,
+		RevertReason:       response.RevertError,
+	}, nil
+}
+
+func adaptExecutionStatus(status string) (core.ExecutionStatus, error) {
+	switch status {
+	case "", "SUCCEEDED":
+		return core.Succeeded, nil
+	case "REVERTED":
+		return core.Reverted, nil
+	default:
+		return 0, fmt.Errorf("unknown execution status %q", status)
 	}
 }
 
@@ -117,17 +218,32 @@ func adaptExecutionResources(response *feeder.ExecutionResources) *core.Executio
 		BuiltinInstanceCounter: adaptBuiltinInstanceCounter(response.BuiltinInstanceCounter),
 		MemoryHoles:            response.MemoryHoles,
 		Steps:                  response.Steps,
+		TotalGasConsumed:       adaptGasConsumed(response.TotalGasConsumed),
+	}
+}
+
+func adaptGasConsumed(response *feeder.GasConsumed) *core.GasConsumed {
+	if response == nil {
+		return nil
+	}
+
+	return &core.GasConsumed{
+		L1Gas:     response.L1Gas,
+		L1DataGas: response.L1DataGas,
 	}
 }
 
 func adaptBuiltinInstanceCounter(response feeder.BuiltinInstanceCounter) core.BuiltinInstanceCounter {
 	return core.BuiltinInstanceCounter{
-		Bitwise:    response.Bitwise,
-		EcOp:       response.EcOp,
-		Ecsda:      response.Ecsda,
-		Output:     response.Output,
-		Pedersen:   response.Pedersen,
-		RangeCheck: response.RangeCheck,
+		Bitwise:      response.Bitwise,
+		EcOp:         response.EcOp,
+		Ecsda:        response.Ecsda,
+		Keccak:       response.Keccak,
+		Output:       response.Output,
+		Pedersen:     response.Pedersen,
+		Poseidon:     response.Poseidon,
+		RangeCheck:   response.RangeCheck,
+		SegmentArena: response.SegmentArena,
 	}
 }
 
@@ -162,12 +278,12 @@ func adaptL2ToL1Message(response *feeder.L2ToL1Message) *core.L2ToL1Message {
 func (f *Feeder) Transaction(ctx context.Context, transactionHash *felt.Felt) (core.Transaction, error) {
 	response, err := f.client.Transaction(ctx, transactionHash)
 	if err != nil {
-		return nil, err
+		return nil, adaptError(err)
 	}
 
 	tx, err := adaptTransaction(response.Transaction)
 	if err != nil {
-		return nil, err
+		return nil, invalidData(err)
 	}
 
 	return tx, nil
@@ -177,13 +293,13 @@ func adaptTransaction(transaction *feeder.Transaction) (core.Transaction, error)
 	txType := transaction.Type
 	switch txType {
 	case "DECLARE":
-		return adaptDeclareTransaction(transaction), nil
+		return adaptDeclareTransaction(transaction)
 	case "DEPLOY":
 		return adaptDeployTransaction(transaction), nil
 	case "INVOKE_FUNCTION":
-		return adaptInvokeTransaction(transaction), nil
+		return adaptInvokeTransaction(transaction)
 	case "DEPLOY_ACCOUNT":
-		return adaptDeployAccountTransaction(transaction), nil
+		return adaptDeployAccountTransaction(transaction)
 	case "L1_HANDLER":
 		return adaptL1HandlerTransaction(transaction), nil
 	default:
@@ -191,17 +307,28 @@ func adaptTransaction(transaction *feeder.Transaction) (core.Transaction, error)
 	}
 }
 
-func adaptDeclareTransaction(t *feeder.Transaction) *core.DeclareTransaction {
-	return &core.DeclareTransaction{
-		TransactionHash:      t.Hash,
-		SenderAddress:        t.SenderAddress,
-		MaxFee:               t.MaxFee,
-		TransactionSignature: t.Signature,
-		Nonce:                t.Nonce,
-		Version:              t.Version,
-		ClassHash:            t.ClassHash,
-		CompiledClassHash:    t.CompiledClassHash,
+func adaptDeclareTransaction(t *feeder.Transaction) (*core.DeclareTransaction, error) {
+	v3, err := adaptV3Fields(t)
+	if err != nil {
+		return nil, err
 	}
+
+	return &core.DeclareTransaction{
+		TransactionHash:       t.Hash,
+		SenderAddress:         t.SenderAddress,
+		MaxFee:                t.MaxFee,
+		TransactionSignature:  t.Signature,
+		Nonce:                 t.Nonce,
+		Version:               t.Version,
+		ClassHash:             t.ClassHash,
+		CompiledClassHash:     t.CompiledClassHash,
+		ResourceBounds:        v3.resourceBounds,
+		Tip:                   v3.tip,
+		PaymasterData:         t.PaymasterData,
+		NonceDAMode:           v3.nonceDAMode,
+		FeeDAMode:             v3.feeDAMode,
+		AccountDeploymentData: t.AccountDeploymentData,
+	}, nil
 }
 
 func adaptDeployTransaction(t *feeder.Transaction) *core.DeployTransaction {
@@ -215,18 +342,29 @@ func adaptDeployTransaction(t *feeder.Transaction) *core.DeployTransaction {
 	}
 }
 
-func adaptInvokeTransaction(t *feeder.Transaction) *core.InvokeTransaction {
-	return &core.InvokeTransaction{
-		TransactionHash:      t.Hash,
-		ContractAddress:      t.ContractAddress,
-		EntryPointSelector:   t.EntryPointSelector,
-		Nonce:                t.Nonce,
-		CallData:             t.CallData,
-		TransactionSignature: t.Signature,
-		MaxFee:               t.MaxFee,
-		Version:              t.Version,
-		SenderAddress:        t.SenderAddress,
+func adaptInvokeTransaction(t *feeder.Transaction) (*core.InvokeTransaction, error) {
+	v3, err := adaptV3Fields(t)
+	if err != nil {
+		return nil, err
 	}
+
+	return &core.InvokeTransaction{
+		TransactionHash:       t.Hash,
+		ContractAddress:       t.ContractAddress,
+		EntryPointSelector:    t.EntryPointSelector,
+		Nonce:                 t.Nonce,
+		CallData:              t.CallData,
+		TransactionSignature:  t.Signature,
+		MaxFee:                t.MaxFee,
+		Version:               t.Version,
+		SenderAddress:         t.SenderAddress,
+		ResourceBounds:        v3.resourceBounds,
+		Tip:                   v3.tip,
+		PaymasterData:         t.PaymasterData,
+		NonceDAMode:           v3.nonceDAMode,
+		FeeDAMode:             v3.feeDAMode,
+		AccountDeploymentData: t.AccountDeploymentData,
+	}, nil
 }
 
 func adaptL1HandlerTransaction(t *feeder.Transaction) *core.L1HandlerTransaction {
@@ -240,13 +378,97 @@ func adaptL1HandlerTransaction(t *feeder.Transaction) *core.L1HandlerTransaction
 	}
 }
 
-func adaptDeployAccountTransaction(t *feeder.Transaction) *core.DeployAccountTransaction {
+func adaptDeployAccountTransaction(t *feeder.Transaction) (*core.DeployAccountTransaction, error) {
+	v3, err := adaptV3Fields(t)
+	if err != nil {
+		return nil, err
+	}
+
 	return &core.DeployAccountTransaction{
 		DeployTransaction:    *adaptDeployTransaction(t),
 		MaxFee:               t.MaxFee,
 		TransactionSignature: t.Signature,
 		Nonce:                t.Nonce,
+		ResourceBounds:       v3.resourceBounds,
+		Tip:                  v3.tip,
+		PaymasterData:        t.PaymasterData,
+		NonceDAMode:          v3.nonceDAMode,
+		FeeDAMode:            v3.feeDAMode,
+	}, nil
+}
+
+// v3Fields are the fields of version 3 transactions that need conversion from the feeder's format.
+type v3Fields struct {
+	resourceBounds map[core.Resource]core.ResourceBounds
+	tip            uint64
+	nonceDAMode    core.DataAvailabilityMode
+	feeDAMode      core.DataAvailabilityMode
+}
+
+func adaptV3Fields(t *feeder.Transaction) (*v3Fields, error) {
+	fields := new(v3Fields)
+
+	var err error
+	if t.Tip != nil {
+		if fields.tip, err = feltToUint64(t.Tip); err != nil {
+			return nil, fmt.Errorf("tip of transaction %s: %w", t.Hash, err)
+		}
+	}
+
+	if t.ResourceBounds != nil {
+		fields.resourceBounds = make(map[core.Resource]core.ResourceBounds, len(t.ResourceBounds))
+		for name, bounds := range t.ResourceBounds {
+			var resource core.Resource
+			switch name {
+			case "L1_GAS":
+				resource = core.ResourceL1Gas
+			case "L2_GAS":
+				resource = core.ResourceL2Gas
+			default:
+				return nil, fmt.Errorf("unknown resource %q in transaction %s", name, t.Hash)
+			}
+
+			var maxAmount uint64
+			if bounds.MaxAmount != nil {
+				if maxAmount, err = feltToUint64(bounds.MaxAmount); err != nil {
+					return nil, fmt.Errorf("max amount of %s of transaction %s: %w", name, t.Hash, err)
+				}
+			}
+			fields.resourceBounds[resource] = core.ResourceBounds{
+				MaxAmount:       maxAmount,
+				MaxPricePerUnit: bounds.MaxPricePerUnit,
+			}
+		}
+	}
+
+	if fields.nonceDAMode, err = adaptDataAvailabilityMode(t.NonceDAMode); err != nil {
+		return nil, err
+	}
+	if fields.feeDAMode, err = adaptDataAvailabilityMode(t.FeeDAMode); err != nil {
+		return nil, err
 	}
+	return fields, nil
+}
+
+func adaptDataAvailabilityMode(mode string) (core.DataAvailabilityMode, error) {
+	switch mode {
+	case "", "L1":
+		return core.DAModeL1, nil
+	case "L2":
+		return core.DAModeL2, nil
+	default:
+		return 0, fmt.Errorf("unknown data availability mode %q", mode)
+	}
+}
+
+func feltToUint64(f *felt.Felt) (uint64, error) {
+	b := f.Bytes()
+	for _, v := range b[:len(b)-8] {
+		if v != 0 {
+			return 0, fmt.Errorf("%s does not fit in 64 bits", f)
+		}
+	}
+	return binary.BigEndian.Uint64(b[len(b)-8:]), nil
 }
 
 // Class gets the class for a given class hash from the feeder,
@@ -254,17 +476,22 @@ func adaptDeployAccountTransaction(t *feeder.Transaction) *core.DeployAccountTra
 func (f *Feeder) Class(ctx context.Context, classHash *felt.Felt) (core.Class, error) {
 	response, err := f.client.ClassDefinition(ctx, classHash)
 	if err != nil {
-		return nil, err
+		return nil, adaptError(err)
 	}
 
+	var class core.Class
 	switch {
 	case response.V1 != nil:
-		return adaptCairo1Class(response.V1)
+		class, err = adaptCairo1Class(response.V1)
 	case response.V0 != nil:
-		return adaptCairo0Class(response.V0)
+		class, err = adaptCairo0Class(response.V0)
 	default:
-		return nil, errors.New("empty class")
+		err = errors.New("empty class")
 	}
+	if err != nil {
+		return nil, invalidData(err)
+	}
+	return class, nil
 }
 
 func adaptCairo1Class(response *feeder.SierraDefinition) (core.Class, error) {
@@ -341,15 +568,91 @@ func adaptCairo0Class(response *feeder.Cairo0Definition) (core.Class, error) {
 	return class, nil
 }
 
+// CompiledClass gets the compiled class of the Cairo 1 class with the given class hash
+// from the feeder, then adapts it to the core.CompiledClass type.
+func (f *Feeder) CompiledClass(ctx context.Context, classHash *felt.Felt) (*core.CompiledClass, error) {
+	response, err := f.client.CompiledClassDefinition(ctx, classHash)
+	if err != nil {
+		return nil, adaptError(err)
+	}
+
+	compiled, err := adaptCompiledClass(response)
+	if err != nil {
+		return nil, invalidData(err)
+	}
+	return compiled, nil
+}
+
+func adaptCompiledClass(response *feeder.CompiledClass) (*core.CompiledClass, error) {
+	if response == nil {
+		return nil, errors.New("nil compiled class")
+	}
+
+	compiled := new(core.CompiledClass)
+	compiled.Bytecode = response.Bytecode
+	compiled.PythonicHints = response.PythonicHints
+	compiled.CompilerVersion = response.CompilerVersion
+	compiled.Hints = response.Hints
+
+	var ok bool
+	compiled.Prime, ok = new(big.Int).SetString(response.Prime, 0)
+	if !ok {
+		return nil, fmt.Errorf("couldn't convert prime value to big.Int: %s", response.Prime)
+	}
+
+	compiled.External = adaptCompiledEntryPoints(response.EntryPoints.External)
+	compiled.L1Handler = adaptCompiledEntryPoints(response.EntryPoints.L1Handler)
+	compiled.Constructor = adaptCompiledEntryPoints(response.EntryPoints.Constructor)
+
+	if response.BytecodeSegmentLengths != nil {
+		compiled.BytecodeSegmentLengths = adaptSegmentLengths(response.BytecodeSegmentLengths)
+		total, ok := compiled.BytecodeSegmentLengths.Total()
+		if !ok {
+			return nil, errors.New("bytecode segment lengths overflow")
+		} else if total != uint64(len(compiled.Bytecode)) {
+			return nil, fmt.Errorf("bytecode segments cover %d words instead of %d", total, len(compiled.Bytecode))
+		}
+	}
+
+	return compiled, nil
+}
+
+func adaptSegmentLengths(segment *feeder.SegmentLengths) *core.SegmentLengths {
+	adapted := &core.SegmentLengths{Length: segment.Length}
+	if len(segment.Children) > 0 {
+		adapted.Children = make([]core.SegmentLengths, len(segment.Children))
+		for i := range segment.Children {
+			adapted.Children[i] = *adaptSegmentLengths(&segment.Children[i])
+		}
+	}
+	return adapted
+}
+
+func adaptCompiledEntryPoints(entryPoints []feeder.CompiledEntryPoint) []core.CompiledEntryPoint {
+	compiledEntryPoints := make([]core.CompiledEntryPoint, len(entryPoints))
+	for i, entryPoint := range entryPoints {
+		compiledEntryPoints[i] = core.CompiledEntryPoint{
+			Offset:   entryPoint.Offset,
+			Builtins: entryPoint.Builtins,
+			Selector: entryPoint.Selector,
+		}
+	}
+	return compiledEntryPoints
+}
+
 // StateUpdate gets the state update for a given block number from the feeder,
 // then adapts it to the core.StateUpdate type.
 func (f *Feeder) StateUpdate(ctx context.Context, blockNumber uint64) (*core.StateUpdate, error) {
 	response, err := f.client.StateUpdate(ctx, blockNumber)
 	if err != nil {
-		return nil, err
+		return nil, adaptError(err)
 	}
 
-	return adaptStateUpdate(response)
+	update, err := adaptStateUpdate(response)
+	if err != nil {
+		return nil, invalidData(err)
+	}
+	return update, nil
 }
 
 func adaptStateUpdate(response *feeder.StateUpdate) (*core.StateUpdate, error) {
@@ -412,3 +715,26 @@ func adaptStateUpdate(response *feeder.StateUpdate) (*core.StateUpdate, error) {
 		StateDiff: stateDiff,
 	}, nil
 }
+
+// BlockSignature gets the signature of the sequencer on the block with the given number from the feeder.
+func (f *Feeder) BlockSignature(ctx context.Context, blockNumber uint64) (*core.BlockSignature, error) {
+	response, err := f.client.Signature(ctx, feeder.BlockNumber(blockNumber))
+	if err != nil {
+		return nil, adaptError(err)
+	}
+
+	return &core.BlockSignature{
+		BlockHash:           response.SignatureInput.BlockHash,
+		StateDiffCommitment: response.SignatureInput.StateDiffCommitment,
+		Signature:           response.Signature,
+	}, nil
+}
+
+// SequencerPublicKey gets the public key that the sequencer signs blocks with from the feeder.
+func (f *Feeder) SequencerPublicKey(ctx context.Context) (*felt.Felt, error) {
+	publicKey, err := f.client.PublicKey(ctx)
+	if err != nil {
+		return nil, adaptError(err)
+	}
+	return publicKey, nil
+}
@@ -2,12 +2,16 @@ package feeder_test
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"strconv"
 	"testing"
 
 	"github.com/NethermindEth/juno/clients/feeder"
 	"github.com/NethermindEth/juno/core"
 	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/starknetdata"
 	adaptfeeder "github.com/NethermindEth/juno/starknetdata/feeder"
 	"github.com/NethermindEth/juno/utils"
 	"github.com/stretchr/testify/assert"
@@ -60,6 +64,140 @@ func TestGetBlockByNumber(t *testing.T) {
 	}
 }
 
+func TestPost013Block(t *testing.T) {
+	// made-up block of protocol version 0.13.2, whose hash is computed from its contents
+	const block5 = `{
+	  "block_hash": "0x37a6a6dda2e8ed6bd65e316101b6b6458419778a52aadb05a465a16883ce458",
+	  "parent_block_hash": "0x123",
+	  "block_number": 5,
+	  "state_root": "0x456",
+	  "transaction_commitment": "0x0",
+	  "event_commitment": "0x0",
+	  "status": "ACCEPTED_ON_L2",
+	  "l1_da_mode": "BLOB",
+	  "l1_gas_price": {
+	    "price_in_wei": "0x3b9aca00",
+	    "price_in_fri": "0x2540be400"
+	  },
+	  "l1_data_gas_price": {
+	    "price_in_wei": "0x1",
+	    "price_in_fri": "0x2"
+	  },
+	  "transactions": [
+	    {
+	      "transaction_hash": "0x3a94edbc959af535b175019388eac611acb11cac0be843862050c79245c3631",
+	      "version": "0x3",
+	      "signature": ["0x11", "0x22"],
+	      "nonce": "0x8",
+	      "nonce_data_availability_mode": "L1",
+	      "fee_data_availability_mode": "L1",
+	      "resource_bounds": {
+	        "L1_GAS": {
+	          "max_amount": "0x186a0",
+	          "max_price_per_unit": "0x5af3107a4000"
+	        },
+	        "L2_GAS": {
+	          "max_amount": "0x0",
+	          "max_price_per_unit": "0x0"
+	        }
+	      },
+	      "tip": "0x0",
+	      "paymaster_data": [],
+	      "sender_address": "0x3f6f3bc663aedc5285d6013cc3ffcbc4341d86ab488b8b68d297f8258793c41",
+	      "calldata": ["0x1", "0x2"],
+	      "account_deployment_data": [],
+	      "type": "INVOKE_FUNCTION"
+	    }
+	  ],
+	  "timestamp": 1720000000,
+	  "sequencer_address": "0x1176a1bd84444c89232ec27754698e5d2e7e1a7f1539f12027f28b23ec9f3d8",
+	  "transaction_receipts": [
+	    {
+	      "execution_status": "SUCCEEDED",
+	      "transaction_index": 0,
+	      "transaction_hash": "0x3a94edbc959af535b175019388eac611acb11cac0be843862050c79245c3631",
+	      "l2_to_l1_messages": [],
+	      "events": [
+	        {
+	          "from_address": "0x49d36570d4e46f48e99674bd3fcc84644ddd6b96f7c741b1562b82f9e004dc7",
+	          "keys": ["0x99"],
+	          "data": ["0x1", "0x2"]
+	        }
+	      ],
+	      "execution_resources": {
+	        "n_steps": 5123,
+	        "builtin_instance_counter": {
+	          "range_check_builtin": 120,
+	          "pedersen_builtin": 10
+	        },
+	        "n_memory_holes": 0,
+	        "total_gas_consumed": {
+	          "l1_gas": 1000,
+	          "l1_data_gas": 128
+	        }
+	      },
+	      "actual_fee": "0x2a"
+	    }
+	  ],
+	  "starknet_version": "0.13.2",
+	  "state_diff_commitment": "0x57ddb602939ac9e0cb1ec30939680cc392bf75c8bc0f97c140e24557ae8deba",
+	  "state_diff_length": 1
+	}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(block5))
+		require.NoError(t, err)
+	}))
+	t.Cleanup(srv.Close)
+	client := feeder.NewClient(srv.URL).WithBackoff(feeder.NopBackoff).WithMaxRetries(0)
+	adapter := adaptfeeder.New(client)
+
+	block, err := adapter.BlockByNumber(context.Background(), 5)
+	require.NoError(t, err)
+
+	assert.Equal(t, utils.HexToFelt(t, "0x3b9aca00"), block.GasPrice)
+	assert.Equal(t, utils.HexToFelt(t, "0x2540be400"), block.GasPriceSTRK)
+	assert.Equal(t, &core.GasPrice{
+		PriceInWei: utils.HexToFelt(t, "0x1"),
+		PriceInFri: utils.HexToFelt(t, "0x2"),
+	}, block.L1DataGasPrice)
+	assert.Equal(t, core.Blob, block.L1DAMode)
+
+	require.Len(t, block.Transactions, 1)
+	invokeTx, ok := block.Transactions[0].(*core.InvokeTransaction)
+	require.True(t, ok)
+	assert.Equal(t, map[core.Resource]core.ResourceBounds{
+		core.ResourceL1Gas: {MaxAmount: 100000, MaxPricePerUnit: utils.HexToFelt(t, "0x5af3107a4000")},
+		core.ResourceL2Gas: {MaxAmount: 0, MaxPricePerUnit: &felt.Zero},
+	}, invokeTx.ResourceBounds)
+	assert.Equal(t, uint64(0), invokeTx.Tip)
+	assert.Equal(t, core.DAModeL1, invokeTx.NonceDAMode)
+	assert.Equal(t, core.DAModeL1, invokeTx.FeeDAMode)
+	assert.Empty(t, invokeTx.PaymasterData)
+	assert.Empty(t, invokeTx.AccountDeploymentData)
+
+	require.Len(t, block.Receipts, 1)
+	assert.Equal(t, core.Succeeded, block.Receipts[0].ExecutionStatus)
+	assert.Equal(t, core.FRI, block.Receipts[0].FeeUnit)
+	assert.Equal(t, core.BuiltinInstanceCounter{Pedersen: 10, RangeCheck: 120},
+		block.Receipts[0].ExecutionResources.BuiltinInstanceCounter)
+	assert.Equal(t, &core.GasConsumed{L1Gas: 1000, L1DataGas: 128},
+		block.Receipts[0].ExecutionResources.TotalGasConsumed)
+
+	assert.NoError(t, core.VerifyBlockHash(block, utils.INTEGRATION))
+}
+
+func TestBlockNotFound(t *testing.T) {
+	client, serverClose := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(serverClose)
+	adapter := adaptfeeder.New(client)
+
+	_, err := adapter.BlockByNumber(context.Background(), 1000000)
+	assert.ErrorIs(t, err, starknetdata.ErrBlockNotFound)
+
+	_, err = adapter.StateUpdate(context.Background(), 1000000)
+	assert.ErrorIs(t, err, starknetdata.ErrBlockNotFound)
+}
+
 func TestStateUpdate(t *testing.T) {
 	numbers := []uint64{0, 1, 2, 21656}
 
@@ -205,6 +343,67 @@ func TestClassV0(t *testing.T) {
 	}
 }
 
+func TestCompiledClass(t *testing.T) {
+	// a made up CASM with bytecode segments, see the sequencer for real ones
+	compiledClassJSON := `{
+		"prime": "0x800000000000011000000000000000000000000000000000000000000000001",
+		"compiler_version": "2.6.0",
+		"bytecode": ["0xa0680017fff8000", "0x7", "0x482680017ffa8000", "0x400280007ff97fff", "0x1", "0x208b7fff7fff7ffe"],
+		"bytecode_segment_lengths": %s,
+		"hints": [],
+		"pythonic_hints": [],
+		"entry_points_by_type": {
+			"EXTERNAL": [{"selector": "0x22ff5f21f0b81b113e63f7db6da94fedef11b2119b4088b89664fb9a3cb658", "offset": 0,
+				"builtins": ["range_check"]}],
+			"L1_HANDLER": [],
+			"CONSTRUCTOR": []
+		}
+	}`
+	hash := utils.HexToFelt(t, "0x4e70b19333ae94bd958625f7b61ce9eec631653597e68645e13780061b2136c")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		segmentLengths := "[2, [1, 2], 1]"
+		if r.URL.Query().Get("classHash") != hash.String() {
+			segmentLengths = "[2, [1, 2]]"
+		}
+		_, err := fmt.Fprintf(w, compiledClassJSON, segmentLengths)
+		require.NoError(t, err)
+	}))
+	t.Cleanup(srv.Close)
+	client := feeder.NewClient(srv.URL).WithBackoff(feeder.NopBackoff).WithMaxRetries(0)
+	adapter := adaptfeeder.New(client)
+	ctx := context.Background()
+
+	response, err := client.CompiledClassDefinition(ctx, hash)
+	require.NoError(t, err)
+	compiled, err := adapter.CompiledClass(ctx, hash)
+	require.NoError(t, err)
+
+	assert.Equal(t, response.Bytecode, compiled.Bytecode)
+	assert.Equal(t, response.Hints, compiled.Hints)
+	assert.Equal(t, response.PythonicHints, compiled.PythonicHints)
+	assert.Equal(t, response.CompilerVersion, compiled.CompilerVersion)
+	assert.Equal(t, response.Prime, "0x"+compiled.Prime.Text(16))
+	assert.Equal(t, &core.SegmentLengths{Children: []core.SegmentLengths{
+		{Length: 2},
+		{Children: []core.SegmentLengths{{Length: 1}, {Length: 2}}},
+		{Length: 1},
+	}}, compiled.BytecodeSegmentLengths)
+
+	for i, v := range response.EntryPoints.External {
+		assert.Equal(t, v.Selector, compiled.External[i].Selector)
+		assert.Equal(t, v.Offset, compiled.External[i].Offset)
+		assert.Equal(t, v.Builtins, compiled.External[i].Builtins)
+	}
+	assert.Equal(t, len(response.EntryPoints.External), len(compiled.External))
+	assert.Equal(t, len(response.EntryPoints.L1Handler), len(compiled.L1Handler))
+	assert.Equal(t, len(response.EntryPoints.Constructor), len(compiled.Constructor))
+
+	t.Run("segments must cover the bytecode", func(t *testing.T) {
+		_, err := adapter.CompiledClass(ctx, new(felt.Felt))
+		assert.EqualError(t, err, "invalid data: bytecode segments cover 5 words instead of 6")
+	})
+}
+
 func TestTransaction(t *testing.T) {
 	clientGoerli, serverClose := feeder.NewTestClient(utils.GOERLI)
 	t.Cleanup(serverClose)
@@ -322,3 +521,41 @@ func TestTransaction(t *testing.T) {
 		assert.Equal(t, responseTx.Version, l1HandlerTx.Version)
 	})
 }
+
+func TestBlockSignature(t *testing.T) {
+	// the values are made up, only the format of the responses is the gateway's
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body string
+		switch {
+		case r.URL.Path == "/get_signature" && r.URL.Query().Get("blockNumber") == "0":
+			body = `{"block_number": 0, "signature": ["0x1", "0x2"],
+				"signature_input": {"block_hash": "0x3", "state_diff_commitment": "0x4"}}`
+		case r.URL.Path == "/get_public_key":
+			body = `"0x5"`
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			body = `{"code": "StarknetErrorCode.BLOCK_NOT_FOUND", "message": "Block number 1000000 was not found."}`
+		}
+		_, err := w.Write([]byte(body))
+		require.NoError(t, err)
+	}))
+	t.Cleanup(srv.Close)
+	client := feeder.NewClient(srv.URL).WithBackoff(feeder.NopBackoff).WithMaxRetries(0)
+	adapter := adaptfeeder.New(client)
+	ctx := context.Background()
+
+	signature, err := adapter.BlockSignature(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, &core.BlockSignature{
+		BlockHash:           utils.HexToFelt(t, "0x3"),
+		StateDiffCommitment: utils.HexToFelt(t, "0x4"),
+		Signature:           []*felt.Felt{utils.HexToFelt(t, "0x1"), utils.HexToFelt(t, "0x2")},
+	}, signature)
+
+	_, err = adapter.BlockSignature(ctx, 1000000)
+	assert.ErrorIs(t, err, starknetdata.ErrBlockNotFound)
+
+	publicKey, err := adapter.SequencerPublicKey(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, utils.HexToFelt(t, "0x5"), publicKey)
+}
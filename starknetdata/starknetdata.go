@@ -2,11 +2,20 @@ package starknetdata
 
 import (
 	"context"
+	"errors"
 
 	"github.com/NethermindEth/juno/core"
 	"github.com/NethermindEth/juno/core/felt"
 )
 
+var (
+	// ErrBlockNotFound is returned when the requested block has not been produced yet.
+	ErrBlockNotFound = errors.New("block not found")
+	// ErrInvalidData is returned when the data received from the source cannot be decoded or
+	// converted to core types. Retrying the same request is not expected to help.
+	ErrInvalidData = errors.New("invalid data")
+)
+
 // StarknetData defines the function which are required to retrieve Starknet's state
 //
 //go:generate mockgen -destination=../mocks/mock_starknetdata.go -package=mocks github.com/NethermindEth/juno/starknetdata StarknetData
@@ -14,5 +23,8 @@ type StarknetData interface {
 	BlockByNumber(ctx context.Context, blockNumber uint64) (*core.Block, error)
 	Transaction(ctx context.Context, transactionHash *felt.Felt) (core.Transaction, error)
 	Class(ctx context.Context, classHash *felt.Felt) (core.Class, error)
+	CompiledClass(ctx context.Context, classHash *felt.Felt) (*core.CompiledClass, error)
 	StateUpdate(ctx context.Context, blockNumber uint64) (*core.StateUpdate, error)
+	BlockSignature(ctx context.Context, blockNumber uint64) (*core.BlockSignature, error)
+	SequencerPublicKey(ctx context.Context) (*felt.Felt, error)
 }
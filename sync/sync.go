@@ -3,7 +3,10 @@ package sync
 import (
 	"context"
 	"errors"
+	"fmt"
 	"runtime"
+	stdsync "sync"
+	"time"
 
 	"github.com/NethermindEth/juno/blockchain"
 	"github.com/NethermindEth/juno/core"
@@ -14,13 +17,36 @@ import (
 	"github.com/sourcegraph/conc/stream"
 )
 
+const (
+	defaultPollInterval = 5 * time.Second
+	defaultMinRetryWait = time.Second
+	defaultMaxRetryWait = time.Minute
+)
+
 var _ service.Service = (*Synchronizer)(nil)
 
+// errInvalidSignature is returned when the signature of the sequencer on a block does not check out.
+var errInvalidSignature = errors.New("invalid block signature")
+
 // Synchronizer manages a list of StarknetData to fetch the latest blockchain updates
 type Synchronizer struct {
 	Blockchain   *blockchain.Blockchain
 	StarknetData starknetdata.StarknetData
 
+	fetchers    int
+	verifiers   int
+	maxInFlight int
+	stopHeight  *uint64
+
+	pollInterval time.Duration
+	minRetryWait time.Duration
+	maxRetryWait time.Duration
+
+	verifySignatures        bool
+	verifyAccountSignatures bool
+	publicKey               *felt.Felt
+	publicKeyLock           stdsync.Mutex
+
 	log utils.SimpleLogger
 }
 
@@ -28,64 +54,245 @@ func New(bc *blockchain.Blockchain, starkNetData starknetdata.StarknetData, log
 	return &Synchronizer{
 		Blockchain:   bc,
 		StarknetData: starkNetData,
+		fetchers:     runtime.NumCPU(),
+		verifiers:    runtime.NumCPU(),
+		pollInterval: defaultPollInterval,
+		minRetryWait: defaultMinRetryWait,
+		maxRetryWait: defaultMaxRetryWait,
 		log:          log,
 	}
 }
 
+// WithFetchers sets the number of blocks that are downloaded concurrently.
+func (s *Synchronizer) WithFetchers(n int) *Synchronizer {
+	s.fetchers = n
+	return s
+}
+
+// WithVerifiers sets the number of blocks that are sanity checked concurrently.
+func (s *Synchronizer) WithVerifiers(n int) *Synchronizer {
+	s.verifiers = n
+	return s
+}
+
+// WithMaxInFlight bounds the number of blocks that are being fetched or are waiting to be
+// verified and stored, and therefore the memory used by the sync pipeline. Zero means no bound.
+func (s *Synchronizer) WithMaxInFlight(n int) *Synchronizer {
+	s.maxInFlight = n
+	return s
+}
+
+// WithStopHeight makes the Synchronizer return from Run once the block at the given height is stored.
+func (s *Synchronizer) WithStopHeight(height uint64) *Synchronizer {
+	s.stopHeight = &height
+	return s
+}
+
+// WithPollInterval sets how often a block that has not been produced yet is requested.
+func (s *Synchronizer) WithPollInterval(d time.Duration) *Synchronizer {
+	s.pollInterval = d
+	return s
+}
+
+// WithRetryWait sets the bounds of the exponential backoff used when fetching a block fails.
+func (s *Synchronizer) WithRetryWait(minWait, maxWait time.Duration) *Synchronizer {
+	s.minRetryWait = minWait
+	s.maxRetryWait = maxWait
+	return s
+}
+
+// WithSignatureVerification makes the Synchronizer check the signature of the sequencer on every
+// block against the sequencer public key before storing it. The signature is stored in the block header.
+func (s *Synchronizer) WithSignatureVerification() *Synchronizer {
+	s.verifySignatures = true
+	return s
+}
+
+// WithAccountSignatureVerification makes the Synchronizer check the signatures of the transactions
+// sent from standard accounts against the public keys the accounts store, see
+// [blockchain.Blockchain.VerifyAccountSignatures]. Mismatches are logged rather than rejecting the
+// block, since the keys are read from the state before the block and an account may have changed
+// its key earlier in the same block.
+func (s *Synchronizer) WithAccountSignatureVerification() *Synchronizer {
+	s.verifyAccountSignatures = true
+	return s
+}
+
 // Run starts the Synchronizer, returns an error if the loop is already running
 func (s *Synchronizer) Run(ctx context.Context) error {
-	s.syncBlocks(ctx)
+	if s.stopHeight != nil {
+		if height, err := s.Blockchain.Height(); err == nil && height >= *s.stopHeight {
+			s.log.Infow("Stop height already reached", "height", height, "stopHeight", *s.stopHeight)
+			return nil
+		}
+	}
+
+	syncCtx, stopSync := context.WithCancel(ctx)
+	defer stopSync()
+	s.syncBlocks(syncCtx, stopSync)
 	return nil
 }
 
 func (s *Synchronizer) fetcherTask(ctx context.Context, height uint64, verifiers *stream.Stream,
-	resetStreams context.CancelFunc,
+	resetStreams, stopSync context.CancelFunc, release func(),
 ) stream.Callback {
+	wait := time.Duration(0)
 	for {
 		select {
 		case <-ctx.Done():
-			return func() {}
-		default:
-			block, err := s.StarknetData.BlockByNumber(ctx, height)
-			if err != nil {
-				continue
-			}
-			stateUpdate, err := s.StarknetData.StateUpdate(ctx, height)
+			return release
+		case <-time.After(wait):
+			block, stateUpdate, classes, err := s.fetchBlock(ctx, height)
 			if err != nil {
-				continue
-			}
-
-			// There are classes in deployed transactions which refer to class hash that are no present in declared
-			// classes. Thus, we need to fetch all the classes which are referenced in deployed contracts
-			referencedClasses := make(map[felt.Felt]core.Class)
-			for _, deployedContract := range stateUpdate.StateDiff.DeployedContracts {
-				referencedClasses[*deployedContract.ClassHash] = nil
-			}
-			for _, classHash := range stateUpdate.StateDiff.DeclaredV0Classes { // todo: fetch v1 classes as well
-				referencedClasses[*classHash] = nil
-			}
-			for classHash := range referencedClasses {
-				class, err := s.StarknetData.Class(ctx, &classHash)
-				if err != nil {
-					continue
+				if ctx.Err() != nil {
+					return release
 				}
-				referencedClasses[classHash] = class
+				wait = s.retryWait(height, wait, err)
+				continue
 			}
 
 			return func() {
 				verifiers.Go(func() stream.Callback {
-					return s.verifierTask(ctx, block, stateUpdate, referencedClasses, resetStreams)
+					return s.verifierTask(ctx, block, stateUpdate, classes, resetStreams, stopSync, release)
 				})
 			}
 		}
 	}
 }
 
+// fetchBlock fetches the block at the given height together with its state update and all the
+// classes it references. A block is only returned if all of its classes could be fetched.
+func (s *Synchronizer) fetchBlock(ctx context.Context, height uint64) (*core.Block, *core.StateUpdate,
+	map[felt.Felt]core.Class, error,
+) {
+	block, err := s.StarknetData.BlockByNumber(ctx, height)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	stateUpdate, err := s.StarknetData.StateUpdate(ctx, height)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if s.verifySignatures {
+		if err = s.verifySignature(ctx, block, stateUpdate.StateDiff); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	// There are classes in deployed transactions which refer to class hash that are no present in declared
+	// classes. Thus, we need to fetch all the classes which are referenced in deployed contracts
+	referencedClasses := make(map[felt.Felt]core.Class)
+	for _, deployedContract := range stateUpdate.StateDiff.DeployedContracts {
+		referencedClasses[*deployedContract.ClassHash] = nil
+	}
+	for _, classHash := range stateUpdate.StateDiff.DeclaredV0Classes {
+		referencedClasses[*classHash] = nil
+	}
+	for _, declaredClass := range stateUpdate.StateDiff.DeclaredV1Classes {
+		referencedClasses[*declaredClass.ClassHash] = nil
+	}
+	for classHash := range referencedClasses {
+		classHash := classHash
+		class, err := s.StarknetData.Class(ctx, &classHash)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("class %s: %w", classHash.String(), err)
+		}
+		referencedClasses[classHash] = class
+	}
+
+	// Cairo 1 classes are executed in their compiled form, so the CASM of newly declared
+	// classes is fetched as well
+	for _, declaredClass := range stateUpdate.StateDiff.DeclaredV1Classes {
+		class, ok := referencedClasses[*declaredClass.ClassHash].(*core.Cairo1Class)
+		if !ok {
+			continue
+		}
+		compiled, err := s.StarknetData.CompiledClass(ctx, declaredClass.ClassHash)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("compiled class %s: %w", declaredClass.ClassHash.String(), err)
+		}
+		class.Compiled = compiled
+	}
+
+	return block, stateUpdate, referencedClasses, nil
+}
+
+// verifySignature checks the signature of the sequencer on block and adds it to the block header.
+func (s *Synchronizer) verifySignature(ctx context.Context, block *core.Block, stateDiff *core.StateDiff) error {
+	publicKey, err := s.sequencerPublicKey(ctx)
+	if err != nil {
+		return fmt.Errorf("sequencer public key: %w", err)
+	}
+	signature, err := s.StarknetData.BlockSignature(ctx, block.Number)
+	if err != nil {
+		return fmt.Errorf("block signature: %w", err)
+	}
+
+	if signature.BlockHash == nil || !signature.BlockHash.Equal(block.Hash) {
+		return fmt.Errorf("%w: signed block hash %v does not match block hash %s",
+			errInvalidSignature, signature.BlockHash, block.Hash)
+	}
+	commitment := block.StateDiffCommitment
+	if commitment == nil {
+		commitment = stateDiff.Commitment()
+	}
+	if signature.StateDiffCommitment == nil || !signature.StateDiffCommitment.Equal(commitment) {
+		return fmt.Errorf("%w: signed state diff commitment %v does not match state diff commitment %s",
+			errInvalidSignature, signature.StateDiffCommitment, commitment)
+	}
+	if err = signature.Verify(publicKey); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidSignature, err)
+	}
+	block.Signature = signature.Signature
+	return nil
+}
+
+// sequencerPublicKey returns the public key that the sequencer signs blocks with. It is only
+// fetched once.
+func (s *Synchronizer) sequencerPublicKey(ctx context.Context) (*felt.Felt, error) {
+	s.publicKeyLock.Lock()
+	defer s.publicKeyLock.Unlock()
+
+	if s.publicKey == nil {
+		publicKey, err := s.StarknetData.SequencerPublicKey(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.publicKey = publicKey
+	}
+	return s.publicKey, nil
+}
+
+// retryWait returns how long to wait before fetching the block at the given height again after
+// the previous attempt failed with err.
+func (s *Synchronizer) retryWait(height uint64, previous time.Duration, err error) time.Duration {
+	switch {
+	case errors.Is(err, starknetdata.ErrBlockNotFound):
+		// we are at the tip of the chain, poll until the block is produced
+		s.log.Debugw("Waiting for block", "number", height)
+		return s.pollInterval
+	case errors.Is(err, starknetdata.ErrInvalidData), errors.Is(err, errInvalidSignature):
+		s.log.Errorw("Received invalid data", "number", height, "err", err)
+		return s.maxRetryWait
+	default:
+		wait := 2 * previous
+		if wait < s.minRetryWait {
+			wait = s.minRetryWait
+		} else if wait > s.maxRetryWait {
+			wait = s.maxRetryWait
+		}
+		s.log.Warnw("Failed fetching block", "number", height, "err", err, "retryAfter", wait.String())
+		return wait
+	}
+}
+
 func (s *Synchronizer) verifierTask(ctx context.Context, block *core.Block, stateUpdate *core.StateUpdate,
-	declaredClasses map[felt.Felt]core.Class, resetStreams context.CancelFunc,
+	declaredClasses map[felt.Felt]core.Class, resetStreams, stopSync context.CancelFunc, release func(),
 ) stream.Callback {
-	err := s.Blockchain.SanityCheckNewHeight(block, stateUpdate)
+	err := s.Blockchain.SanityCheckNewHeight(block, stateUpdate, declaredClasses)
 	return func() {
+		defer release()
+
 		select {
 		case <-ctx.Done():
 			return
@@ -97,13 +304,25 @@ func (s *Synchronizer) verifierTask(ctx context.Context, block *core.Block, stat
 							block.Hash.ShortString(), "error", err.Error())
 					}
 				} else {
-					s.log.Warnw("Sanity checks failed", "number", block.Number, "hash", block.Hash.ShortString())
+					s.log.Warnw("Sanity checks failed", "number", block.Number, "hash", block.Hash.ShortString(),
+						"err", err.Error())
 					resetStreams()
 					return
 				}
 			}
+			if s.verifyAccountSignatures {
+				if err := s.Blockchain.VerifyAccountSignatures(block); err != nil {
+					s.log.Warnw("Account signature check failed", "number", block.Number,
+						"hash", block.Hash.ShortString(), "err", err.Error())
+				}
+			}
 			err := s.Blockchain.Store(block, stateUpdate, declaredClasses)
-			if err != nil {
+			if errors.Is(err, blockchain.ErrParentDoesNotMatchHead) {
+				// the chain was reorganised, sync the other branch from the parent of the head
+				s.revertHead(block)
+				resetStreams()
+				return
+			} else if err != nil {
 				s.log.Warnw("Failed storing Block", "number", block.Number,
 					"hash", block.Hash.ShortString(), "err", err.Error())
 				resetStreams()
@@ -112,10 +331,30 @@ func (s *Synchronizer) verifierTask(ctx context.Context, block *core.Block, stat
 
 			s.log.Infow("Stored Block", "number", block.Number, "hash",
 				block.Hash.ShortString(), "root", block.GlobalStateRoot.ShortString())
+
+			if s.stopHeight != nil && block.Number == *s.stopHeight {
+				s.log.Infow("Reached stop height, stopping sync", "number", block.Number)
+				stopSync()
+			}
 		}
 	}
 }
 
+// revertHead reverts the head block, which the given block of another branch does not extend.
+func (s *Synchronizer) revertHead(forkBlock *core.Block) {
+	head, err := s.Blockchain.HeadsHeader()
+	if err != nil {
+		s.log.Errorw("Failed reading head to revert", "err", err)
+		return
+	}
+	s.log.Infow("Reorg detected, reverting head", "head", head.Number, "hash", head.Hash.ShortString(),
+		"forkBlockParent", forkBlock.ParentHash.ShortString())
+
+	if err = s.Blockchain.RevertHead(); err != nil {
+		s.log.Errorw("Failed reverting head", "number", head.Number, "hash", head.Hash.ShortString(), "err", err)
+	}
+}
+
 func (s *Synchronizer) nextHeight() uint64 {
 	nextHeight := uint64(0)
 	if h, err := s.Blockchain.Height(); err == nil {
@@ -124,9 +363,25 @@ func (s *Synchronizer) nextHeight() uint64 {
 	return nextHeight
 }
 
-func (s *Synchronizer) syncBlocks(syncCtx context.Context) {
-	fetchers := stream.New().WithMaxGoroutines(runtime.NumCPU())
-	verifiers := stream.New().WithMaxGoroutines(runtime.NumCPU())
+func (s *Synchronizer) syncBlocks(syncCtx context.Context, stopSync context.CancelFunc) {
+	fetchers := stream.New().WithMaxGoroutines(s.fetchers)
+	verifiers := stream.New().WithMaxGoroutines(s.verifiers)
+
+	// inFlight holds a token for every block that was handed to the fetchers and is not yet
+	// stored or dropped.
+	acquire, release := func(context.Context) bool { return true }, func() {}
+	if s.maxInFlight > 0 {
+		inFlight := make(chan struct{}, s.maxInFlight)
+		acquire = func(ctx context.Context) bool {
+			select {
+			case inFlight <- struct{}{}:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		release = func() { <-inFlight }
+	}
 
 	streamCtx, streamCancel := context.WithCancel(syncCtx)
 	nextHeight := s.nextHeight()
@@ -146,9 +401,18 @@ func (s *Synchronizer) syncBlocks(syncCtx context.Context) {
 				s.log.Warnw("Rolling back sync process", "height", nextHeight)
 			}
 		default:
+			if s.stopHeight != nil && nextHeight > *s.stopHeight {
+				// nothing left to fetch, wait for the stop height to be stored or for a rollback
+				<-streamCtx.Done()
+				continue
+			}
+			if !acquire(streamCtx) {
+				continue
+			}
+
 			curHeight, curStreamCtx, curCancel := nextHeight, streamCtx, streamCancel
 			fetchers.Go(func() stream.Callback {
-				return s.fetcherTask(curStreamCtx, curHeight, verifiers, curCancel)
+				return s.fetcherTask(curStreamCtx, curHeight, verifiers, curCancel, stopSync, release)
 			})
 			nextHeight++
 		}
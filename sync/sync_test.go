@@ -3,6 +3,8 @@ package sync
 import (
 	"context"
 	"errors"
+	"fmt"
+	stdsync "sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -10,9 +12,12 @@ import (
 	"github.com/NethermindEth/juno/blockchain"
 	"github.com/NethermindEth/juno/clients/feeder"
 	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/crypto"
 	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db"
 	"github.com/NethermindEth/juno/db/pebble"
 	"github.com/NethermindEth/juno/mocks"
+	"github.com/NethermindEth/juno/starknetdata"
 	adaptfeeder "github.com/NethermindEth/juno/starknetdata/feeder"
 	"github.com/NethermindEth/juno/utils"
 	"github.com/golang/mock/gomock"
@@ -40,6 +45,12 @@ func TestSyncBlocks(t *testing.T) {
 				if err != nil {
 					return err
 				}
+				stateUpdate, err := gw.StateUpdate(context.Background(), uint64(height))
+				if err != nil {
+					return err
+				}
+				b.StateDiffCommitment = stateUpdate.StateDiff.Commitment()
+				b.StateDiffLength = stateUpdate.StateDiff.Length()
 
 				block, err := bc.BlockByNumber(uint64(height))
 				require.NoError(t, err)
@@ -54,11 +65,9 @@ func TestSyncBlocks(t *testing.T) {
 	t.Run("sync multiple blocks in an empty db", func(t *testing.T) {
 		testDB := pebble.NewMemTest()
 		bc := blockchain.New(testDB, utils.MAINNET)
-		synchronizer := New(bc, gw, log)
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		synchronizer := New(bc, gw, log).WithStopHeight(2)
 
-		require.NoError(t, synchronizer.Run(ctx))
-		cancel()
+		require.NoError(t, synchronizer.Run(context.Background()))
 
 		testBlockchain(t, bc)
 	})
@@ -72,11 +81,9 @@ func TestSyncBlocks(t *testing.T) {
 		require.NoError(t, err)
 		require.NoError(t, bc.Store(b0, s0, nil))
 
-		synchronizer := New(bc, gw, log)
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		synchronizer := New(bc, gw, log).WithStopHeight(2)
 
-		require.NoError(t, synchronizer.Run(ctx))
-		cancel()
+		require.NoError(t, synchronizer.Run(context.Background()))
 
 		testBlockchain(t, bc)
 	})
@@ -99,7 +106,12 @@ func TestSyncBlocks(t *testing.T) {
 		}).AnyTimes()
 
 		reqCount := 0
+		var reqCountLock stdsync.Mutex
 		mockSNData.EXPECT().StateUpdate(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, height uint64) (*core.StateUpdate, error) {
+			// a fetcher of a reset stream may still be requesting the same height
+			reqCountLock.Lock()
+			defer reqCountLock.Unlock()
+
 			curHeight := atomic.LoadUint64(&syncingHeight)
 			// reject any other requests
 			if height != curHeight {
@@ -127,12 +139,305 @@ func TestSyncBlocks(t *testing.T) {
 			return gw.Class(ctx, hash)
 		}).AnyTimes()
 
-		synchronizer := New(bc, mockSNData, log)
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		synchronizer := New(bc, mockSNData, log).WithStopHeight(2).
+			WithRetryWait(time.Millisecond, 10*time.Millisecond)
 
-		require.NoError(t, synchronizer.Run(ctx))
-		cancel()
+		require.NoError(t, synchronizer.Run(context.Background()))
 
 		testBlockchain(t, bc)
 	})
+	t.Run("sync with bounded concurrency", func(t *testing.T) {
+		testDB := pebble.NewMemTest()
+		bc := blockchain.New(testDB, utils.MAINNET)
+		synchronizer := New(bc, gw, log).WithFetchers(1).WithVerifiers(1).WithMaxInFlight(1).WithStopHeight(2)
+
+		require.NoError(t, synchronizer.Run(context.Background()))
+
+		testBlockchain(t, bc)
+	})
+}
+
+func TestContractAddressVerification(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+
+	mockSNData := mocks.NewMockStarknetData(mockCtrl)
+	mockSNData.EXPECT().BlockByNumber(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, number uint64) (*core.Block, error) {
+			block, err := gw.BlockByNumber(ctx, number)
+			if err != nil {
+				return nil, err
+			}
+			// neither the transaction hash nor the block hash commit to the salt
+			deployTx, ok := block.Transactions[0].(*core.DeployTransaction)
+			require.True(t, ok)
+			deployTx.ContractAddressSalt = new(felt.Felt).SetUint64(1)
+			return block, nil
+		}).AnyTimes()
+	mockSNData.EXPECT().StateUpdate(gomock.Any(), gomock.Any()).DoAndReturn(gw.StateUpdate).AnyTimes()
+	mockSNData.EXPECT().Class(gomock.Any(), gomock.Any()).DoAndReturn(gw.Class).AnyTimes()
+
+	bc := blockchain.New(pebble.NewMemTest(), utils.MAINNET)
+	synchronizer := New(bc, mockSNData, utils.NewNopZapLogger()).WithStopHeight(0).
+		WithRetryWait(time.Millisecond, 10*time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	t.Cleanup(cancel)
+
+	require.NoError(t, synchronizer.Run(ctx))
+	_, err := bc.Height()
+	assert.ErrorIs(t, err, db.ErrKeyNotFound)
+}
+
+func TestFetchRetries(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+	log := utils.NewNopZapLogger()
+
+	t.Run("block not produced yet is polled", func(t *testing.T) {
+		mockSNData := mocks.NewMockStarknetData(mockCtrl)
+
+		var tipRequests uint64
+		mockSNData.EXPECT().BlockByNumber(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, height uint64) (*core.Block, error) {
+			if height > 0 {
+				atomic.AddUint64(&tipRequests, 1)
+				return nil, fmt.Errorf("%w: block %d", starknetdata.ErrBlockNotFound, height)
+			}
+			return gw.BlockByNumber(ctx, height)
+		}).AnyTimes()
+		mockSNData.EXPECT().StateUpdate(gomock.Any(), gomock.Any()).DoAndReturn(gw.StateUpdate).AnyTimes()
+		mockSNData.EXPECT().Class(gomock.Any(), gomock.Any()).DoAndReturn(gw.Class).AnyTimes()
+
+		bc := blockchain.New(pebble.NewMemTest(), utils.MAINNET)
+		// failed fetches are not retried within the test, so the tip can only be requested again
+		// after the poll interval
+		synchronizer := New(bc, mockSNData, log).WithFetchers(1).WithMaxInFlight(2).
+			WithPollInterval(time.Millisecond).WithRetryWait(time.Hour, time.Hour)
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
+		done := make(chan error, 1)
+		go func() {
+			done <- synchronizer.Run(ctx)
+		}()
+
+		require.Eventually(t, func() bool {
+			height, err := bc.Height()
+			return err == nil && height == 0 && atomic.LoadUint64(&tipRequests) >= 3
+		}, 10*time.Second, time.Millisecond)
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	t.Run("block is not handed over without its classes", func(t *testing.T) {
+		mockSNData := mocks.NewMockStarknetData(mockCtrl)
+
+		var classFailures uint64
+		mockSNData.EXPECT().BlockByNumber(gomock.Any(), gomock.Any()).DoAndReturn(gw.BlockByNumber).AnyTimes()
+		mockSNData.EXPECT().StateUpdate(gomock.Any(), gomock.Any()).DoAndReturn(gw.StateUpdate).AnyTimes()
+		mockSNData.EXPECT().Class(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, hash *felt.Felt) (core.Class, error) {
+			if atomic.AddUint64(&classFailures, 1) <= 3 {
+				return nil, errors.New("connection reset")
+			}
+			return gw.Class(ctx, hash)
+		}).AnyTimes()
+
+		testDB := pebble.NewMemTest()
+		bc := blockchain.New(testDB, utils.MAINNET)
+		synchronizer := New(bc, mockSNData, log).WithStopHeight(0).
+			WithRetryWait(time.Millisecond, 10*time.Millisecond)
+
+		require.NoError(t, synchronizer.Run(context.Background()))
+		assert.Greater(t, atomic.LoadUint64(&classFailures), uint64(3))
+
+		stateUpdate, err := gw.StateUpdate(context.Background(), 0)
+		require.NoError(t, err)
+		require.NoError(t, testDB.View(func(txn db.Transaction) error {
+			state := core.NewState(txn)
+			for _, deployed := range stateUpdate.StateDiff.DeployedContracts {
+				class, err := state.Class(deployed.ClassHash)
+				require.NoError(t, err)
+				assert.NotNil(t, class)
+			}
+			return nil
+		}))
+	})
+}
+
+func TestSyncStopHeight(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+	log := utils.NewNopZapLogger()
+
+	testDB := pebble.NewMemTest()
+	bc := blockchain.New(testDB, utils.MAINNET)
+	ctx := context.Background()
+
+	// Run only returns without a cancelled context once the stop height is stored
+	require.NoError(t, New(bc, gw, log).WithStopHeight(1).Run(ctx))
+
+	height, err := bc.Height()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), height)
+
+	t.Run("stop height already reached", func(t *testing.T) {
+		require.NoError(t, New(bc, gw, log).WithStopHeight(0).Run(ctx))
+
+		height, err := bc.Height()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), height)
+	})
+	t.Run("sync from archive", func(t *testing.T) {
+		archive, err := feeder.NewArchiveClient("../clients/feeder/testdata/mainnet")
+		require.NoError(t, err)
+
+		bc := blockchain.New(pebble.NewMemTest(), utils.MAINNET)
+		require.NoError(t, New(bc, adaptfeeder.New(archive), log).WithStopHeight(2).Run(ctx))
+
+		height, err := bc.Height()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2), height)
+	})
+}
+
+func TestSyncReorg(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+
+	bc := blockchain.New(pebble.NewMemTest(), utils.MAINNET)
+	ctx := context.Background()
+
+	// store a block 1 of another branch, which block 2 does not extend
+	for i := uint64(0); i < 2; i++ {
+		b, err := gw.BlockByNumber(ctx, i)
+		require.NoError(t, err)
+		su, err := gw.StateUpdate(ctx, i)
+		require.NoError(t, err)
+		if i == 1 {
+			b.Hash = utils.HexToFelt(t, "0xdead")
+		}
+		require.NoError(t, bc.Store(b, su, nil))
+	}
+
+	require.NoError(t, New(bc, gw, utils.NewNopZapLogger()).WithStopHeight(2).Run(ctx))
+
+	height, err := bc.Height()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), height)
+	for i := uint64(1); i <= 2; i++ {
+		want, err := gw.BlockByNumber(ctx, i)
+		require.NoError(t, err)
+		got, err := bc.BlockByNumber(i)
+		require.NoError(t, err)
+		assert.Equal(t, want.Hash, got.Hash)
+	}
+	_, err = bc.BlockByHash(utils.HexToFelt(t, "0xdead"))
+	assert.ErrorIs(t, err, db.ErrKeyNotFound)
+	require.NoError(t, bc.VerifyState(ctx))
+}
+
+func TestSignatureVerification(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+	log := utils.NewNopZapLogger()
+
+	// the blocks are signed with a test key rather than by the sequencer
+	privateKey := utils.HexToFelt(t, "0x1234567890abcdef")
+	publicKey, err := crypto.PublicKey(privateKey)
+	require.NoError(t, err)
+
+	// signedData serves the mainnet blocks of gw with their signatures made with the test key
+	// and counts the signature requests
+	signedData := func(sequencerPublicKey *felt.Felt, signatureRequests *uint64) starknetdata.StarknetData {
+		mockSNData := mocks.NewMockStarknetData(mockCtrl)
+		mockSNData.EXPECT().BlockByNumber(gomock.Any(), gomock.Any()).DoAndReturn(gw.BlockByNumber).AnyTimes()
+		mockSNData.EXPECT().StateUpdate(gomock.Any(), gomock.Any()).DoAndReturn(gw.StateUpdate).AnyTimes()
+		mockSNData.EXPECT().Class(gomock.Any(), gomock.Any()).DoAndReturn(gw.Class).AnyTimes()
+		mockSNData.EXPECT().BlockSignature(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, height uint64) (*core.BlockSignature, error) {
+				atomic.AddUint64(signatureRequests, 1)
+				return signBlock(ctx, gw, height, privateKey)
+			}).AnyTimes()
+		mockSNData.EXPECT().SequencerPublicKey(gomock.Any()).Return(sequencerPublicKey, nil).Times(1)
+		return mockSNData
+	}
+
+	t.Run("signed blocks are stored with their signature", func(t *testing.T) {
+		bc := blockchain.New(pebble.NewMemTest(), utils.MAINNET)
+		ctx := context.Background()
+
+		var signatureRequests uint64
+		require.NoError(t, New(bc, signedData(publicKey, &signatureRequests), log).
+			WithSignatureVerification().WithStopHeight(2).Run(ctx))
+
+		for height := uint64(0); height <= 2; height++ {
+			header, err := bc.BlockHeaderByNumber(height)
+			require.NoError(t, err)
+			signature, err := signBlock(ctx, gw, height, privateKey)
+			require.NoError(t, err)
+			assert.Equal(t, signature.Signature, header.Signature)
+		}
+	})
+
+	t.Run("blocks with a bad signature are not stored", func(t *testing.T) {
+		// the public key of another sequencer
+		otherPublicKey, err := crypto.PublicKey(utils.HexToFelt(t, "0xfedcba0987654321"))
+		require.NoError(t, err)
+		var signatureRequests uint64
+
+		bc := blockchain.New(pebble.NewMemTest(), utils.MAINNET)
+		synchronizer := New(bc, signedData(otherPublicKey, &signatureRequests), log).
+			WithSignatureVerification().WithStopHeight(0).WithRetryWait(time.Millisecond, 10*time.Millisecond)
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
+		done := make(chan error, 1)
+		go func() {
+			done <- synchronizer.Run(ctx)
+		}()
+
+		// the block is only refetched after its signature was rejected
+		require.Eventually(t, func() bool {
+			return atomic.LoadUint64(&signatureRequests) >= 2
+		}, 10*time.Second, time.Millisecond)
+		cancel()
+		require.NoError(t, <-done)
+
+		_, err = bc.Height()
+		assert.ErrorIs(t, err, db.ErrKeyNotFound)
+	})
+}
+
+// signBlock signs the block at the given height the way the sequencer does, with privateKey.
+func signBlock(ctx context.Context, data starknetdata.StarknetData, height uint64, privateKey *felt.Felt) (
+	*core.BlockSignature, error,
+) {
+	block, err := data.BlockByNumber(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+	stateUpdate, err := data.StateUpdate(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+
+	commitment := stateUpdate.StateDiff.Commitment()
+	r, s, err := crypto.Sign(crypto.Pedersen(block.Hash, commitment), privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &core.BlockSignature{BlockHash: block.Hash, StateDiffCommitment: commitment, Signature: []*felt.Felt{r, s}}, nil
 }